@@ -0,0 +1,145 @@
+package mcpengine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== API Key Authenticator Tests =====
+
+func TestNewAPIKeyAuthenticator_Validation(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	testCases := []struct {
+		name    string
+		cfg     APIKeyConfig
+		wantErr bool
+	}{
+		{
+			name: "header carrier",
+			cfg:  APIKeyConfig{Header: "X-Api-Key", Key: "k"},
+		},
+		{
+			name: "query carrier",
+			cfg:  APIKeyConfig{QueryParam: "api_key", Key: "k"},
+		},
+		{
+			name:    "missing key",
+			cfg:     APIKeyConfig{Header: "X-Api-Key"},
+			wantErr: true,
+		},
+		{
+			name:    "no carrier",
+			cfg:     APIKeyConfig{Key: "k"},
+			wantErr: true,
+		},
+		{
+			name:    "both carriers",
+			cfg:     APIKeyConfig{Header: "X-Api-Key", QueryParam: "api_key", Key: "k"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewAPIKeyAuthenticator(tc.cfg, logger)
+			if tc.wantErr && err == nil {
+				t.Error("Expected an error, got none")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticator_AuthorizeHeader(t *testing.T) {
+	auth, err := NewAPIKeyAuthenticator(APIKeyConfig{Header: "X-Api-Key", Key: "secret"}, NewZapLogger(zap.NewNop().Sugar()))
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/mcp", nil)
+	auth.Authorize(req)
+	if got := req.Header.Get("X-Api-Key"); got != "secret" {
+		t.Errorf("Expected X-Api-Key %q, got %q", "secret", got)
+	}
+	if auth.Token() != "" {
+		t.Error("Token must be empty; the key is attached via Authorize")
+	}
+}
+
+func TestAPIKeyAuthenticator_AuthorizeQueryParam(t *testing.T) {
+	auth, err := NewAPIKeyAuthenticator(APIKeyConfig{QueryParam: "api_key", Key: "secret"}, NewZapLogger(zap.NewNop().Sugar()))
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/mcp?session=1", nil)
+	auth.Authorize(req)
+	query := req.URL.Query()
+	if got := query.Get("api_key"); got != "secret" {
+		t.Errorf("Expected api_key %q, got %q", "secret", got)
+	}
+	if got := query.Get("session"); got != "1" {
+		t.Errorf("Existing query parameters must be preserved, got session=%q", got)
+	}
+}
+
+func TestAPIKeyAuthenticator_ChallengeFails(t *testing.T) {
+	auth, err := NewAPIKeyAuthenticator(APIKeyConfig{Header: "X-Api-Key", Key: "secret"}, NewZapLogger(zap.NewNop().Sugar()))
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	resp := &http.Response{StatusCode: http.StatusUnauthorized}
+	_, _, challengeErr := auth.HandleChallenge(context.Background(), resp)
+	if challengeErr == nil {
+		t.Fatal("Expected HandleChallenge to fail for an API key")
+	}
+	if !strings.Contains(challengeErr.Error(), "API key") {
+		t.Errorf("Error should explain the API key was rejected, got %q", challengeErr)
+	}
+}
+
+func TestAPIKeyAuthenticator_AttachedByPostSender(t *testing.T) {
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+
+	keyChan := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyChan <- r.Header.Get("X-Api-Key")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	endpointChan <- "/api"
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth, err := NewAPIKeyAuthenticator(APIKeyConfig{Header: "X-Api-Key", Key: "secret"}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	sender := NewHTTPPostSender(&http.Client{}, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	select {
+	case got := <-keyChan:
+		if got != "secret" {
+			t.Errorf("Expected X-Api-Key %q, got %q", "secret", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No request was received")
+	}
+}
@@ -6,37 +6,74 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"html/template"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/coreos/go-oidc"
-	"go.uber.org/zap"
+
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
 // AuthConfig holds configuration options for AuthManager.
 // Any field that is set to its zero value will be replaced with a default:
 //   - ClientID:           ClientID to use for OAuth.
 //   - ClientSecret:       ClientSecret to use for OAuth (can be empty).
-//   - ListenPort:         Port on which the auth server listens (default 8181)
+//   - ListenPort:         Port on which the auth server listens (default 8181;
+//     EphemeralPort binds a random free port)
 //   - CallbackPath:       HTTP path for auth callbacks (default "/callback")
 //   - OIDCConfigPath:     Path to fetch OIDC configuration (default "/.well-known/openid-configuration")
 //   - MaxAuthAttempts:    Maximum allowed authentication attempts (default 3)
 //   - AuthCooldownPeriod: Cooldown period between auth attempts (default 15 seconds)
+//   - TokenStore:         Where to persist tokens across restarts (default none)
+//   - TokenCache:         Issuer+audience-keyed token cache, shareable between managers (default a private cache)
+//   - Audience:           OAuth audience parameter for IdPs that require one to mint API tokens (default none)
+//   - Resource:           RFC 8707 resource indicator binding tokens to the MCP server (default the challenged server's URL)
+//   - ClientCredentials:  Obtain tokens via the client_credentials grant, with no browser or callback server (for automation)
+//   - Scopes:             OAuth scopes to request (default those advertised in the auth challenge)
+//   - ClientStore:        Where to persist dynamically registered client credentials (default none)
+//   - HTTPClient:         Client for OIDC discovery and token exchange (default a fresh client)
+//   - DisableBrowser:     Don't open the system browser on auth challenges (for headless hosts)
+//   - CallbackTemplate:   html/template text for the callback success page (default built-in page)
+//   - CallbackTemplateFile: File to load the callback success template from (overrides CallbackTemplate)
+//   - CallbackAutoClose:  Make the default success page close its window automatically
+//   - RevokeOnShutdown:   Revoke tokens at the authorization server when the engine stops
 type AuthConfig struct {
-	ClientID           string
-	ClientSecret       string
-	ListenPort         int
-	CallbackPath       string
-	OIDCConfigPath     string
-	MaxAuthAttempts    int
-	AuthCooldownPeriod time.Duration
+	ClientID             string
+	ClientSecret         string
+	ListenPort           int
+	CallbackPath         string
+	OIDCConfigPath       string
+	MaxAuthAttempts      int
+	AuthCooldownPeriod   time.Duration
+	TokenStore           TokenStore
+	ClientStore          ClientStore
+	TokenCache           *TokenCache
+	Audience             string
+	Resource             string
+	ClientCredentials    bool
+	Scopes               []string
+	HTTPClient           *http.Client
+	DisableBrowser       bool
+	CallbackTemplate     string
+	CallbackTemplateFile string
+	CallbackAutoClose    bool
+	RevokeOnShutdown     bool
 }
 
+// EphemeralPort selects a random free port for the callback listener, so
+// several proxies on one machine don't collide on a fixed port. The
+// redirect URI is rebuilt from the actual listener address once the flow
+// starts.
+const EphemeralPort = -1
+
 // resolveConfig fills in any missing configuration fields with defaults.
 func resolveConfig(cfg *AuthConfig) *AuthConfig {
 	if cfg == nil {
@@ -46,6 +83,8 @@ func resolveConfig(cfg *AuthConfig) *AuthConfig {
 			OIDCConfigPath:     "/.well-known/openid-configuration",
 			MaxAuthAttempts:    3,
 			AuthCooldownPeriod: 15 * time.Second,
+			TokenCache:         NewTokenCache(),
+			HTTPClient:         &http.Client{},
 		}
 	}
 
@@ -65,13 +104,67 @@ func resolveConfig(cfg *AuthConfig) *AuthConfig {
 	if resolved.AuthCooldownPeriod == 0 {
 		resolved.AuthCooldownPeriod = 15 * time.Second
 	}
+	if resolved.TokenCache == nil {
+		resolved.TokenCache = NewTokenCache()
+	}
+	if resolved.HTTPClient == nil {
+		resolved.HTTPClient = &http.Client{}
+	}
 	return &resolved
 }
 
+// Authenticator is the interface the engine uses to acquire and attach
+// credentials to upstream requests. AuthManager provides the default OIDC
+// implementation; embedders can supply their own (SSO brokers, hardware
+// tokens, static credentials) via Config.Authenticator while reusing the
+// engine's 401 handling and header injection.
+type Authenticator interface {
+	// HandleChallenge reacts to a 401/403 response and begins whatever flow
+	// is needed to obtain credentials. It returns a URL the user must visit
+	// (empty if no user interaction is required), a waiter that blocks until
+	// the flow completes, and an error.
+	HandleChallenge(ctx context.Context, resp *http.Response) (authURL string, wait func(), err error)
+	// Token returns the current credential, or an empty string if none is
+	// available. The result is attached as a bearer token to requests.
+	Token() string
+	// Refresh attempts to renew the current credential without user
+	// interaction, returning an error if that is not possible.
+	Refresh(ctx context.Context) error
+}
+
+// RequestAuthenticator is an optional interface an Authenticator can
+// implement to attach credentials that don't fit the Authorization: Bearer
+// scheme (API-key headers, query parameters, request signing). When the
+// Authenticator implements it, the senders call Authorize on each outgoing
+// request instead of attaching Token() as a bearer token.
+type RequestAuthenticator interface {
+	Authorize(req *http.Request)
+}
+
+// ExpiryAwareAuthenticator is an optional interface an Authenticator can
+// implement to expose credential expiry. Senders use it to renew an expired
+// credential before attaching it, saving the doomed request and 401 round
+// trip per message.
+type ExpiryAwareAuthenticator interface {
+	// TokenExpired reports whether the current credential is past its
+	// expiry and should be renewed before use.
+	TokenExpired() bool
+}
+
+// TokenRevoker is an optional interface an Authenticator can implement to
+// invalidate its credentials at the authorization server (RFC 7009) when
+// the engine shuts down, so short-lived desktop sessions don't leave live
+// tokens behind.
+type TokenRevoker interface {
+	RevokeTokens(ctx context.Context) error
+}
+
 // OpenIDConfiguration represents the OpenID Connect configuration.
 type OpenIDConfiguration struct {
 	AuthorizationEndpoint string `json:"authorization_endpoint"`
 	TokenEndpoint         string `json:"token_endpoint"`
+	RegistrationEndpoint  string `json:"registration_endpoint"`
+	RevocationEndpoint    string `json:"revocation_endpoint"`
 	Issuer                string `json:"issuer"`
 }
 
@@ -86,15 +179,21 @@ type AuthManager struct {
 	oauth2Config oauth2.Config
 
 	verifier         string
-	accessToken      string
-	tokenMutex       sync.RWMutex
+	pendingStates    map[string]time.Time
+	stateMutex       sync.Mutex
+	tokens           *TokenCache
+	store            TokenStore
+	clientStore      ClientStore
 	authCompleteChan chan struct{}
 
-	serverURL  string
-	oidcConfig OpenIDConfiguration
+	serverURL   string
+	resourceURL string
+	grantScopes []string
+	oidcConfig  OpenIDConfiguration
 
-	httpClient *http.Client
-	logger     *zap.SugaredLogger
+	httpClient       *http.Client
+	callbackTemplate *template.Template
+	logger           Logger
 
 	// Auth retry tracking.
 	authAttempts     int
@@ -104,18 +203,124 @@ type AuthManager struct {
 
 // NewAuthManager creates a new AuthManager instance.
 // If a nil or partially populated config is provided, missing fields are replaced with defaults.
-func NewAuthManager(cfg *AuthConfig, logger *zap.SugaredLogger) *AuthManager {
+func NewAuthManager(cfg *AuthConfig, logger Logger) *AuthManager {
 	cfg = resolveConfig(cfg)
 	redirectURL := fmt.Sprintf("http://localhost:%d%s", cfg.ListenPort, cfg.CallbackPath)
-	return &AuthManager{
+	a := &AuthManager{
 		clientID:         cfg.ClientID,
 		clientSecret:     cfg.ClientSecret,
 		redirectURL:      redirectURL,
 		opts:             cfg,
+		tokens:           cfg.TokenCache,
+		store:            cfg.TokenStore,
+		clientStore:      cfg.ClientStore,
+		pendingStates:    make(map[string]time.Time),
 		authCompleteChan: make(chan struct{}),
-		httpClient:       &http.Client{},
+		httpClient:       cfg.HTTPClient,
 		logger:           logger,
 	}
+	if a.clientID == "" && a.clientStore != nil {
+		creds, err := a.clientStore.LoadClient()
+		if err != nil {
+			logger.Warnf("Failed to load stored client credentials: %v", err)
+		} else if creds != nil {
+			logger.Debugw("Loaded registered client from store", "client-id", creds.ClientID)
+			a.clientID = creds.ClientID
+			a.clientSecret = creds.ClientSecret
+		}
+	}
+	if a.store != nil {
+		token, err := a.store.Load()
+		if err != nil {
+			logger.Warnf("Failed to load stored token: %v", err)
+		} else if token != nil {
+			logger.Debug("Loaded token from store")
+			// The issuer isn't known yet; the token is re-keyed once
+			// discovery names it.
+			a.tokens.Put(a.tokenKey(), token)
+		}
+	}
+	a.callbackTemplate = a.loadCallbackTemplate()
+	return a
+}
+
+// defaultCallbackTemplate is the success page shown after authentication
+// when no custom template is configured.
+const defaultCallbackTemplate = `
+	<html>
+	  <head><title>Authentication Successful</title></head>
+	  <body>
+		<h1>Authentication Successful</h1>
+		<p>You can now close this window and return to the application.</p>
+		{{if .AutoClose}}<script>window.close();</script>{{end}}
+	  </body>
+	</html>
+`
+
+// callbackPageData is the data available to callback success templates.
+type callbackPageData struct {
+	// Server is the upstream MCP server's base URL.
+	Server string
+	// User is a display name taken from the ID token, when one was issued.
+	User string
+	// Scopes are the OAuth scopes that were requested.
+	Scopes []string
+	// AutoClose reflects AuthConfig.CallbackAutoClose for use by the
+	// default template.
+	AutoClose bool
+}
+
+// loadCallbackTemplate resolves the configured success page template,
+// falling back to the built-in page if a custom one can't be loaded or
+// parsed.
+func (a *AuthManager) loadCallbackTemplate() *template.Template {
+	text := defaultCallbackTemplate
+	if a.opts.CallbackTemplate != "" {
+		text = a.opts.CallbackTemplate
+	}
+	if a.opts.CallbackTemplateFile != "" {
+		data, err := os.ReadFile(a.opts.CallbackTemplateFile)
+		if err != nil {
+			a.logger.Warnf("Failed to read callback template file: %v; using the default page", err)
+		} else {
+			text = string(data)
+		}
+	}
+	tmpl, err := template.New("callback").Parse(text)
+	if err != nil {
+		a.logger.Warnf("Failed to parse callback template: %v; using the default page", err)
+		tmpl = template.Must(template.New("callback").Parse(defaultCallbackTemplate))
+	}
+	return tmpl
+}
+
+// idTokenDisplayName extracts a display name from an unverified ID token
+// for the success page. The claims are presentation-only and never used for
+// authorization decisions.
+func idTokenDisplayName(token *oauth2.Token) string {
+	idToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ""
+	}
+	var claims struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return ""
+	}
+	if claims.Name != "" {
+		return claims.Name
+	}
+	return claims.Email
 }
 
 // CanAttemptAuth checks whether an authentication attempt is allowed based on the maximum attempts
@@ -157,6 +362,10 @@ func (a *AuthManager) ResetAuthAttempts() {
 // HandleAuthChallenge handles a 401 response and starts the authentication flow.
 // It returns the authorization URL, a waiter function that blocks until authentication completes,
 // and an error.
+//
+// The flow always uses PKCE (RFC 7636): a fresh S256 code challenge is attached
+// to the authorization URL and the matching verifier is sent on the token
+// exchange, so public clients can authenticate without a client secret.
 func (a *AuthManager) HandleAuthChallenge(ctx context.Context, resp *http.Response) (string, func(), error) {
 	// Reset the auth channel, in case this isn't the first call.
 	a.authCompleteChan = make(chan struct{})
@@ -188,10 +397,57 @@ func (a *AuthManager) HandleAuthChallenge(ctx context.Context, resp *http.Respon
 		return "", nil, fmt.Errorf("failed to extract server URL: %w", err)
 	}
 	a.serverURL = serverURL
+	// Remember the MCP server separately: serverURL is replaced by the
+	// authorization server's issuer below, but the resource indicator must
+	// keep naming the server the token is for.
+	a.resourceURL = serverURL
+
+	// The MCP authorization spec separates the resource server from the
+	// authorization server: the challenge points at Protected Resource
+	// Metadata (RFC 9728) naming the AS. Fall back to the MCP server's own
+	// OIDC configuration when the hint is absent or unusable.
+	if metadataURL := parseResourceMetadataURL(wwwAuth); metadataURL != "" {
+		issuer, err := a.discoverAuthorizationServer(ctx, metadataURL)
+		if err != nil {
+			a.logger.Warnf("Failed to discover authorization server from resource metadata: %v; using the MCP server's OIDC configuration", err)
+		} else {
+			a.logger.Debugw("Discovered authorization server", "issuer", issuer)
+			a.serverURL = issuer
+		}
+	}
+
+	if len(a.opts.Scopes) > 0 {
+		scopes = a.opts.Scopes
+	}
 
 	if err := a.fetchOIDCConfiguration(ctx); err != nil {
 		return "", nil, fmt.Errorf("failed to fetch OIDC configuration: %w", err)
 	}
+
+	// Service-to-service deployments have no human to click a link: the
+	// client_credentials grant goes straight to the token endpoint, so no
+	// callback server, registration, or browser is involved.
+	if a.opts.ClientCredentials {
+		a.grantScopes = scopes
+		if err := a.clientCredentialsToken(ctx); err != nil {
+			return "", nil, err
+		}
+		return "", func() {}, nil
+	}
+
+	// Bind the callback listener before anything sees the redirect URI:
+	// with an ephemeral port, the URI is only known once the listener is
+	// up, and registration and the auth URL must both carry the real port.
+	if err := a.startAuthServer(ctx); err != nil {
+		return "", nil, fmt.Errorf("failed to start auth server: %w", err)
+	}
+	// Without a pre-configured client, register one dynamically (RFC 7591)
+	// as the MCP authorization spec expects.
+	if a.clientID == "" {
+		if err := a.registerClient(ctx); err != nil {
+			return "", nil, fmt.Errorf("failed to register OAuth client: %w", err)
+		}
+	}
 	if err := a.initOAuth2Config(ctx, scopes); err != nil {
 		return "", nil, fmt.Errorf("failed to initialize OAuth2 configuration: %w", err)
 	}
@@ -199,16 +455,32 @@ func (a *AuthManager) HandleAuthChallenge(ctx context.Context, resp *http.Respon
 	verifier := oauth2.GenerateVerifier()
 	a.verifier = verifier
 
-	if err := a.startAuthServer(ctx); err != nil {
-		return "", nil, fmt.Errorf("failed to start auth server: %w", err)
-	}
-
 	state := generateState()
-	authURL := a.oauth2Config.AuthCodeURL(
-		state,
+	a.rememberState(state)
+	// Some IdPs (Auth0, Okta) only mint API tokens when an audience is
+	// requested explicitly.
+	authOpts := []oauth2.AuthCodeOption{
 		oauth2.AccessTypeOffline,
 		oauth2.S256ChallengeOption(verifier),
-	)
+	}
+	if a.opts.Audience != "" {
+		authOpts = append(authOpts, oauth2.SetAuthURLParam("audience", a.opts.Audience))
+	}
+	// RFC 8707: bind the token to the MCP server so it can't be replayed
+	// against other resources, as the MCP authorization spec requires.
+	if resource := a.resource(); resource != "" {
+		authOpts = append(authOpts, oauth2.SetAuthURLParam("resource", resource))
+	}
+	authURL := a.oauth2Config.AuthCodeURL(state, authOpts...)
+
+	// Besides handing the URL back for the client to relay, open it in the
+	// user's browser directly so authentication doesn't depend on the host
+	// app surfacing the link.
+	if !a.opts.DisableBrowser {
+		if err := openBrowser(authURL); err != nil {
+			a.logger.Debugf("Could not open browser: %v", err)
+		}
+	}
 
 	// Waiter blocks until the authentication flow is complete.
 	waiter := func() {
@@ -217,11 +489,258 @@ func (a *AuthManager) HandleAuthChallenge(ctx context.Context, resp *http.Respon
 	return authURL, waiter, nil
 }
 
-// GetAccessToken returns the current access token.
+// resource returns the RFC 8707 resource indicator: the configured override
+// when set, otherwise the URL of the server that issued the challenge.
+func (a *AuthManager) resource() string {
+	if a.opts.Resource != "" {
+		return a.opts.Resource
+	}
+	return a.resourceURL
+}
+
+// tokenKey is the cache key for the manager's current issuer and audience.
+// Before the first challenge the issuer is empty; tokens cached then are
+// re-keyed once discovery names it.
+func (a *AuthManager) tokenKey() TokenKey {
+	return TokenKey{Issuer: a.oidcConfig.Issuer, Audience: a.opts.Audience}
+}
+
+// currentToken returns the cached token for the manager's issuer, or nil.
+func (a *AuthManager) currentToken() *oauth2.Token {
+	return a.tokens.Get(a.tokenKey())
+}
+
+// GetAccessToken returns the current access token. If the token has expired
+// and a refresh token is available, it is renewed transparently first, so
+// callers keep working past the access token's lifetime without a new
+// browser round trip.
 func (a *AuthManager) GetAccessToken() string {
-	a.tokenMutex.RLock()
-	defer a.tokenMutex.RUnlock()
-	return a.accessToken
+	token := a.currentToken()
+	if token == nil {
+		return ""
+	}
+	if token.Valid() || token.RefreshToken == "" {
+		return token.AccessToken
+	}
+	if err := a.Refresh(context.Background()); err != nil {
+		a.logger.Warnf("Failed to refresh access token: %v", err)
+		return token.AccessToken
+	}
+	if fresh := a.currentToken(); fresh != nil {
+		return fresh.AccessToken
+	}
+	return ""
+}
+
+// setToken replaces the current OAuth2 token and, if a TokenStore is
+// configured, persists it. Persistence failures are logged but don't
+// invalidate the in-memory token.
+func (a *AuthManager) setToken(token *oauth2.Token) {
+	a.tokens.Put(a.tokenKey(), token)
+	if a.store != nil {
+		if err := a.store.Save(token); err != nil {
+			a.logger.Warnf("Failed to persist token: %v", err)
+		}
+	}
+}
+
+// TokenExpired implements ExpiryAwareAuthenticator. The expiry comes from
+// the token response's expires_in, which the oauth2 library records on the
+// token.
+func (a *AuthManager) TokenExpired() bool {
+	token := a.currentToken()
+	return token != nil && !token.Valid()
+}
+
+// HandleChallenge implements Authenticator by delegating to HandleAuthChallenge.
+func (a *AuthManager) HandleChallenge(ctx context.Context, resp *http.Response) (string, func(), error) {
+	return a.HandleAuthChallenge(ctx, resp)
+}
+
+// Token implements Authenticator by returning the current access token.
+func (a *AuthManager) Token() string {
+	return a.GetAccessToken()
+}
+
+// clientCredentialsToken obtains a token via the client_credentials grant
+// (RFC 6749 section 4.4) and caches it. It requires a pre-configured client
+// id and secret; public clients cannot use this grant.
+func (a *AuthManager) clientCredentialsToken(ctx context.Context) error {
+	if a.clientID == "" || a.clientSecret == "" {
+		return fmt.Errorf("the client_credentials grant requires both a client id and a client secret")
+	}
+	params := url.Values{}
+	if a.opts.Audience != "" {
+		params.Set("audience", a.opts.Audience)
+	}
+	if resource := a.resource(); resource != "" {
+		params.Set("resource", resource)
+	}
+	cc := clientcredentials.Config{
+		ClientID:       a.clientID,
+		ClientSecret:   a.clientSecret,
+		TokenURL:       a.oidcConfig.TokenEndpoint,
+		Scopes:         a.grantScopes,
+		EndpointParams: params,
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, a.httpClient)
+	token, err := cc.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("client_credentials grant failed: %w", err)
+	}
+	a.setToken(token)
+	a.logger.Debug("Obtained token via client_credentials grant")
+	return nil
+}
+
+// Refresh implements Authenticator by exchanging the stored refresh token
+// for a fresh access token. It fails if no refresh token was granted, in
+// which case a new challenge is required.
+func (a *AuthManager) Refresh(ctx context.Context) error {
+	// Client-credentials tokens carry no refresh token; renewal is simply
+	// another grant.
+	if a.opts.ClientCredentials && a.oidcConfig.TokenEndpoint != "" {
+		return a.clientCredentialsToken(ctx)
+	}
+	token := a.currentToken()
+	if token == nil || token.RefreshToken == "" {
+		return fmt.Errorf("no refresh token available; re-authentication required")
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, a.httpClient)
+	fresh, err := a.oauth2Config.TokenSource(ctx, token).Token()
+	if err != nil {
+		return fmt.Errorf("failed to refresh access token: %w", err)
+	}
+	a.setToken(fresh)
+	a.logger.Debug("Access token refreshed")
+	return nil
+}
+
+// RevokeTokens implements TokenRevoker by posting the refresh and access
+// token to the authorization server's RFC 7009 revocation endpoint and
+// clearing the stored token. Revocation is best-effort: a server that
+// doesn't advertise a revocation endpoint is not an error.
+func (a *AuthManager) RevokeTokens(ctx context.Context) error {
+	token := a.tokens.Take(a.tokenKey())
+	if token == nil {
+		return nil
+	}
+	if a.store != nil {
+		if err := a.store.Delete(); err != nil {
+			a.logger.Warnf("Failed to delete stored token: %v", err)
+		}
+	}
+	if a.oidcConfig.RevocationEndpoint == "" {
+		a.logger.Debug("Authorization server advertises no revocation endpoint; skipping revocation")
+		return nil
+	}
+
+	// Revoking the refresh token typically invalidates the whole grant, but
+	// RFC 7009 doesn't require that, so the access token is revoked too.
+	var errs []error
+	if token.RefreshToken != "" {
+		if err := a.revokeToken(ctx, token.RefreshToken, "refresh_token"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if token.AccessToken != "" {
+		if err := a.revokeToken(ctx, token.AccessToken, "access_token"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("token revocation failed: %v", errs)
+	}
+	a.logger.Debug("Tokens revoked")
+	return nil
+}
+
+// revokeToken posts a single token to the revocation endpoint with the
+// given RFC 7009 token_type_hint.
+func (a *AuthManager) revokeToken(ctx context.Context, token, hint string) error {
+	form := url.Values{
+		"token":           {token},
+		"token_type_hint": {hint},
+	}
+	if a.clientID != "" {
+		form.Set("client_id", a.clientID)
+	}
+	if a.clientSecret != "" {
+		form.Set("client_secret", a.clientSecret)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.oidcConfig.RevocationEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create revocation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to revoke %s: %w", hint, err)
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("revocation endpoint returned status %d for %s", resp.StatusCode, hint)
+	}
+	return nil
+}
+
+// ProtectedResourceMetadata is the RFC 9728 metadata document a resource
+// server publishes under /.well-known/oauth-protected-resource.
+type ProtectedResourceMetadata struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers"`
+}
+
+// parseResourceMetadataURL extracts the resource_metadata parameter from a
+// WWW-Authenticate header, or "" if the challenge carries none.
+func parseResourceMetadataURL(header string) string {
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+	for _, part := range strings.Split(strings.TrimPrefix(header, "Bearer "), ",") {
+		part = strings.TrimSpace(part)
+		if strings.HasPrefix(part, "resource_metadata=") {
+			return strings.Trim(part[len("resource_metadata="):], "\"")
+		}
+	}
+	return ""
+}
+
+// discoverAuthorizationServer fetches the Protected Resource Metadata at
+// metadataURL and returns the issuer of the first advertised authorization
+// server.
+func (a *AuthManager) discoverAuthorizationServer(ctx context.Context, metadataURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create resource metadata request: %w", err)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch resource metadata: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to fetch resource metadata, status: %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read resource metadata response: %w", err)
+	}
+	var metadata ProtectedResourceMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return "", fmt.Errorf("failed to parse resource metadata: %w", err)
+	}
+	if len(metadata.AuthorizationServers) == 0 {
+		return "", fmt.Errorf("resource metadata names no authorization servers")
+	}
+	return strings.TrimSuffix(metadata.AuthorizationServers[0], "/"), nil
 }
 
 // fetchOIDCConfiguration retrieves the OpenID Connect configuration from the server.
@@ -254,6 +773,9 @@ func (a *AuthManager) fetchOIDCConfiguration(ctx context.Context) error {
 	}
 	a.logger.Debugf("OIDC configuration fetched: auth_endpoint=%s, token_endpoint=%s",
 		a.oidcConfig.AuthorizationEndpoint, a.oidcConfig.TokenEndpoint)
+	// A token cached before the issuer was known (e.g. loaded from the
+	// store at startup) now belongs under the discovered issuer.
+	a.tokens.Rekey(TokenKey{}, a.tokenKey())
 	return nil
 }
 
@@ -275,15 +797,27 @@ func (a *AuthManager) initOAuth2Config(ctx context.Context, scopes []string) err
 
 // startAuthServer starts an HTTP server to handle the authentication callback.
 // It accepts a context that, when canceled, will cause the server to shut down gracefully.
+// The redirect URL is rebuilt from the listener's actual address, so an
+// ephemeral port is announced correctly.
 func (a *AuthManager) startAuthServer(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.HandleFunc(a.opts.CallbackPath, a.handleCallback)
 
+	port := a.opts.ListenPort
+	if port == EphemeralPort {
+		port = 0
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on callback port: %w", err)
+	}
+	boundPort := listener.Addr().(*net.TCPAddr).Port
+	a.redirectURL = fmt.Sprintf("http://localhost:%d%s", boundPort, a.opts.CallbackPath)
+
 	a.server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", a.opts.ListenPort),
 		Handler: mux,
 	}
-	a.logger.Debugf("Starting authentication server on port %d", a.opts.ListenPort)
+	a.logger.Debugf("Starting authentication server on port %d", boundPort)
 
 	// Listen for context cancellation to shut down the server.
 	go func() {
@@ -297,46 +831,86 @@ func (a *AuthManager) startAuthServer(ctx context.Context) error {
 	}()
 
 	go func() {
-		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := a.server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			a.logger.Errorf("HTTP server error: %v", err)
 		}
 	}()
 	return nil
 }
 
+// stateTTL bounds how long an issued OAuth state stays redeemable. A user
+// who hasn't completed the browser flow within this window has to restart
+// it.
+const stateTTL = 10 * time.Minute
+
+// rememberState records an issued state so the callback can verify it,
+// dropping any expired states while it holds the lock.
+func (a *AuthManager) rememberState(state string) {
+	a.stateMutex.Lock()
+	defer a.stateMutex.Unlock()
+	now := time.Now()
+	for s, issued := range a.pendingStates {
+		if now.Sub(issued) > stateTTL {
+			delete(a.pendingStates, s)
+		}
+	}
+	a.pendingStates[state] = now
+}
+
+// consumeState redeems a state exactly once, reporting whether it was
+// issued by this process and has not expired.
+func (a *AuthManager) consumeState(state string) bool {
+	a.stateMutex.Lock()
+	defer a.stateMutex.Unlock()
+	issued, ok := a.pendingStates[state]
+	if !ok {
+		return false
+	}
+	delete(a.pendingStates, state)
+	return time.Since(issued) <= stateTTL
+}
+
 // handleCallback processes the authentication callback request.
 func (a *AuthManager) handleCallback(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	// Verify the CSRF state before touching the code: a callback with an
+	// unknown or expired state was not initiated by this process.
+	if state := r.URL.Query().Get("state"); !a.consumeState(state) {
+		a.logger.Warn("Rejected auth callback with invalid or expired state")
+		http.Error(w, "invalid or expired state parameter; please restart the authentication flow", http.StatusBadRequest)
+		return
+	}
 	code := r.URL.Query().Get("code")
 	if code == "" {
 		http.Error(w, "missing code in request", http.StatusBadRequest)
 		return
 	}
 
-	oauth2Token, err := a.oauth2Config.Exchange(
-		ctx,
-		code,
-		oauth2.VerifierOption(a.verifier),
-	)
+	exchangeOpts := []oauth2.AuthCodeOption{oauth2.VerifierOption(a.verifier)}
+	if a.opts.Audience != "" {
+		exchangeOpts = append(exchangeOpts, oauth2.SetAuthURLParam("audience", a.opts.Audience))
+	}
+	if resource := a.resource(); resource != "" {
+		exchangeOpts = append(exchangeOpts, oauth2.SetAuthURLParam("resource", resource))
+	}
+	oauth2Token, err := a.oauth2Config.Exchange(ctx, code, exchangeOpts...)
 	if err != nil {
 		http.Error(w, "failed to exchange token: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	a.tokenMutex.Lock()
-	a.accessToken = oauth2Token.AccessToken
-	a.tokenMutex.Unlock()
+	a.setToken(oauth2Token)
 
 	w.Header().Set("Content-Type", "text/html")
-	w.Write([]byte(`
-		<html>
-		  <head><title>Authentication Successful</title></head>
-		  <body>
-			<h1>Authentication Successful</h1>
-			<p>You can now close this window and return to the application.</p>
-		  </body>
-		</html>
-	`))
+	data := callbackPageData{
+		Server:    a.serverURL,
+		User:      idTokenDisplayName(oauth2Token),
+		Scopes:    a.oauth2Config.Scopes,
+		AutoClose: a.opts.CallbackAutoClose,
+	}
+	if err := a.callbackTemplate.Execute(w, data); err != nil {
+		a.logger.Errorf("Failed to render callback page: %v", err)
+	}
 
 	go func() {
 		time.Sleep(1 * time.Second)
@@ -0,0 +1,31 @@
+package mcpengine
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// browserCommand picks the platform launcher for opening url in the user's
+// default browser.
+func browserCommand(goos, url string) (string, []string) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}
+	default:
+		return "xdg-open", []string{url}
+	}
+}
+
+// openBrowser launches the user's default browser on the given URL. It only
+// starts the launcher; whether a browser actually appears is up to the
+// desktop environment.
+func openBrowser(url string) error {
+	name, args := browserCommand(runtime.GOOS, url)
+	if err := exec.Command(name, args...).Start(); err != nil {
+		return fmt.Errorf("failed to launch %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package mcpengine
+
+import "testing"
+
+// ===== Browser Launch Tests =====
+
+func TestBrowserCommand(t *testing.T) {
+	testCases := []struct {
+		goos         string
+		expectedName string
+		expectedArgs []string
+	}{
+		{goos: "darwin", expectedName: "open", expectedArgs: []string{"https://auth.example.com"}},
+		{goos: "windows", expectedName: "rundll32", expectedArgs: []string{"url.dll,FileProtocolHandler", "https://auth.example.com"}},
+		{goos: "linux", expectedName: "xdg-open", expectedArgs: []string{"https://auth.example.com"}},
+		{goos: "freebsd", expectedName: "xdg-open", expectedArgs: []string{"https://auth.example.com"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.goos, func(t *testing.T) {
+			name, args := browserCommand(tc.goos, "https://auth.example.com")
+			if name != tc.expectedName {
+				t.Errorf("Expected launcher %q, got %q", tc.expectedName, name)
+			}
+			if len(args) != len(tc.expectedArgs) {
+				t.Fatalf("Expected args %v, got %v", tc.expectedArgs, args)
+			}
+			for i := range args {
+				if args[i] != tc.expectedArgs[i] {
+					t.Errorf("Expected args %v, got %v", tc.expectedArgs, args)
+				}
+			}
+		})
+	}
+}
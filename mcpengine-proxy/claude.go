@@ -0,0 +1,129 @@
+package mcpengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ClaudeConfigPath returns the platform location of Claude Desktop's
+// configuration file, the most common host application the proxy is
+// registered in. os.UserConfigDir resolves to the directory Claude Desktop
+// uses on every platform (Application Support, %AppData%, ~/.config).
+func ClaudeConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("claude: failed to locate the config directory: %w", err)
+	}
+	return filepath.Join(dir, "Claude", "claude_desktop_config.json"), nil
+}
+
+// ClaudeServerEntry is the command/args block Claude Desktop expects for
+// one entry under mcpServers.
+type ClaudeServerEntry struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// ClaudeSnippet builds the entry that registers the named server
+// definition with Claude Desktop: this binary, run with -server <name>.
+// The absolute executable path is used when it can be resolved, since
+// Claude Desktop does not search PATH the way a shell does.
+func ClaudeSnippet(name string) ClaudeServerEntry {
+	command := "mcpengine"
+	if executable, err := os.Executable(); err == nil {
+		command = executable
+	}
+	return ClaudeServerEntry{
+		Command: command,
+		Args:    []string{"-server", name},
+	}
+}
+
+// AddToClaudeConfig writes the entry into the mcpServers block of the
+// Claude Desktop config at path, creating the file if it doesn't exist
+// and preserving unrelated settings.
+func AddToClaudeConfig(path, name string, entry ClaudeServerEntry) error {
+	config := map[string]json.RawMessage{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("claude: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &config); err != nil {
+			return fmt.Errorf("claude: invalid JSON in %s: %w", path, err)
+		}
+	}
+	servers := map[string]json.RawMessage{}
+	if raw, ok := config["mcpServers"]; ok {
+		if err := json.Unmarshal(raw, &servers); err != nil {
+			return fmt.Errorf("claude: invalid mcpServers block in %s: %w", path, err)
+		}
+	}
+	entryData, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("claude: %w", err)
+	}
+	servers[name] = entryData
+	serversData, err := json.Marshal(servers)
+	if err != nil {
+		return fmt.Errorf("claude: %w", err)
+	}
+	config["mcpServers"] = serversData
+
+	updated, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("claude: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("claude: %w", err)
+	}
+	if err := os.WriteFile(path, updated, 0o600); err != nil {
+		return fmt.Errorf("claude: %w", err)
+	}
+	return nil
+}
+
+// RemoveFromClaudeConfig deletes the named entry from the mcpServers block
+// of the Claude Desktop config at path, reporting whether an entry was
+// removed. A missing file or a name that isn't registered removes nothing.
+// Unrelated settings in the file are preserved verbatim.
+func RemoveFromClaudeConfig(path, name string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claude: %w", err)
+	}
+
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil {
+		return false, fmt.Errorf("claude: invalid JSON in %s: %w", path, err)
+	}
+	var servers map[string]json.RawMessage
+	if raw, ok := config["mcpServers"]; ok {
+		if err := json.Unmarshal(raw, &servers); err != nil {
+			return false, fmt.Errorf("claude: invalid mcpServers block in %s: %w", path, err)
+		}
+	}
+	if _, ok := servers[name]; !ok {
+		return false, nil
+	}
+	delete(servers, name)
+
+	serversData, err := json.Marshal(servers)
+	if err != nil {
+		return false, fmt.Errorf("claude: %w", err)
+	}
+	config["mcpServers"] = serversData
+	updated, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("claude: %w", err)
+	}
+	if err := os.WriteFile(path, updated, 0o600); err != nil {
+		return false, fmt.Errorf("claude: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package mcpengine
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ===== Claude Desktop Config Tests =====
+
+func TestRemoveFromClaudeConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+	content := `{
+  "globalShortcut": "Cmd+Space",
+  "mcpServers": {
+    "github": {"command": "mcpengine", "args": ["-server", "github"]},
+    "other": {"command": "other-server"}
+  }
+}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+
+	removed, err := RemoveFromClaudeConfig(path, "github")
+	if err != nil {
+		t.Fatalf("Failed to remove: %v", err)
+	}
+	if !removed {
+		t.Error("Expected the entry to be removed")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read back: %v", err)
+	}
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Rewritten config is invalid JSON: %v", err)
+	}
+	// Unrelated settings survive the edit.
+	if _, ok := config["globalShortcut"]; !ok {
+		t.Error("Expected unrelated settings to be preserved")
+	}
+	var servers map[string]json.RawMessage
+	if err := json.Unmarshal(config["mcpServers"], &servers); err != nil {
+		t.Fatalf("Invalid mcpServers block: %v", err)
+	}
+	if _, ok := servers["github"]; ok {
+		t.Error("Expected github to be gone")
+	}
+	if _, ok := servers["other"]; !ok {
+		t.Error("Expected other entries to survive")
+	}
+}
+
+func TestClaudeSnippet(t *testing.T) {
+	entry := ClaudeSnippet("github")
+	if entry.Command == "" {
+		t.Error("Expected a command")
+	}
+	if len(entry.Args) != 2 || entry.Args[0] != "-server" || entry.Args[1] != "github" {
+		t.Errorf("Expected [-server github], got %v", entry.Args)
+	}
+}
+
+func TestAddToClaudeConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "claude_desktop_config.json")
+
+	// A missing file is created.
+	if err := AddToClaudeConfig(path, "github", ClaudeServerEntry{Command: "mcpengine", Args: []string{"-server", "github"}}); err != nil {
+		t.Fatalf("Failed to add: %v", err)
+	}
+	// Adding a second entry preserves the first.
+	if err := AddToClaudeConfig(path, "other", ClaudeServerEntry{Command: "other-server"}); err != nil {
+		t.Fatalf("Failed to add second entry: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read back: %v", err)
+	}
+	var config struct {
+		MCPServers map[string]ClaudeServerEntry `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("Written config is invalid JSON: %v", err)
+	}
+	if got := config.MCPServers["github"]; got.Command != "mcpengine" || len(got.Args) != 2 {
+		t.Errorf("Unexpected github entry: %+v", got)
+	}
+	if _, ok := config.MCPServers["other"]; !ok {
+		t.Error("Expected both entries to be present")
+	}
+}
+
+func TestRemoveFromClaudeConfig_NotRegistered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "claude_desktop_config.json")
+
+	// A missing file removes nothing and is not an error.
+	removed, err := RemoveFromClaudeConfig(path, "github")
+	if err != nil || removed {
+		t.Errorf("Expected (false, nil) for a missing file, got (%v, %v)", removed, err)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"mcpServers": {}}`), 0o600); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	removed, err = RemoveFromClaudeConfig(path, "github")
+	if err != nil || removed {
+		t.Errorf("Expected (false, nil) for an unregistered name, got (%v, %v)", removed, err)
+	}
+}
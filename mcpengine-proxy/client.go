@@ -0,0 +1,298 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// protocolVersion is the MCP protocol revision the client speaks.
+const protocolVersion = "2024-11-05"
+
+// clientName identifies this library in the initialize handshake.
+const clientName = "mcpengine"
+
+// Client is a programmatic MCP client built on the same transport and auth
+// stack as the stdio proxy. It lets Go programs talk to MCP servers directly
+// — Connect, Initialize, ListTools, CallTool, ReadResource — without going
+// through stdin/stdout.
+type Client struct {
+	engine *MCPEngine
+
+	cancel     context.CancelFunc
+	inputChan  chan string
+	outputChan chan string
+
+	mu      sync.Mutex
+	nextID  int
+	pending map[int]chan *jsonRPCEnvelope
+}
+
+// NewClient creates a Client from the same Config used by New. The Config's
+// SSE/MCP path settings and auth options apply unchanged.
+func NewClient(cfg Config) (*Client, error) {
+	engine, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		engine:  engine,
+		nextID:  1,
+		pending: make(map[int]chan *jsonRPCEnvelope),
+	}, nil
+}
+
+// Connect starts the transport workers (SSE subscription and HTTP sender)
+// and the response dispatcher. It must be called before any requests are
+// issued. The connection lives until Close is called or ctx is canceled.
+func (c *Client) Connect(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+
+	c.inputChan = make(chan string, 1_000)
+	c.outputChan = make(chan string, 1_000)
+	postPathChan := make(chan string, 1)
+
+	engine := c.engine
+	sender := NewHTTPPostSender(
+		engine.httpClient, engine.endpoint,
+		postPathChan, c.inputChan, c.outputChan,
+		engine.auth, engine.logger.With("worker", "http-post"),
+	)
+	go sender.Run(ctx, cancel)
+
+	if engine.useSse {
+		worker := NewSSEWorker(engine.sseClient, postPathChan, c.outputChan, engine.logger.With("worker", "sse"))
+		go worker.Run(ctx, cancel)
+	} else {
+		postPathChan <- engine.mcpPath
+	}
+
+	go c.dispatch(ctx)
+	return nil
+}
+
+// Close tears down the connection and all workers.
+func (c *Client) Close() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+}
+
+// dispatch routes responses from the transport to the callers waiting on them.
+func (c *Client) dispatch(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-c.outputChan:
+			if !ok {
+				return
+			}
+			var envelope jsonRPCEnvelope
+			if err := json.Unmarshal([]byte(msg), &envelope); err != nil {
+				c.engine.logger.Errorf("Failed to parse response: %v", err)
+				continue
+			}
+			if envelope.ID == nil {
+				c.engine.logger.Debugw("Ignoring notification from server", "msg", msg)
+				continue
+			}
+			c.mu.Lock()
+			ch, exists := c.pending[*envelope.ID]
+			if exists {
+				delete(c.pending, *envelope.ID)
+			}
+			c.mu.Unlock()
+			if !exists {
+				c.engine.logger.Warnw("Received response with unknown id", "id", *envelope.ID)
+				continue
+			}
+			ch <- &envelope
+		}
+	}
+}
+
+// call issues a JSON-RPC request and blocks until its response arrives or
+// ctx is canceled. The result is unmarshaled into result if non-nil.
+func (c *Client) call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	id := c.nextID
+	c.nextID++
+	respChan := make(chan *jsonRPCEnvelope, 1)
+	c.pending[id] = respChan
+	c.mu.Unlock()
+
+	req := jsonRPCRequest{
+		JSONRPC: "2.0",
+		ID:      &id,
+		Method:  method,
+		Params:  params,
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	select {
+	case c.inputChan <- string(data):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return ctx.Err()
+	case envelope := <-respChan:
+		if envelope.Error != nil {
+			return envelope.Error
+		}
+		if result != nil {
+			if err := json.Unmarshal(envelope.Result, result); err != nil {
+				return fmt.Errorf("failed to unmarshal result for %s: %w", method, err)
+			}
+		}
+		return nil
+	}
+}
+
+// notify sends a JSON-RPC notification (no id, no response expected).
+func (c *Client) notify(ctx context.Context, method string, params interface{}) error {
+	req := jsonRPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	select {
+	case c.inputChan <- string(data):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Initialize performs the MCP initialize handshake and sends the
+// notifications/initialized notification on success.
+func (c *Client) Initialize(ctx context.Context) (*InitializeResult, error) {
+	params := map[string]interface{}{
+		"protocolVersion": protocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]string{
+			"name":    clientName,
+			"version": "dev",
+		},
+	}
+	var result InitializeResult
+	if err := c.call(ctx, "initialize", params, &result); err != nil {
+		return nil, err
+	}
+	if err := c.notify(ctx, "notifications/initialized", nil); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListTools returns the tools the server exposes.
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var result listToolsResult
+	if err := c.call(ctx, "tools/list", map[string]interface{}{}, &result); err != nil {
+		return nil, err
+	}
+	return result.Tools, nil
+}
+
+// CallTool invokes a tool by name with the given arguments.
+func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*Result, error) {
+	params := map[string]interface{}{
+		"name":      name,
+		"arguments": arguments,
+	}
+	var result Result
+	if err := c.call(ctx, "tools/call", params, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ReadResource reads the resource identified by uri.
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]ResourceContents, error) {
+	params := map[string]interface{}{"uri": uri}
+	var result readResourceResult
+	if err := c.call(ctx, "resources/read", params, &result); err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+// jsonRPCRequest is an outgoing JSON-RPC message. A nil ID marks it as a
+// notification.
+type jsonRPCRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      *int        `json:"id,omitempty"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
+// jsonRPCEnvelope is an incoming JSON-RPC response before its result is
+// decoded into a typed value.
+type jsonRPCEnvelope struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      *int            `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *JSONRPCError   `json:"error"`
+}
+
+// JSONRPCError is the error object of a JSON-RPC response.
+type JSONRPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *JSONRPCError) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// InitializeResult holds the server's reply to the initialize request.
+type InitializeResult struct {
+	ProtocolVersion string          `json:"protocolVersion"`
+	Capabilities    json.RawMessage `json:"capabilities"`
+	ServerInfo      ServerInfo      `json:"serverInfo"`
+}
+
+// ServerInfo identifies the server implementation.
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Tool describes a tool exposed by the server.
+type Tool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	InputSchema json.RawMessage `json:"inputSchema"`
+}
+
+type listToolsResult struct {
+	Tools []Tool `json:"tools"`
+}
+
+// ResourceContents is one entry of a resources/read result.
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+type readResourceResult struct {
+	Contents []ResourceContents `json:"contents"`
+}
@@ -0,0 +1,197 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Client Tests =====
+
+// newMockMCPServer returns an httptest server that answers initialize,
+// tools/list, tools/call, and resources/read with canned responses.
+func newMockMCPServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req jsonRPCRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("Failed to parse request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// Notifications are acknowledged without a body.
+		if req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		var result string
+		switch req.Method {
+		case "initialize":
+			result = `{"protocolVersion": "2024-11-05", "capabilities": {}, "serverInfo": {"name": "mock", "version": "1.0"}}`
+		case "tools/list":
+			result = `{"tools": [{"name": "echo", "description": "Echoes input", "inputSchema": {"type": "object"}}]}`
+		case "tools/call":
+			result = `{"content": [{"type": "text", "text": "echoed"}], "isError": false}`
+		case "resources/read":
+			result = `{"contents": [{"uri": "file:///hello.txt", "mimeType": "text/plain", "text": "hello"}]}`
+		case "fail":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %d, "error": {"code": -32601, "message": "method not found"}}`, *req.ID)
+			return
+		default:
+			t.Errorf("Unexpected method: %s", req.Method)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %d, "result": %s}`, *req.ID, result)
+	}))
+}
+
+func newTestClient(t *testing.T, endpoint string) *Client {
+	t.Helper()
+	client, err := NewClient(Config{
+		Endpoint: endpoint,
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+	})
+	if err != nil {
+		t.Fatalf("NewClient returned error: %v", err)
+	}
+	return client
+}
+
+func TestClient_Initialize(t *testing.T) {
+	ts := newMockMCPServer(t)
+	defer ts.Close()
+
+	client := newTestClient(t, ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	result, err := client.Initialize(ctx)
+	if err != nil {
+		t.Fatalf("Initialize returned error: %v", err)
+	}
+	if result.ServerInfo.Name != "mock" {
+		t.Errorf("Expected server name %q, got %q", "mock", result.ServerInfo.Name)
+	}
+	if result.ProtocolVersion != "2024-11-05" {
+		t.Errorf("Expected protocol version %q, got %q", "2024-11-05", result.ProtocolVersion)
+	}
+}
+
+func TestClient_ListTools(t *testing.T) {
+	ts := newMockMCPServer(t)
+	defer ts.Close()
+
+	client := newTestClient(t, ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	tools, err := client.ListTools(ctx)
+	if err != nil {
+		t.Fatalf("ListTools returned error: %v", err)
+	}
+	if len(tools) != 1 {
+		t.Fatalf("Expected 1 tool, got %d", len(tools))
+	}
+	if tools[0].Name != "echo" {
+		t.Errorf("Expected tool name %q, got %q", "echo", tools[0].Name)
+	}
+}
+
+func TestClient_CallTool(t *testing.T) {
+	ts := newMockMCPServer(t)
+	defer ts.Close()
+
+	client := newTestClient(t, ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"message": "hi"})
+	if err != nil {
+		t.Fatalf("CallTool returned error: %v", err)
+	}
+	if result.IsError {
+		t.Error("Expected isError=false")
+	}
+	if len(result.Content) != 1 || result.Content[0].Text != "echoed" {
+		t.Errorf("Unexpected tool result content: %+v", result.Content)
+	}
+}
+
+func TestClient_ReadResource(t *testing.T) {
+	ts := newMockMCPServer(t)
+	defer ts.Close()
+
+	client := newTestClient(t, ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	contents, err := client.ReadResource(ctx, "file:///hello.txt")
+	if err != nil {
+		t.Fatalf("ReadResource returned error: %v", err)
+	}
+	if len(contents) != 1 {
+		t.Fatalf("Expected 1 content item, got %d", len(contents))
+	}
+	if contents[0].Text != "hello" {
+		t.Errorf("Expected text %q, got %q", "hello", contents[0].Text)
+	}
+}
+
+func TestClient_ServerError(t *testing.T) {
+	ts := newMockMCPServer(t)
+	defer ts.Close()
+
+	client := newTestClient(t, ts.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect returned error: %v", err)
+	}
+	defer client.Close()
+
+	err := client.call(ctx, "fail", nil, nil)
+	if err == nil {
+		t.Fatal("Expected error from server, got nil")
+	}
+	var rpcErr *JSONRPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("Expected *JSONRPCError, got %T: %v", err, err)
+	}
+	if rpcErr.Code != -32601 {
+		t.Errorf("Expected code -32601, got %d", rpcErr.Code)
+	}
+}
@@ -0,0 +1,414 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"mcpengine"
+)
+
+// subcommands maps the verb-style commands (mcpengine list, mcpengine
+// install <name>, ...) to their implementations. Plain flag invocations
+// still run the proxy itself.
+var subcommands = map[string]func(args []string) int{
+	"list":        runList,
+	"install":     runInstall,
+	"uninstall":   runUninstall,
+	"doctor":      runDoctor,
+	"config":      runConfig,
+	"init":        runInit,
+	"self-update": runSelfUpdate,
+	"dlq":         runDLQ,
+}
+
+// runDLQ inspects a dead-letter file written via -dead_letter_file, or
+// drains it for re-dispatch: -redispatch prints the raw JSON-RPC messages
+// (ready to pipe back into a running proxy) and clears the file.
+func runDLQ(args []string) int {
+	flags := flag.NewFlagSet("dlq", flag.ExitOnError)
+	redispatch := flags.Bool("redispatch", false, "Print the raw messages for re-dispatch and clear the file")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: mcpengine dlq [-redispatch] <file>")
+		return 1
+	}
+	path := flags.Arg(0)
+
+	if *redispatch {
+		letters, err := mcpengine.TakeDeadLetters(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to drain dead letters: %s\n", err)
+			return 1
+		}
+		for _, letter := range letters {
+			fmt.Println(letter.Message)
+		}
+		fmt.Fprintf(os.Stderr, "Re-dispatched %d message(s); %s cleared\n", len(letters), path)
+		return 0
+	}
+
+	letters, err := mcpengine.ReadDeadLetters(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to read dead letters: %s\n", err)
+		return 1
+	}
+	if len(letters) == 0 {
+		fmt.Println("No dead letters")
+		return 0
+	}
+	writer := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "TIME\tREASON\tMESSAGE")
+	for _, letter := range letters {
+		fmt.Fprintf(writer, "%s\t%s\t%s\n",
+			letter.Time.Format(time.RFC3339), letter.Reason, letter.Message)
+	}
+	writer.Flush()
+	return 0
+}
+
+// runSelfUpdate replaces the running binary with the latest release,
+// after verifying the manifest's checksum for this platform.
+func runSelfUpdate(args []string) int {
+	flags := flag.NewFlagSet("self-update", flag.ExitOnError)
+	url := flags.String("url", mcpengine.DefaultManifestURL, "URL of the release manifest")
+	check := flags.Bool("check", false, "Only report whether an update is available")
+	flags.Parse(args)
+
+	updater := &mcpengine.Updater{ManifestURL: *url}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	manifest, available, err := updater.Check(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to check for updates: %s\n", err)
+		return 1
+	}
+	if !available {
+		fmt.Printf("Already up to date (%s)\n", mcpengine.Version)
+		return 0
+	}
+	fmt.Printf("Update available: %s -> %s\n", mcpengine.Version, manifest.Version)
+	if *check {
+		return 0
+	}
+	if err := updater.Apply(ctx, manifest); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to update: %s\n", err)
+		return 1
+	}
+	fmt.Printf("Updated to %s\n", manifest.Version)
+	return 0
+}
+
+// serversPathFlag registers the shared -servers override on a subcommand's
+// flag set and returns a resolver that falls back to the default location.
+func serversPathFlag(flags *flag.FlagSet) func() (string, error) {
+	path := flags.String("servers", "", "Path of the servers file (default: <user config dir>/mcpengine/servers.yaml)")
+	return func() (string, error) {
+		if *path != "" {
+			return *path, nil
+		}
+		return mcpengine.ServersPath()
+	}
+}
+
+// runList prints the known server definitions: locally defined ones,
+// installed ones, and the bundled definitions that haven't been installed.
+func runList(args []string) int {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	resolvePath := serversPathFlag(flags)
+	flags.Parse(args)
+
+	path, err := resolvePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to locate servers file: %s\n", err)
+		return 1
+	}
+	summaries, err := mcpengine.ListServers(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load servers: %s\n", err)
+		return 1
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tSOURCE\tTRANSPORT\tENDPOINT\tAUTH\tTOKEN\tPROFILES")
+	for _, summary := range summaries {
+		token := "-"
+		if summary.TokenCached {
+			token = "cached"
+		}
+		profiles := "-"
+		if len(summary.Profiles) > 0 {
+			profiles = strings.Join(summary.Profiles, ",")
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			summary.Name, summary.Source, summary.Transport, summary.Endpoint, summary.AuthMode, token, profiles)
+	}
+	writer.Flush()
+	return 0
+}
+
+// runInstall copies a server definition — bundled, or fetched from a
+// remote registry — into the local servers file.
+func runInstall(args []string) int {
+	flags := flag.NewFlagSet("install", flag.ExitOnError)
+	resolvePath := serversPathFlag(flags)
+	force := flags.Bool("force", false, "Overwrite an existing entry with the same name")
+	registryURL := flags.String("registry", "", "Also offer definitions from this registry index URL (verified against its .sha256 sidecar and cached locally)")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: mcpengine install [-force] [-registry url] <name>")
+		return 1
+	}
+	name := flags.Arg(0)
+
+	source := mcpengine.BundledServers()
+	if *registryURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		remote, fromCache, err := mcpengine.NewRegistry(*registryURL).Fetch(ctx)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fetch registry: %s\n", err)
+			return 1
+		}
+		if fromCache {
+			fmt.Fprintln(os.Stderr, "Registry unreachable; using the cached index")
+		}
+		// Registry definitions shadow bundled ones of the same name.
+		for remoteName, opts := range remote {
+			source[remoteName] = opts
+		}
+	}
+
+	path, err := resolvePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to locate servers file: %s\n", err)
+		return 1
+	}
+	if err := mcpengine.InstallServerFrom(path, name, source, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to install %s: %s\n", name, err)
+		return 1
+	}
+	fmt.Printf("Installed %s to %s\n", name, path)
+	return 0
+}
+
+// runUninstall removes a server entry from the local servers file,
+// optionally purging its cached credentials and its Claude Desktop
+// registration.
+func runUninstall(args []string) int {
+	flags := flag.NewFlagSet("uninstall", flag.ExitOnError)
+	resolvePath := serversPathFlag(flags)
+	purge := flags.Bool("purge", false, "Also delete the server's cached OAuth token and registered client from the OS keychain")
+	claude := flags.Bool("claude", false, "Also remove the server from the Claude Desktop config if registered there")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: mcpengine uninstall [-purge] [-claude] <name>")
+		return 1
+	}
+	name := flags.Arg(0)
+
+	path, err := resolvePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to locate servers file: %s\n", err)
+		return 1
+	}
+	opts, err := mcpengine.UninstallServer(path, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to uninstall %s: %s\n", name, err)
+		return 1
+	}
+	fmt.Printf("Removed %s from %s\n", name, path)
+
+	if *purge {
+		if host := opts["host"]; host != "" {
+			if err := mcpengine.NewKeychainTokenStore(host).Delete(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to delete cached token: %s\n", err)
+				return 1
+			}
+			if err := mcpengine.NewKeychainClientStore(host).DeleteClient(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to delete registered client: %s\n", err)
+				return 1
+			}
+			fmt.Printf("Purged cached credentials for %s\n", host)
+		}
+	}
+	if *claude {
+		claudePath, err := mcpengine.ClaudeConfigPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to locate Claude Desktop config: %s\n", err)
+			return 1
+		}
+		removed, err := mcpengine.RemoveFromClaudeConfig(claudePath, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to edit Claude Desktop config: %s\n", err)
+			return 1
+		}
+		if removed {
+			fmt.Printf("Removed %s from %s\n", name, claudePath)
+		}
+	}
+	return 0
+}
+
+// runConfig emits host-application config. The only target today is
+// Claude Desktop: mcpengine config claude <name> prints the mcpServers
+// block to paste, or patches it in place with -write.
+func runConfig(args []string) int {
+	if len(args) < 1 || args[0] != "claude" {
+		fmt.Fprintln(os.Stderr, "Usage: mcpengine config claude [-write] <name>")
+		return 1
+	}
+	flags := flag.NewFlagSet("config claude", flag.ExitOnError)
+	resolvePath := serversPathFlag(flags)
+	write := flags.Bool("write", false, "Patch the entry into claude_desktop_config.json instead of printing it")
+	flags.Parse(args[1:])
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: mcpengine config claude [-write] <name>")
+		return 1
+	}
+	name := flags.Arg(0)
+
+	// The name must exist so users don't register a server that list
+	// doesn't know about.
+	path, err := resolvePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to locate servers file: %s\n", err)
+		return 1
+	}
+	servers, err := mcpengine.LoadServers(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load servers: %s\n", err)
+		return 1
+	}
+	if _, ok := servers[name]; !ok {
+		if _, ok = mcpengine.BundledServers()[name]; !ok {
+			fmt.Fprintf(os.Stderr, "No server named %q; see \"mcpengine list\"\n", name)
+			return 1
+		}
+	}
+	entry := mcpengine.ClaudeSnippet(name)
+
+	if *write {
+		claudePath, err := mcpengine.ClaudeConfigPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to locate Claude Desktop config: %s\n", err)
+			return 1
+		}
+		if err := mcpengine.AddToClaudeConfig(claudePath, name, entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to edit Claude Desktop config: %s\n", err)
+			return 1
+		}
+		fmt.Printf("Registered %s in %s\n", name, claudePath)
+		fmt.Println("Restart Claude Desktop to pick up the change")
+		return 0
+	}
+
+	snippet, err := json.MarshalIndent(map[string]map[string]mcpengine.ClaudeServerEntry{
+		"mcpServers": {name: entry},
+	}, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to render snippet: %s\n", err)
+		return 1
+	}
+	fmt.Println(string(snippet))
+	return 0
+}
+
+// doctorConfigFor builds the diagnostics description, either from a named
+// server definition or from the given defaults.
+func doctorConfigFor(opts mcpengine.ServerOptions, host string) mcpengine.DoctorConfig {
+	cfg := mcpengine.DoctorConfig{
+		Endpoint:        host,
+		SSEPath:         opts["sse_path"],
+		MCPPath:         opts["mcp_path"],
+		KeychainAccount: host,
+	}
+	if opts["host"] != "" {
+		cfg.Endpoint = opts["host"]
+		cfg.KeychainAccount = opts["host"]
+	}
+	// Derive the transport the same way the list command does, so the
+	// probe matches what running the server would contact.
+	cfg.Transport = mcpengine.TransportMode(mcpengine.SummarizeServer("", "", opts).Transport)
+	if port := opts["auth_port"]; port != "" {
+		if parsed, err := strconv.Atoi(port); err == nil {
+			if parsed == 0 {
+				parsed = mcpengine.EphemeralPort
+			}
+			cfg.CallbackPort = parsed
+		}
+	}
+	return cfg
+}
+
+// runDoctor diagnoses a server: connectivity, OIDC discovery, the callback
+// port, and the cached token, printing a remedy for anything wrong.
+func runDoctor(args []string) int {
+	flags := flag.NewFlagSet("doctor", flag.ExitOnError)
+	resolvePath := serversPathFlag(flags)
+	host := flags.String("host", "", "Diagnose this endpoint instead of a named server")
+	profile := flags.String("profile", os.Getenv("MCPENGINE_PROFILE"), "Profile of the named server to diagnose")
+	timeout := flags.Duration("timeout", 10*time.Second, "Overall deadline for the network probes")
+	flags.Parse(args)
+	if flags.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: mcpengine doctor [-host url] [name]")
+		return 1
+	}
+
+	opts := mcpengine.ServerOptions{}
+	if flags.NArg() == 1 {
+		name := flags.Arg(0)
+		path, err := resolvePath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to locate servers file: %s\n", err)
+			return 1
+		}
+		servers, err := mcpengine.LoadServers(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load servers: %s\n", err)
+			return 1
+		}
+		definition, ok := servers[name]
+		if !ok {
+			definition, ok = mcpengine.BundledServers()[name]
+		}
+		if !ok {
+			fmt.Fprintf(os.Stderr, "No server named %q; see \"mcpengine list\"\n", name)
+			return 1
+		}
+		opts, err = definition.Resolve(*profile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to resolve profile: %s\n", err)
+			return 1
+		}
+	} else if *host == "" {
+		fmt.Fprintln(os.Stderr, "Usage: mcpengine doctor [-host url] [name]")
+		return 1
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	checks := mcpengine.RunDoctor(ctx, doctorConfigFor(opts, *host))
+
+	failed := false
+	for _, check := range checks {
+		fmt.Printf("%-4s %-14s %s\n", strings.ToUpper(string(check.Status)), check.Name, check.Detail)
+		if check.Remedy != "" {
+			fmt.Printf("     %-14s -> %s\n", "", check.Remedy)
+		}
+		if check.Status == mcpengine.DoctorFail {
+			failed = true
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
@@ -5,64 +5,709 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
 
 	"mcpengine"
 )
 
+// headerFlags collects repeated -header flags of the form "Name: Value".
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	pairs := make([]string, 0, len(h))
+	for name, value := range h {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", name, value))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func (h headerFlags) Set(value string) error {
+	name, val, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected \"Name: Value\", got %q", value)
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+// paramFlags collects repeated query-parameter flags of the form "name=value".
+type paramFlags map[string]string
+
+func (p paramFlags) String() string {
+	pairs := make([]string, 0, len(p))
+	for name, value := range p {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", name, value))
+	}
+	return strings.Join(pairs, ", ")
+}
+
+func (p paramFlags) Set(value string) error {
+	name, val, found := strings.Cut(value, "=")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("expected \"name=value\", got %q", value)
+	}
+	p[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
+}
+
+// listFlags collects a repeatable string flag.
+type listFlags []string
+
+func (l *listFlags) String() string { return strings.Join(*l, ", ") }
+
+func (l *listFlags) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// expandList environment-expands every entry, for flags carrying secrets
+// like -docker_env KEY=${SECRET}.
+func expandList(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	expanded := make([]string, len(values))
+	for i, value := range values {
+		expanded[i] = os.ExpandEnv(value)
+	}
+	return expanded
+}
+
+// splitScopes parses the comma-separated -scopes value, returning nil for
+// an empty flag so the challenge's advertised scopes apply.
+func splitScopes(value string) []string {
+	if value == "" {
+		return nil
+	}
+	var scopes []string
+	for _, scope := range strings.Split(value, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+// parseMethodRateLimit parses a -rate_limit_method value of the form
+// "method=rps" or "method=rps:burst".
+func parseMethodRateLimit(spec string) (string, mcpengine.MethodRateLimit, error) {
+	method, rest, found := strings.Cut(spec, "=")
+	method = strings.TrimSpace(method)
+	if !found || method == "" {
+		return "", mcpengine.MethodRateLimit{}, fmt.Errorf("expected \"method=rps\" or \"method=rps:burst\", got %q", spec)
+	}
+	rpsStr, burstStr, hasBurst := strings.Cut(rest, ":")
+	rps, err := strconv.ParseFloat(strings.TrimSpace(rpsStr), 64)
+	if err != nil {
+		return "", mcpengine.MethodRateLimit{}, fmt.Errorf("invalid rate %q: %w", rpsStr, err)
+	}
+	limit := mcpengine.MethodRateLimit{RequestsPerSecond: rps}
+	if hasBurst {
+		if limit.Burst, err = strconv.Atoi(strings.TrimSpace(burstStr)); err != nil {
+			return "", mcpengine.MethodRateLimit{}, fmt.Errorf("invalid burst %q: %w", burstStr, err)
+		}
+	}
+	return method, limit, nil
+}
+
+// loadTransforms reads a YAML list of transform rules from path.
+func loadTransforms(path string) ([]mcpengine.TransformRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []mcpengine.TransformRule
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("invalid YAML in %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// applyConfigFile loads YAML key/value pairs from path and applies them to
+// any flag not explicitly set on the command line, so CLI flags take
+// precedence over file values. Keys use the same names as the flags.
+func applyConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("invalid YAML in %s: %w", path, err)
+	}
+
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	for name, value := range values {
+		if name == "config" {
+			return fmt.Errorf("config file cannot set %q", name)
+		}
+		if flag.Lookup(name) == nil {
+			return fmt.Errorf("unknown option %q", name)
+		}
+		if setFlags[name] {
+			continue
+		}
+		if err := flag.Set(name, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("invalid value for %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyServerDefinition looks up name among the local and bundled server
+// definitions, resolves the selected profile, and applies the options to
+// any flag not explicitly set, the same precedence -config uses.
+func applyServerDefinition(name, profile string) error {
+	path, err := mcpengine.ServersPath()
+	if err != nil {
+		return err
+	}
+	servers, err := mcpengine.LoadServers(path)
+	if err != nil {
+		return err
+	}
+	opts, ok := servers[name]
+	if !ok {
+		opts, ok = mcpengine.BundledServers()[name]
+	}
+	if !ok {
+		return fmt.Errorf("no server named %q; see \"mcpengine list\"", name)
+	}
+	opts, err = opts.Resolve(profile)
+	if err != nil {
+		return err
+	}
+
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+	for key, value := range opts {
+		if key == "server" || key == "config" {
+			return fmt.Errorf("server %q cannot set %q", name, key)
+		}
+		if flag.Lookup(key) == nil {
+			return fmt.Errorf("server %q sets unknown option %q", name, key)
+		}
+		if setFlags[key] {
+			continue
+		}
+		if err := flag.Set(key, value); err != nil {
+			return fmt.Errorf("invalid value for %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
 func main() {
+	// Verb-style subcommands come before any proxy flags; everything else
+	// runs the proxy as before.
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(run(os.Args[2:]))
+		}
+	}
+	// "validate" takes the same flags as the proxy itself and is just a
+	// forced dry run: mcpengine validate -config file.
+	validateVerb := false
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		validateVerb = true
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	host := flag.String("host", "localhost:8000", "The hostname. By default we connect to <hostname>/sse")
 	clientId := flag.String("client_id", "", "The ClientID to be used in OAuth")
 	clientSecret := flag.String("client_secret", "", "The Client Secret to be used in OAuth (can be empty if using PKCE)")
-	mode := flag.String("mode", "sse", "The style of HTTP communication to use with the server (one of: sse, http)")
+	audience := flag.String("audience", "", "OAuth audience parameter, required by some IdPs (Auth0, Okta) to mint API tokens")
+	resource := flag.String("resource", "", "RFC 8707 resource indicator sent on token requests (default: the MCP server's URL)")
+	clientCredentials := flag.Bool("client_credentials", false, "Use the OAuth client_credentials grant (no browser; requires -client_id and -client_secret)")
+	scopes := flag.String("scopes", "", "Comma-separated OAuth scopes to request (default: those advertised in the auth challenge)")
+	bearerToken := flag.String("bearer_token", "", "Static bearer token to attach to requests, bypassing OAuth; environment-expanded, e.g. ${MCP_TOKEN}")
+	bearerTokenFile := flag.String("bearer_token_file", "", "Read the static bearer token from this file instead of the command line")
+	apiKey := flag.String("api_key", "", "API key to attach to requests, bypassing OAuth; environment-expanded, e.g. ${MCP_API_KEY}")
+	credentialHelper := flag.String("credential_helper", "", "External command (with arguments, space-separated) that prints a token on stdout, like git/docker credential helpers")
+	credentialHelperTTL := flag.Duration("credential_helper_ttl", 0, "How long to cache the helper's token before re-running it (0 caches until a 401)")
+	apiKeyHeader := flag.String("api_key_header", "X-Api-Key", "Header name the API key is sent in")
+	apiKeyQuery := flag.String("api_key_query", "", "Send the API key as this query parameter instead of a header")
+	mode := flag.String("mode", "sse", "The style of HTTP communication to use with the server (one of: sse, http, streamable, auto)")
 	ssePath := flag.String("sse_path", "/sse", "The path to append to hostname for an /sse connection")
 	mcpPath := flag.String("mcp_path", "/mcp", "The path to append to hostname for non-SSE POST")
 	debug := flag.Bool("debug", false, "Enable debug logging")
-	authListenPort := flag.Int("auth_port", 8181, "The port on which the auth server listens")
+	logFile := flag.String("log_file", "", "Also write logs to this file (in addition to stderr)")
+	logFileLevel := flag.String("log_file_level", "debug", "Log level for the file sink (one of: debug, info, warn, error)")
+	logFileMaxSize := flag.Int("log_file_max_size", 0, "Rotate -log_file once it exceeds this many megabytes (0 disables rotation unless a retention flag is set)")
+	logFileMaxBackups := flag.Int("log_file_max_backups", 0, "Keep at most this many rotated log files (0 keeps all)")
+	logFileMaxAge := flag.Int("log_file_max_age", 0, "Delete rotated log files older than this many days (0 keeps them forever)")
+	redact := flag.Bool("redact", true, "Mask bearer tokens and secret JSON fields in log output (-redact=false logs raw messages)")
+	redactFields := flag.String("redact_fields", "", "Comma-separated extra JSON fields to mask in logs, e.g. params.password")
+	authListenPort := flag.Int("auth_port", 8181, "The port on which the auth server listens (0 binds a random free port)")
+	noBrowser := flag.Bool("no_browser", false, "Don't open the system browser on auth challenges (for headless hosts)")
+	callbackTemplate := flag.String("callback_template", "", "HTML template file for the auth callback success page")
+	callbackAutoClose := flag.Bool("callback_autoclose", false, "Auto-close the browser window after successful authentication")
+	startupTimeout := flag.Duration("startup_timeout", 0, "How long to wait for the server's POST endpoint before giving up (0 to wait forever)")
+	requestTimeout := flag.Duration("request_timeout", 0, "Per-request timeout; unanswered requests get a JSON-RPC error (0 to disable)")
+	trackOrphans := flag.Bool("track_orphans", false, "Warn about unanswered requests and unknown response ids without synthesizing timeout errors")
+	orphanWarnAfter := flag.Duration("orphan_warn_after", 0, "How long a request may go unanswered before -track_orphans warns (0 for the 1m default)")
+	keepaliveInterval := flag.Duration("keepalive_interval", 0, "Send an MCP ping after this much inbound silence and shut down if it goes unanswered (0 to disable)")
+	keepaliveTimeout := flag.Duration("keepalive_timeout", 0, "How long a keepalive ping may go unanswered before the connection is deemed dead (0 to use keepalive_interval)")
+	sseStallTimeout := flag.Duration("sse_stall_timeout", 0, "Tear down and reconnect the SSE stream when no event arrives for this long (0 to disable)")
+	maxMessageSize := flag.Int("max_message_size", 0, "Maximum size in bytes of a single JSON-RPC message (0 for the 10MiB default)")
+	compress := flag.Bool("compress", false, "Gzip-compress POST bodies to reduce bandwidth on slow links")
+	maxRequestSize := flag.Int("max_request_size", 0, "Reject client requests larger than this many bytes with a JSON-RPC error (0 to disable)")
+	maxResponseSize := flag.Int("max_response_size", 0, "Replace server responses larger than this many bytes with a JSON-RPC error (0 to disable)")
+	maxContentSize := flag.Int("max_content_size", 0, "Spill result content items larger than this many bytes to a file and substitute a placeholder (0 to disable)")
+	contentSpillDir := flag.String("content_spill_dir", "", "Directory for spilled oversized content (default system temp; requires -max_content_size)")
+	follow := flag.Bool("follow", false, "Keep reading input after EOF instead of exiting (for FIFO stdin in daemon deployments)")
+	shareSocket := flag.String("share_socket", "", "Accept additional stdio sessions on this Unix socket so local clients share one upstream connection and login")
+	notifyClient := flag.Bool("notify_client", false, "Emit proxy events as MCP logging notifications on stdout")
+	useKeychain := flag.Bool("keychain", false, "Persist OAuth tokens to the OS keychain so restarts don't require re-authentication")
+	revokeOnExit := flag.Bool("revoke_on_exit", false, "Revoke OAuth tokens at the authorization server when the proxy exits")
+	proxyURL := flag.String("proxy", "", "Route all outbound connections through this HTTP proxy URL (default: honor HTTP(S)_PROXY environment variables)")
+	tlsCAFile := flag.String("tls_ca_file", "", "PEM bundle of extra root CAs to trust (for servers behind a private CA)")
+	tlsCertFile := flag.String("tls_cert_file", "", "Client certificate to present to the server (requires -tls_key_file)")
+	tlsKeyFile := flag.String("tls_key_file", "", "Private key for -tls_cert_file")
+	tlsMinVersion := flag.String("tls_min_version", "", "Minimum TLS version (one of: 1.0, 1.1, 1.2, 1.3; default: Go's default)")
+	tlsInsecure := flag.Bool("tls_insecure", false, "Skip server certificate verification (development only)")
+	configPath := flag.String("config", "", "Path to a YAML config file; flags set on the command line take precedence")
+	serverName := flag.String("server", "", "Run a named server definition from the servers file (see \"mcpengine list\"); flags set on the command line take precedence")
+	dryRun := flag.Bool("dry_run", false, "Validate the resolved configuration and exit without starting any workers (same as the validate subcommand)")
+	profile := flag.String("profile", os.Getenv("MCPENGINE_PROFILE"), "Profile of the -server definition to run (e.g. dev, staging, prod); defaults to $MCPENGINE_PROFILE")
+	replayFile := flag.String("replay", "", "Replay server responses from this recorded session file instead of contacting the server")
+	traceFile := flag.String("trace", "", "Dump a secret-redacted wire-level trace (stdio lines, HTTP exchanges, SSE chunks) to this file")
+	deadLetterFile := flag.String("dead_letter_file", "", "Persist undeliverable messages with failure reasons to this file (inspect with \"mcpengine dlq\")")
+	offlineBuffer := flag.Int("offline_buffer", 0, "Buffer up to this many outgoing requests in memory while the server is unreachable, delivering them on recovery (0 fails fast)")
+	offlineSpillFile := flag.String("offline_spill_file", "", "Spill offline-buffered requests beyond -offline_buffer to this file instead of dropping them")
+	parkOnAuth := flag.Bool("park_on_auth", false, "Park requests blocked by an auth challenge and re-send them once authentication completes")
+	sessionFile := flag.String("session_file", "", "Persist the negotiated session to this file so a quick restart resumes it instead of re-initializing")
+	channelBuffer := flag.Int("channel_buffer", 0, "Capacity of the internal message channels between workers (default 1000)")
+	inputOverflow := flag.String("input_overflow", "", "Policy when the client-to-server channel is full: block (default), drop-oldest, or error")
+	outputOverflow := flag.String("output_overflow", "", "Policy when the server-to-client channel is full: block (default), drop-oldest, or error")
+	postConcurrency := flag.Int("post_concurrency", 0, "Allow up to this many POSTs in flight at once (0 or 1 posts sequentially)")
+	postOrdering := flag.String("post_ordering", "", "Ordering among concurrent POSTs: none (default), method, or global (requires -post_concurrency)")
+	rateLimit := flag.Float64("rate_limit", 0, "Throttle outgoing requests to this many per second (0 disables)")
+	rateLimitBurst := flag.Int("rate_limit_burst", 0, "Token bucket capacity for -rate_limit (default: the rate rounded up)")
+	rateLimitPolicy := flag.String("rate_limit_policy", "", "Over-limit behavior: wait (default) or error")
+	var rateLimitMethods listFlags
+	flag.Var(&rateLimitMethods, "rate_limit_method", "Per-method rate limit as \"method=rps\" or \"method=rps:burst\" (repeatable)")
+	transformFile := flag.String("transform_file", "", "YAML file of declarative transform rules (set/remove/rename on matching methods)")
+	dialTimeout := flag.Duration("dial_timeout", 0, "Timeout for establishing new TCP connections (0 keeps Go's 30s default)")
+	tcpKeepAlive := flag.Duration("tcp_keep_alive", 0, "Interval between TCP keep-alive probes; negative disables them (0 keeps Go's default)")
+	tlsHandshakeTimeout := flag.Duration("tls_handshake_timeout", 0, "Timeout for the TLS handshake (0 keeps Go's 10s default)")
+	responseHeaderTimeout := flag.Duration("response_header_timeout", 0, "Timeout waiting for response headers after a request is sent; does not limit SSE streams (0 means no limit)")
+	idleConnTimeout := flag.Duration("idle_conn_timeout", 0, "How long idle connections stay pooled (0 keeps Go's 90s default)")
+	maxIdleConnsPerHost := flag.Int("max_idle_conns_per_host", 0, "Idle connection pool size per host (0 keeps Go's default of 2)")
+	maxConnsPerHost := flag.Int("max_conns_per_host", 0, "Cap on total connections per host, dialing included (0 means no limit)")
+	sessionMaxAge := flag.Duration("session_max_age", 0, "Ignore a persisted session older than this (default 5m; requires -session_file)")
+	healthAddr := flag.String("health_addr", "", "Serve /healthz and /status on this address (e.g. 127.0.0.1:8182) for supervisors; empty disables")
+	execCommand := flag.String("exec", "", "Launch this local MCP server command (with arguments, space-separated) and bridge to it over stdio instead of connecting to -host")
+	dockerImage := flag.String("docker_image", "", "Run the MCP server from this container image (docker run -i --rm) and bridge stdio through it; -exec supplies the in-container command")
+	dockerNetwork := flag.String("docker_network", "", "Docker network for -docker_image, e.g. \"none\" to isolate the server")
+	dockerBinary := flag.String("docker_binary", "", "Container CLI to use with -docker_image (default: docker; podman also works)")
+	kubeTarget := flag.String("kube_target", "", "Reach the server through a kubectl port-forward to this target, e.g. \"service/mcp\"; requires -kube_port")
+	kubePort := flag.Int("kube_port", 0, "Remote port the MCP server listens on for -kube_target")
+	kubeLocalPort := flag.Int("kube_local_port", 0, "Local port for the port-forward (0 picks a free port)")
+	kubeNamespace := flag.String("kube_namespace", "", "Namespace of -kube_target (default: the context's namespace)")
+	kubeContext := flag.String("kube_context", "", "Kube context for the port-forward (default: the current context)")
+	kubeBinary := flag.String("kube_binary", "", "kubectl-compatible CLI to use for the port-forward (default: kubectl)")
+	serveAddr := flag.String("serve", "", "Reverse mode: expose the -exec stdio server as an SSE + POST endpoint on this address (e.g. 0.0.0.0:8900)")
+	serveBearerToken := flag.String("serve_bearer_token", "", "Bearer token remote clients must present in reverse mode; environment-expanded")
+	http2Mode := flag.String("http2", "", "Pin the HTTP version for upstream connections (one of: force, h2c); default negotiates via ALPN")
+	grpcTarget := flag.String("grpc_target", "", "Experimental: carry the session over a gRPC bidirectional stream to this host:port instead of HTTP")
+	grpcMethod := flag.String("grpc_method", "", "Full method name of the gRPC session stream (default: /mcp.MCPEngine/Session)")
+	grpcPlainText := flag.Bool("grpc_plaintext", false, "Dial -grpc_target without TLS, for internal deployments")
+	otlpEndpoint := flag.String("otlp_endpoint", "", "Export an OpenTelemetry span per JSON-RPC request to this OTLP/HTTP collector (host:port, e.g. localhost:4318)")
+	otlpInsecure := flag.Bool("otlp_insecure", false, "Export spans to -otlp_endpoint over plain HTTP instead of TLS")
+	otlpService := flag.String("otlp_service", "", "service.name reported on exported spans (default: mcpengine)")
+	metrics := flag.Bool("metrics", false, "Track per-method latency and error-rate stats, exposed via -health_addr's /status and a periodic summary log line")
+	metricsInterval := flag.Duration("metrics_interval", 0, "How often the metrics summary is logged (default: 1m)")
+	dashboardAddr := flag.String("dashboard_addr", "", "Serve a localhost debug dashboard (live traffic, auth state, pause/step) on this address, e.g. 127.0.0.1:8183; empty disables")
+	headers := headerFlags{}
+	flag.Var(headers, "header", "Extra HTTP header as \"Name: Value\"; may be repeated, values are environment-expanded")
+	sseHeaders := headerFlags{}
+	flag.Var(sseHeaders, "sse_header", "Extra header on the SSE subscription only, as \"Name: Value\"; may be repeated, values are environment-expanded")
+	sseQuery := paramFlags{}
+	flag.Var(sseQuery, "sse_query", "Query parameter on the SSE subscription URL as \"name=value\" (e.g. an api key or tenant id); may be repeated, values are environment-expanded")
+	var allowEndpointHosts listFlags
+	flag.Var(&allowEndpointHosts, "allow_endpoint_host", "Extra host (\"host\" or \"host:port\") an absolute SSE endpoint URL may point at; may be repeated")
+	var dockerEnv, dockerMounts listFlags
+	flag.Var(&dockerEnv, "docker_env", "KEY=VALUE set inside the -docker_image container; may be repeated, values are environment-expanded")
+	flag.Var(&dockerMounts, "docker_mount", "Volume for the -docker_image container as \"host:container[:ro]\"; may be repeated")
 	flag.Parse()
 
-	if *mode != "sse" && *mode != "http" {
-		fmt.Printf("Invalid mode: %s. Must be one of \"sse\", \"http\"\n", *mode)
+	if *configPath != "" {
+		if err := applyConfigFile(*configPath); err != nil {
+			fmt.Printf("Failed to load config file: %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if *serverName != "" {
+		if err := applyServerDefinition(*serverName, *profile); err != nil {
+			fmt.Printf("Failed to load server definition: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	switch *mode {
+	case "sse", "http", "streamable", "auto", "stdio":
+	default:
+		fmt.Printf("Invalid mode: %s. Must be one of \"sse\", \"http\", \"streamable\", \"auto\", \"stdio\"\n", *mode)
 		os.Exit(1)
 	}
 
-	var rawLogger *zap.Logger
+	stderrLevel := zapcore.InfoLevel
 	if *debug {
-		l, err := zap.NewDevelopment()
-		if err != nil {
-			fmt.Printf("Failed to setup logger: %s\n", err)
-			os.Exit(1)
+		stderrLevel = zapcore.DebugLevel
+	}
+	fileLevel, err := zapcore.ParseLevel(*logFileLevel)
+	if err != nil {
+		fmt.Printf("Invalid log_file_level: %s\n", err)
+		os.Exit(1)
+	}
+	logger, flushLogs, err := mcpengine.NewLogger(mcpengine.LogConfig{
+		StderrLevel:    stderrLevel,
+		FilePath:       *logFile,
+		FileLevel:      fileLevel,
+		FileMaxSizeMB:  *logFileMaxSize,
+		FileMaxBackups: *logFileMaxBackups,
+		FileMaxAgeDays: *logFileMaxAge,
+	})
+	if err != nil {
+		fmt.Printf("Failed to setup logger: %s\n", err)
+		os.Exit(1)
+	}
+	defer flushLogs()
+	// The engine takes the Logger interface; cmd keeps the zap logger for
+	// Fatal exits.
+	engineLogger := mcpengine.NewZapLogger(logger)
+	if *redact {
+		engineLogger = mcpengine.NewRedactingLogger(engineLogger,
+			mcpengine.NewRedactor(splitScopes(*redactFields)...))
+	}
+
+	if *serveAddr != "" {
+		if *execCommand == "" {
+			logger.Fatal("-serve requires -exec to name the stdio server to expose")
 		}
-		rawLogger = l
-	} else {
-		l, err := zap.NewProduction()
+		reverse, err := mcpengine.NewReverseServer(mcpengine.ReverseConfig{
+			Addr:           *serveAddr,
+			Command:        strings.Fields(*execCommand),
+			SSEPath:        *ssePath,
+			MCPPath:        *mcpPath,
+			BearerToken:    os.ExpandEnv(*serveBearerToken),
+			MaxMessageSize: *maxMessageSize,
+		}, engineLogger)
 		if err != nil {
-			fmt.Printf("Failed to setup logger: %s\n", err)
-			os.Exit(1)
+			logger.Fatalw("Invalid reverse mode configuration", "err", err)
 		}
-		rawLogger = l
+		logger.Info("Starting reverse mode")
+		if err := reverse.Run(context.Background()); err != nil && err != context.Canceled {
+			logger.Fatalw("Reverse mode exited with error", "err", err)
+		}
+		return
 	}
-	logger := rawLogger.Sugar()
 
-	if *host == "" {
+	if *host == "" && *execCommand == "" {
 		logger.Fatal("-host flag must be set")
 	}
-	engine, err := mcpengine.New(mcpengine.Config{
-		Endpoint: *host,
-		UseSSE:   *mode == "sse",
-		SSEPath:  *ssePath,
-		MCPPath:  *mcpPath,
+	transport := mcpengine.TransportMode(*mode)
+	if *mode == "streamable" {
+		transport = mcpengine.TransportStreamableHTTP
+	}
+	if *dockerImage != "" {
+		transport = mcpengine.TransportDocker
+	} else if *grpcTarget != "" {
+		transport = mcpengine.TransportGRPC
+	} else if strings.HasPrefix(*host, "npipe://") {
+		transport = mcpengine.TransportPipe
+	} else if strings.HasPrefix(*host, "tcp://") || strings.HasPrefix(*host, "tcps://") {
+		transport = mcpengine.TransportTCP
+	} else if *execCommand != "" {
+		transport = mcpengine.TransportStdio
+	} else if *mode == "stdio" {
+		logger.Fatal("-mode stdio requires -exec")
+	}
+	minTLSVersion, err := mcpengine.ParseTLSVersion(*tlsMinVersion)
+	if err != nil {
+		logger.Fatalw("Invalid tls_min_version", "err", err)
+	}
+	var tlsConfig *mcpengine.TLSClientConfig
+	if *tlsCAFile != "" || *tlsCertFile != "" || *tlsKeyFile != "" || minTLSVersion != 0 || *tlsInsecure {
+		tlsConfig = &mcpengine.TLSClientConfig{
+			CAFile:             *tlsCAFile,
+			CertFile:           *tlsCertFile,
+			KeyFile:            *tlsKeyFile,
+			MinVersion:         minTLSVersion,
+			InsecureSkipVerify: *tlsInsecure,
+		}
+	}
+	if *mode == "auto" && *replayFile == "" {
+		probeClient, err := mcpengine.NewHTTPClient(*proxyURL, tlsConfig)
+		if err != nil {
+			logger.Fatalw("Failed to build HTTP client", "err", err)
+		}
+		detectCtx, detectCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		transport = mcpengine.DetectTransport(detectCtx, probeClient, *host, *mcpPath, engineLogger)
+		detectCancel()
+		logger.Infow("Auto-detected transport", "transport", transport)
+	}
+	listenPort := *authListenPort
+	if listenPort == 0 {
+		listenPort = mcpengine.EphemeralPort
+	}
+	var tokenStore mcpengine.TokenStore
+	var clientStore mcpengine.ClientStore
+	if *useKeychain {
+		tokenStore = mcpengine.NewKeychainTokenStore(*host)
+		clientStore = mcpengine.NewKeychainClientStore(*host)
+	}
+	var rateLimitCfg *mcpengine.RateLimitConfig
+	if *rateLimit > 0 || len(rateLimitMethods) > 0 {
+		rateLimitCfg = &mcpengine.RateLimitConfig{
+			RequestsPerSecond: *rateLimit,
+			Burst:             *rateLimitBurst,
+			OnLimit:           mcpengine.RateLimitAction(*rateLimitPolicy),
+		}
+		for _, spec := range rateLimitMethods {
+			method, limit, err := parseMethodRateLimit(spec)
+			if err != nil {
+				logger.Fatalw("Invalid -rate_limit_method", "value", spec, "err", err)
+			}
+			if rateLimitCfg.PerMethod == nil {
+				rateLimitCfg.PerMethod = make(map[string]mcpengine.MethodRateLimit)
+			}
+			rateLimitCfg.PerMethod[method] = limit
+		}
+	}
+	var transforms []mcpengine.TransformRule
+	if *transformFile != "" {
+		var err error
+		if transforms, err = loadTransforms(*transformFile); err != nil {
+			logger.Fatalw("Failed to load -transform_file", "path", *transformFile, "err", err)
+		}
+	}
+	var httpTuning *mcpengine.HTTPTuning
+	if *dialTimeout != 0 || *tcpKeepAlive != 0 || *tlsHandshakeTimeout != 0 ||
+		*responseHeaderTimeout != 0 || *idleConnTimeout != 0 ||
+		*maxIdleConnsPerHost != 0 || *maxConnsPerHost != 0 {
+		httpTuning = &mcpengine.HTTPTuning{
+			DialTimeout:           *dialTimeout,
+			KeepAlive:             *tcpKeepAlive,
+			TLSHandshakeTimeout:   *tlsHandshakeTimeout,
+			ResponseHeaderTimeout: *responseHeaderTimeout,
+			IdleConnTimeout:       *idleConnTimeout,
+			MaxIdleConnsPerHost:   *maxIdleConnsPerHost,
+			MaxConnsPerHost:       *maxConnsPerHost,
+		}
+	}
+	cfg := mcpengine.Config{
+		Endpoint:  *host,
+		Transport: transport,
 		AuthConfig: &mcpengine.AuthConfig{
-			ClientID:     *clientId,
-			ClientSecret: *clientSecret,
-			ListenPort:   *authListenPort,
+			ClientID:             *clientId,
+			ClientSecret:         *clientSecret,
+			Audience:             *audience,
+			Resource:             *resource,
+			ClientCredentials:    *clientCredentials,
+			Scopes:               splitScopes(*scopes),
+			ListenPort:           listenPort,
+			TokenStore:           tokenStore,
+			ClientStore:          clientStore,
+			DisableBrowser:       *noBrowser,
+			CallbackTemplateFile: *callbackTemplate,
+			CallbackAutoClose:    *callbackAutoClose,
+			RevokeOnShutdown:     *revokeOnExit,
 		},
-		Logger: logger,
-	})
+		Logger:               engineLogger,
+		StartupTimeout:       *startupTimeout,
+		RequestTimeout:       *requestTimeout,
+		TrackOrphans:         *trackOrphans,
+		OrphanWarnAfter:      *orphanWarnAfter,
+		KeepaliveInterval:    *keepaliveInterval,
+		SSEStallTimeout:      *sseStallTimeout,
+		AllowedEndpointHosts: allowEndpointHosts,
+		KeepaliveTimeout:     *keepaliveTimeout,
+		MaxMessageSize:       *maxMessageSize,
+		Compress:             *compress,
+		MaxRequestSize:       *maxRequestSize,
+		MaxResponseSize:      *maxResponseSize,
+		MaxContentSize:       *maxContentSize,
+		ContentSpillDir:      *contentSpillDir,
+		FollowInput:          *follow,
+		ShareSocket:          *shareSocket,
+		NotifyClient:         *notifyClient,
+		Headers:              headers,
+		SSEHeaders:           sseHeaders,
+		SSEQueryParams:       sseQuery,
+		ProxyURL:             *proxyURL,
+		TLS:                  tlsConfig,
+		HealthAddr:           *healthAddr,
+		HTTP2:                mcpengine.HTTP2Mode(*http2Mode),
+		Metrics:              *metrics,
+		MetricsInterval:      *metricsInterval,
+		DashboardAddr:        *dashboardAddr,
+		DeadLetterFile:       *deadLetterFile,
+		OfflineBufferSize:    *offlineBuffer,
+		OfflineSpillFile:     *offlineSpillFile,
+		ParkOnAuth:           *parkOnAuth,
+		SessionFile:          *sessionFile,
+		SessionMaxAge:        *sessionMaxAge,
+		ChannelBufferSize:    *channelBuffer,
+		InputOverflowPolicy:  mcpengine.OverflowPolicy(*inputOverflow),
+		OutputOverflowPolicy: mcpengine.OverflowPolicy(*outputOverflow),
+		PostConcurrency:      *postConcurrency,
+		PostOrdering:         mcpengine.PostOrdering(*postOrdering),
+		HTTPTuning:           httpTuning,
+		RateLimit:            rateLimitCfg,
+		Transforms:           transforms,
+	}
+	staticToken := os.ExpandEnv(*bearerToken)
+	if *bearerTokenFile != "" {
+		if staticToken != "" {
+			logger.Fatal("-bearer_token and -bearer_token_file are mutually exclusive")
+		}
+		staticToken, err = mcpengine.ReadTokenFile(*bearerTokenFile)
+		if err != nil {
+			logger.Fatalw("Failed to load bearer token", "err", err)
+		}
+	}
+	if staticToken != "" {
+		cfg.Authenticator = mcpengine.NewStaticTokenAuthenticator(staticToken, engineLogger.With("svc", "auth"))
+	}
+	if *credentialHelper != "" {
+		if staticToken != "" {
+			logger.Fatal("-credential_helper and -bearer_token are mutually exclusive")
+		}
+		parts := strings.Fields(*credentialHelper)
+		helperAuth, err := mcpengine.NewCredentialHelperAuthenticator(mcpengine.CredentialHelperConfig{
+			Command: parts[0],
+			Args:    parts[1:],
+			TTL:     *credentialHelperTTL,
+		}, engineLogger.With("svc", "auth"))
+		if err != nil {
+			logger.Fatalw("Invalid credential helper configuration", "err", err)
+		}
+		cfg.Authenticator = helperAuth
+	}
+	if key := os.ExpandEnv(*apiKey); key != "" {
+		if staticToken != "" {
+			logger.Fatal("-api_key and -bearer_token are mutually exclusive")
+		}
+		if *credentialHelper != "" {
+			logger.Fatal("-api_key and -credential_helper are mutually exclusive")
+		}
+		keyCfg := mcpengine.APIKeyConfig{Key: key}
+		if *apiKeyQuery != "" {
+			keyCfg.QueryParam = *apiKeyQuery
+		} else {
+			keyCfg.Header = *apiKeyHeader
+		}
+		keyAuth, err := mcpengine.NewAPIKeyAuthenticator(keyCfg, engineLogger.With("svc", "auth"))
+		if err != nil {
+			logger.Fatalw("Invalid API key configuration", "err", err)
+		}
+		cfg.Authenticator = keyAuth
+	}
+	switch transport {
+	case mcpengine.TransportDocker:
+		cfg.Endpoint = ""
+		cfg.Docker = &mcpengine.DockerConfig{
+			Image:   *dockerImage,
+			Command: strings.Fields(*execCommand),
+			Env:     expandList(dockerEnv),
+			Mounts:  dockerMounts,
+			Network: *dockerNetwork,
+			Binary:  *dockerBinary,
+		}
+	case mcpengine.TransportStdio:
+		cfg.Endpoint = ""
+		cfg.Command = strings.Fields(*execCommand)
+	case mcpengine.TransportGRPC:
+		cfg.Endpoint = ""
+		cfg.GRPC = &mcpengine.GRPCConfig{
+			Target:    *grpcTarget,
+			Method:    *grpcMethod,
+			PlainText: *grpcPlainText,
+		}
+	case mcpengine.TransportPipe, mcpengine.TransportTCP:
+		// The endpoint scheme carries everything; no paths apply.
+	case mcpengine.TransportSSE:
+		cfg.SSEPath = *ssePath
+	default:
+		cfg.MCPPath = *mcpPath
+	}
+	if *kubeTarget != "" {
+		cfg.Endpoint = ""
+		cfg.Kube = &mcpengine.KubeForwardConfig{
+			Target:    *kubeTarget,
+			Port:      *kubePort,
+			LocalPort: *kubeLocalPort,
+			Context:   *kubeContext,
+			Namespace: *kubeNamespace,
+			Binary:    *kubeBinary,
+		}
+	}
+	if *otlpEndpoint != "" {
+		cfg.Tracing = &mcpengine.TracingConfig{
+			OTLPEndpoint: *otlpEndpoint,
+			Insecure:     *otlpInsecure,
+			ServiceName:  *otlpService,
+		}
+	}
+	if *traceFile != "" {
+		trace, err := os.Create(*traceFile)
+		if err != nil {
+			fmt.Printf("Failed to open trace file: %s\n", err)
+			os.Exit(1)
+		}
+		defer trace.Close()
+		cfg.TraceWriter = trace
+	}
+	if *replayFile != "" {
+		recording, err := os.Open(*replayFile)
+		if err != nil {
+			fmt.Printf("Failed to open replay file: %s\n", err)
+			os.Exit(1)
+		}
+		defer recording.Close()
+		cfg.ReplayFrom = recording
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Printf("Invalid configuration: %s\n", err)
+		os.Exit(1)
+	}
+	if *dryRun || validateVerb {
+		// Everything is parsed, env-expanded, defaulted, and validated;
+		// report the resolved essentials and stop before any worker runs.
+		fmt.Println("Configuration OK")
+		fmt.Printf("  transport: %s\n", transport)
+		switch transport {
+		case mcpengine.TransportStdio:
+			fmt.Printf("  command:   %s\n", strings.Join(cfg.Command, " "))
+		case mcpengine.TransportDocker:
+			fmt.Printf("  image:     %s\n", cfg.Docker.Image)
+		case mcpengine.TransportGRPC:
+			fmt.Printf("  target:    %s\n", cfg.GRPC.Target)
+		default:
+			fmt.Printf("  endpoint:  %s%s%s\n", cfg.Endpoint, cfg.SSEPath, cfg.MCPPath)
+		}
+		if cfg.Authenticator != nil {
+			fmt.Println("  auth:      static credential")
+		} else {
+			fmt.Println("  auth:      oauth")
+		}
+		return
+	}
+	engine, err := mcpengine.New(cfg)
 	if err != nil {
 		logger.Fatalw("Failed to create MCPEngine", "err", err)
 	}
 	logger.Info("Starting MCPEngine")
-	engine.Start(context.Background())
+	if err := engine.Start(context.Background()); err != nil {
+		logger.Fatalw("MCPEngine exited with error", "err", err)
+	}
 }
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"mcpengine"
+)
+
+// wizard wraps the prompt I/O so the flow reads top to bottom.
+type wizard struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// ask prompts for one value, returning fallback on an empty answer.
+func (w *wizard) ask(label, fallback string) string {
+	if fallback != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", label, fallback)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", label)
+	}
+	if !w.in.Scan() {
+		return fallback
+	}
+	answer := strings.TrimSpace(w.in.Text())
+	if answer == "" {
+		return fallback
+	}
+	return answer
+}
+
+// askChoice prompts until the answer is one of the choices; the first
+// choice is the default.
+func (w *wizard) askChoice(label string, choices []string) string {
+	for {
+		answer := w.ask(fmt.Sprintf("%s (%s)", label, strings.Join(choices, "/")), choices[0])
+		for _, choice := range choices {
+			if answer == choice {
+				return answer
+			}
+		}
+		fmt.Fprintf(w.out, "Please answer one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+// confirm asks a yes/no question, defaulting to no.
+func (w *wizard) confirm(label string) bool {
+	answer := w.ask(label+" (y/N)", "n")
+	return strings.EqualFold(answer, "y") || strings.EqualFold(answer, "yes")
+}
+
+// runInit interactively builds one server definition: endpoint, transport,
+// auth, a connection test, and finally the servers file (plus an optional
+// Claude Desktop registration).
+func runInit(args []string) int {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	resolvePath := serversPathFlag(flags)
+	flags.Parse(args)
+
+	w := &wizard{in: bufio.NewScanner(os.Stdin), out: os.Stdout}
+	fmt.Fprintln(w.out, "This wizard defines a named MCP server for the proxy.")
+
+	name := w.ask("Server name", "")
+	if name == "" {
+		fmt.Fprintln(os.Stderr, "A name is required")
+		return 1
+	}
+
+	opts := mcpengine.ServerOptions{}
+	transport := w.askChoice("Transport", []string{"sse", "streamable", "http", "stdio"})
+	if transport == "stdio" {
+		opts["exec"] = w.ask("Server command (with arguments)", "")
+		if opts["exec"] == "" {
+			fmt.Fprintln(os.Stderr, "The stdio transport needs a command")
+			return 1
+		}
+	} else {
+		opts["mode"] = transport
+		opts["host"] = w.ask("Endpoint URL", "http://localhost:8000")
+		if transport == "sse" {
+			if path := w.ask("SSE path", "/sse"); path != "/sse" {
+				opts["sse_path"] = path
+			}
+		} else {
+			if path := w.ask("MCP path", "/mcp"); path != "/mcp" {
+				opts["mcp_path"] = path
+			}
+		}
+	}
+
+	auth := w.askChoice("Authentication", []string{"oauth", "bearer", "api-key", "none"})
+	switch auth {
+	case "oauth":
+		if clientID := w.ask("OAuth client id (empty registers one dynamically)", ""); clientID != "" {
+			opts["client_id"] = clientID
+		}
+		if scopes := w.ask("OAuth scopes, comma-separated (empty uses the server's)", ""); scopes != "" {
+			opts["scopes"] = scopes
+		}
+		if w.confirm("Persist tokens to the OS keychain?") {
+			opts["keychain"] = "true"
+		}
+	case "bearer":
+		// Store the environment reference, not the secret itself; the
+		// value is expanded at startup.
+		opts["bearer_token"] = w.ask("Bearer token (a ${VAR} reference keeps it out of the file)", "")
+	case "api-key":
+		opts["api_key"] = w.ask("API key (a ${VAR} reference keeps it out of the file)", "")
+	}
+
+	if opts["host"] != "" && w.confirm("Test the connection now?") {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		checks := mcpengine.RunDoctor(ctx, doctorConfigFor(opts, ""))
+		cancel()
+		for _, check := range checks {
+			if check.Name != "endpoint" && check.Name != "oidc-discovery" {
+				continue
+			}
+			fmt.Fprintf(w.out, "%-4s %-14s %s\n", strings.ToUpper(string(check.Status)), check.Name, check.Detail)
+			if check.Remedy != "" {
+				fmt.Fprintf(w.out, "     %-14s -> %s\n", "", check.Remedy)
+			}
+			if check.Status == mcpengine.DoctorFail && !w.confirm("The check failed; save anyway?") {
+				return 1
+			}
+		}
+	}
+
+	path, err := resolvePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to locate servers file: %s\n", err)
+		return 1
+	}
+	servers, err := mcpengine.LoadServers(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load servers: %s\n", err)
+		return 1
+	}
+	if _, exists := servers[name]; exists && !w.confirm(fmt.Sprintf("%q already exists; overwrite?", name)) {
+		return 1
+	}
+	servers[name] = opts
+	if err := mcpengine.SaveServers(path, servers); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to save: %s\n", err)
+		return 1
+	}
+	fmt.Fprintf(w.out, "Saved %s to %s\n", name, path)
+	fmt.Fprintf(w.out, "Run it with: mcpengine -server %s\n", name)
+
+	if w.confirm("Register it with Claude Desktop?") {
+		claudePath, err := mcpengine.ClaudeConfigPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to locate Claude Desktop config: %s\n", err)
+			return 1
+		}
+		if err := mcpengine.AddToClaudeConfig(claudePath, name, mcpengine.ClaudeSnippet(name)); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to edit Claude Desktop config: %s\n", err)
+			return 1
+		}
+		fmt.Fprintf(w.out, "Registered %s in %s; restart Claude Desktop to pick it up\n", name, claudePath)
+	}
+	return 0
+}
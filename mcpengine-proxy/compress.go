@@ -0,0 +1,53 @@
+package mcpengine
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// gzipBody compresses a message for use as a POST body. The whole message
+// is compressed up front so Content-Length stays known and retries can
+// re-read the body.
+func gzipBody(msg string) (*bytes.Reader, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(msg)); err != nil {
+		return nil, fmt.Errorf("compressing request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compressing request body: %w", err)
+	}
+	return bytes.NewReader(buf.Bytes()), nil
+}
+
+// gzipReadCloser pairs a gzip reader with the response body underneath it,
+// so closing one closes both.
+type gzipReadCloser struct {
+	*gzip.Reader
+	underlying io.Closer
+}
+
+func (grc *gzipReadCloser) Close() error {
+	err := grc.Reader.Close()
+	if underlyingErr := grc.underlying.Close(); err == nil {
+		err = underlyingErr
+	}
+	return err
+}
+
+// responseBody returns the response body, transparently decompressed when
+// the server sent it gzipped. The transport only decompresses encodings it
+// negotiated itself, so servers that compress unprompted are handled here.
+func responseBody(resp *http.Response) (io.ReadCloser, error) {
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		return resp.Body, nil
+	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing response body: %w", err)
+	}
+	return &gzipReadCloser{Reader: zr, underlying: resp.Body}, nil
+}
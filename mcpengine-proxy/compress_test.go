@@ -0,0 +1,120 @@
+package mcpengine
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Compression Tests =====
+
+func TestGzipBody_RoundTrips(t *testing.T) {
+	msg := `{"jsonrpc": "2.0", "id": 1, "method": "tools/call", "params": {"text": "` +
+		strings.Repeat("compress me ", 100) + `"}}`
+	body, err := gzipBody(msg)
+	if err != nil {
+		t.Fatalf("gzipBody returned error: %v", err)
+	}
+	if int(body.Size()) >= len(msg) {
+		t.Errorf("Expected the compressed body smaller than %d bytes, got %d", len(msg), body.Size())
+	}
+	zr, err := gzip.NewReader(body)
+	if err != nil {
+		t.Fatalf("Compressed body is not valid gzip: %v", err)
+	}
+	restored, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("Failed to decompress: %v", err)
+	}
+	if string(restored) != msg {
+		t.Error("Round-tripped body does not match the original message")
+	}
+}
+
+func TestHTTPPostSender_CompressesRequests(t *testing.T) {
+	received := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Encoding") != "gzip" {
+			t.Errorf("Expected Content-Encoding gzip, got %q", r.Header.Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Errorf("Body is not valid gzip: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, _ := io.ReadAll(zr)
+		received <- string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	endpointChan := make(chan string, 1)
+	endpointChan <- "/messages"
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(ts.Client(), ts.URL, endpointChan, inputChan, outputChan,
+		NewAuthManager(nil, logger), logger)
+	sender.SetCompress(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	msg := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	inputChan <- msg
+	select {
+	case body := <-received:
+		if body != msg {
+			t.Errorf("Expected the decompressed body %q, got %q", msg, body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Request never reached the server")
+	}
+}
+
+func TestHTTPPostSender_DecompressesResponses(t *testing.T) {
+	response := `{"jsonrpc": "2.0", "id": 1, "result": {}}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Compress unprompted, like a misbehaving gateway; the sender must
+		// still hand plain JSON to the client.
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(response))
+		zw.Close()
+	}))
+	defer ts.Close()
+
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	endpointChan := make(chan string, 1)
+	endpointChan <- "/messages"
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(ts.Client(), ts.URL, endpointChan, inputChan, outputChan,
+		NewAuthManager(nil, logger), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	select {
+	case got := <-outputChan:
+		if got != response {
+			t.Errorf("Expected the decompressed response %q, got %q", response, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Response never reached the output channel")
+	}
+}
@@ -0,0 +1,137 @@
+package mcpengine
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// contentGuard shields stdio clients from multi-megabyte result lines:
+// content items whose payload exceeds the configured size (giant base64
+// images, huge text blobs) are written to a local file and replaced in the
+// message with a small placeholder naming that file. It runs as inbound
+// middleware on responses only; requests are the client's own doing.
+type contentGuard struct {
+	limit    int
+	spillDir string
+	logger   Logger
+}
+
+// newContentGuard constructs a guard with the given per-item size limit.
+// An empty spillDir falls back to the system temp directory.
+func newContentGuard(limit int, spillDir string, logger Logger) *contentGuard {
+	if spillDir == "" {
+		spillDir = os.TempDir()
+	}
+	return &contentGuard{
+		limit:    limit,
+		spillDir: spillDir,
+		logger:   logger,
+	}
+}
+
+// inbound replaces oversized content items in a response's result.content
+// array. Messages without one, or too small to matter, pass through
+// byte-for-byte.
+func (cg *contentGuard) inbound(msg string) (string, error) {
+	// A message under the limit cannot contain an oversized item.
+	if len(msg) <= cg.limit || getMessageMethod(msg) != "" {
+		return msg, nil
+	}
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(msg), &envelope); err != nil {
+		return msg, nil
+	}
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(envelope["result"], &result); err != nil {
+		return msg, nil
+	}
+	var content []json.RawMessage
+	if err := json.Unmarshal(result["content"], &content); err != nil {
+		return msg, nil
+	}
+
+	guarded := false
+	for i, item := range content {
+		if len(item) <= cg.limit {
+			continue
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(item, &fields); err != nil {
+			continue
+		}
+		itemType, _ := fields["type"].(string)
+		payload, field := "", ""
+		for _, candidate := range []string{"data", "text", "blob"} {
+			if value, ok := fields[candidate].(string); ok && len(value) > cg.limit {
+				payload, field = value, candidate
+				break
+			}
+		}
+		if field == "" {
+			continue
+		}
+		content[i] = cg.replace(itemType, field, payload)
+		guarded = true
+	}
+	if !guarded {
+		return msg, nil
+	}
+
+	rewrittenContent, err := json.Marshal(content)
+	if err != nil {
+		cg.logger.Errorw("Failed to rebuild guarded content", "err", err)
+		return msg, nil
+	}
+	result["content"] = rewrittenContent
+	rewrittenResult, err := json.Marshal(result)
+	if err != nil {
+		cg.logger.Errorw("Failed to rebuild guarded result", "err", err)
+		return msg, nil
+	}
+	envelope["result"] = rewrittenResult
+	rewritten, err := json.Marshal(envelope)
+	if err != nil {
+		cg.logger.Errorw("Failed to rebuild guarded message", "err", err)
+		return msg, nil
+	}
+	return string(rewritten), nil
+}
+
+// replace spills one oversized payload to a file and returns the text item
+// standing in for it. Base64 payloads are decoded first so the file is
+// directly usable; when spilling fails the content is stripped and the
+// placeholder says so.
+func (cg *contentGuard) replace(itemType, field, payload string) json.RawMessage {
+	data := []byte(payload)
+	if field != "text" {
+		if decoded, err := base64.StdEncoding.DecodeString(payload); err == nil {
+			data = decoded
+		}
+	}
+
+	placeholder := ""
+	file, err := os.CreateTemp(cg.spillDir, "mcp-content-*.dat")
+	if err == nil {
+		_, err = file.Write(data)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		cg.logger.Errorw("Failed to spill oversized content", "err", err)
+		placeholder = fmt.Sprintf("[%s content (%d bytes) exceeded the %d-byte limit and could not be saved]",
+			itemType, len(payload), cg.limit)
+	} else {
+		cg.logger.Infow("Spilled oversized content item",
+			"type", itemType, "bytes", len(payload), "path", file.Name())
+		placeholder = fmt.Sprintf("[%s content (%d bytes) exceeded the %d-byte limit; saved to %s]",
+			itemType, len(payload), cg.limit, file.Name())
+	}
+	item, marshalErr := json.Marshal(map[string]string{"type": "text", "text": placeholder})
+	if marshalErr != nil {
+		return json.RawMessage(`{"type":"text","text":"[oversized content removed]"}`)
+	}
+	return item
+}
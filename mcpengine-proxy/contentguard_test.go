@@ -0,0 +1,113 @@
+package mcpengine
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// ===== Content Guard Tests =====
+
+func TestContentGuard_SpillsOversizedItems(t *testing.T) {
+	dir := t.TempDir()
+	guard := newContentGuard(64, dir, NewZapLogger(zap.NewNop().Sugar()))
+
+	image := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("pixels", 100)))
+	msg, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"result": map[string]interface{}{
+			"content": []interface{}{
+				map[string]string{"type": "text", "text": "small"},
+				map[string]string{"type": "image", "data": image, "mimeType": "image/png"},
+			},
+		},
+	})
+
+	out, err := guard.inbound(string(msg))
+	if err != nil {
+		t.Fatalf("inbound returned error: %v", err)
+	}
+	var data struct {
+		Result struct {
+			Content []map[string]string `json:"content"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal([]byte(out), &data); err != nil {
+		t.Fatalf("Failed to parse guarded message: %v", err)
+	}
+	if len(data.Result.Content) != 2 {
+		t.Fatalf("Expected both content items kept, got %d", len(data.Result.Content))
+	}
+	if data.Result.Content[0]["text"] != "small" {
+		t.Errorf("Expected the small item untouched, got %v", data.Result.Content[0])
+	}
+	placeholder := data.Result.Content[1]
+	if placeholder["type"] != "text" || !strings.Contains(placeholder["text"], "image content") {
+		t.Fatalf("Expected a placeholder for the image, got %v", placeholder)
+	}
+	if _, ok := placeholder["data"]; ok {
+		t.Error("Expected the base64 payload stripped")
+	}
+
+	// The placeholder names a file holding the decoded payload.
+	parts := strings.Split(placeholder["text"], "saved to ")
+	if len(parts) != 2 {
+		t.Fatalf("Expected the placeholder to name the spill file, got %q", placeholder["text"])
+	}
+	path := strings.TrimSuffix(parts[1], "]")
+	spilled, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read spill file: %v", err)
+	}
+	if string(spilled) != strings.Repeat("pixels", 100) {
+		t.Error("Expected the spill file to hold the decoded payload")
+	}
+}
+
+func TestContentGuard_LeavesSmallMessagesUntouched(t *testing.T) {
+	guard := newContentGuard(1024, t.TempDir(), NewZapLogger(zap.NewNop().Sugar()))
+
+	original := `{"jsonrpc": "2.0", "id": 2, "result": {"content": [{"type": "text", "text": "hi"}]}}`
+	if out, err := guard.inbound(original); err != nil || out != original {
+		t.Errorf("Expected the message byte-for-byte, got %q (%v)", out, err)
+	}
+
+	// Requests and notifications are never touched, however large.
+	request := `{"jsonrpc": "2.0", "id": 3, "method": "tools/call", "params": {"blob": "` +
+		strings.Repeat("x", 2048) + `"}}`
+	if out, err := guard.inbound(request); err != nil || out != request {
+		t.Error("Expected the request untouched")
+	}
+}
+
+func TestContentGuard_ConfigValidation(t *testing.T) {
+	base := Config{
+		Endpoint: "http://localhost:8080",
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+	}
+
+	cfg := base
+	cfg.MaxContentSize = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative MaxContentSize rejected")
+	}
+
+	cfg = base
+	cfg.ContentSpillDir = "/tmp"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected ContentSpillDir without MaxContentSize rejected")
+	}
+
+	cfg = base
+	cfg.MaxContentSize = 1 << 16
+	cfg.ContentSpillDir = "/tmp"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid guard config, got %v", err)
+	}
+}
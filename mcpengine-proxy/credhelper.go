@@ -0,0 +1,105 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CredentialHelperConfig configures a CredentialHelperAuthenticator.
+type CredentialHelperConfig struct {
+	// Command is the helper executable to run. It must print a token on
+	// stdout, in the style of git and docker credential helpers, letting
+	// enterprises plug in existing SSO tooling without proxy changes.
+	Command string
+	// Args are passed to the command on each invocation.
+	Args []string
+	// TTL bounds how long a fetched token is cached before the helper is
+	// consulted again. Zero caches until a 401 forces a refresh.
+	TTL time.Duration
+}
+
+// CredentialHelperAuthenticator implements Authenticator by executing an
+// external command that prints a token on stdout. Tokens are cached between
+// requests; a 401 from the server re-runs the helper, so helpers that mint
+// short-lived credentials keep working without the proxy knowing their
+// expiry.
+type CredentialHelperAuthenticator struct {
+	cfg    CredentialHelperConfig
+	logger Logger
+
+	mu        sync.Mutex
+	token     string
+	fetchedAt time.Time
+}
+
+// NewCredentialHelperAuthenticator constructs a
+// CredentialHelperAuthenticator, validating that a command is configured.
+func NewCredentialHelperAuthenticator(cfg CredentialHelperConfig, logger Logger) (*CredentialHelperAuthenticator, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("credential helper: Command must be set")
+	}
+	return &CredentialHelperAuthenticator{
+		cfg:    cfg,
+		logger: logger,
+	}, nil
+}
+
+// HandleChallenge implements Authenticator. A 401 means the cached token
+// went stale, so the helper is consulted again; no user interaction is
+// needed.
+func (c *CredentialHelperAuthenticator) HandleChallenge(ctx context.Context, resp *http.Response) (string, func(), error) {
+	c.logger.Debugw("Server rejected the helper-supplied token; re-running the helper", "status", resp.StatusCode)
+	if err := c.Refresh(ctx); err != nil {
+		return "", nil, err
+	}
+	return "", func() {}, nil
+}
+
+// Token implements Authenticator, returning the cached token and running
+// the helper when the cache is empty or past its TTL. Helper failures are
+// logged and yield an empty token so the request proceeds unauthenticated
+// and surfaces the server's error.
+func (c *CredentialHelperAuthenticator) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && (c.cfg.TTL == 0 || time.Since(c.fetchedAt) < c.cfg.TTL) {
+		return c.token
+	}
+	if err := c.runHelper(context.Background()); err != nil {
+		c.logger.Warnf("Credential helper failed: %v", err)
+	}
+	return c.token
+}
+
+// Refresh implements Authenticator by discarding the cache and re-running
+// the helper.
+func (c *CredentialHelperAuthenticator) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = ""
+	return c.runHelper(ctx)
+}
+
+// runHelper executes the configured command and caches its stdout as the
+// token. Callers must hold c.mu.
+func (c *CredentialHelperAuthenticator) runHelper(ctx context.Context) error {
+	out, err := exec.CommandContext(ctx, c.cfg.Command, c.cfg.Args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return fmt.Errorf("helper %s failed: %w: %s", c.cfg.Command, err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return fmt.Errorf("helper %s failed: %w", c.cfg.Command, err)
+	}
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return fmt.Errorf("helper %s printed no token", c.cfg.Command)
+	}
+	c.token = token
+	c.fetchedAt = time.Now()
+	return nil
+}
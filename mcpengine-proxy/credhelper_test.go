@@ -0,0 +1,159 @@
+package mcpengine
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Credential Helper Tests =====
+
+// writeHelperToken points the helper's token file at a new value.
+func writeHelperToken(t *testing.T, path, token string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write helper token: %v", err)
+	}
+}
+
+func TestNewCredentialHelperAuthenticator_Validation(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	if _, err := NewCredentialHelperAuthenticator(CredentialHelperConfig{}, logger); err == nil {
+		t.Error("Expected an error without a command")
+	}
+	if _, err := NewCredentialHelperAuthenticator(CredentialHelperConfig{Command: "true"}, logger); err != nil {
+		t.Errorf("Expected a valid config to pass, got %v", err)
+	}
+}
+
+func TestCredentialHelper_TokenCachesUntilRefresh(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeHelperToken(t, tokenFile, "helper-token-1")
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth, err := NewCredentialHelperAuthenticator(CredentialHelperConfig{
+		Command: "cat",
+		Args:    []string{tokenFile},
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	// Whitespace from the helper's output is trimmed.
+	if token := auth.Token(); token != "helper-token-1" {
+		t.Errorf("Expected helper-token-1, got %q", token)
+	}
+
+	// Without a TTL the first token is cached, even after the helper's
+	// answer changes.
+	writeHelperToken(t, tokenFile, "helper-token-2")
+	if token := auth.Token(); token != "helper-token-1" {
+		t.Errorf("Expected the cached token, got %q", token)
+	}
+
+	// Refresh discards the cache and re-runs the helper.
+	if err := auth.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if token := auth.Token(); token != "helper-token-2" {
+		t.Errorf("Expected the re-fetched token, got %q", token)
+	}
+}
+
+func TestCredentialHelper_TTLExpiry(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeHelperToken(t, tokenFile, "helper-token-1")
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth, err := NewCredentialHelperAuthenticator(CredentialHelperConfig{
+		Command: "cat",
+		Args:    []string{tokenFile},
+		TTL:     50 * time.Millisecond,
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+
+	if token := auth.Token(); token != "helper-token-1" {
+		t.Errorf("Expected helper-token-1, got %q", token)
+	}
+	writeHelperToken(t, tokenFile, "helper-token-2")
+	if token := auth.Token(); token != "helper-token-1" {
+		t.Errorf("Expected the cached token within the TTL, got %q", token)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if token := auth.Token(); token != "helper-token-2" {
+		t.Errorf("Expected a fresh token after the TTL, got %q", token)
+	}
+}
+
+func TestCredentialHelper_HandleChallengeRefreshes(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	writeHelperToken(t, tokenFile, "helper-token-1")
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth, err := NewCredentialHelperAuthenticator(CredentialHelperConfig{
+		Command: "cat",
+		Args:    []string{tokenFile},
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create authenticator: %v", err)
+	}
+	if token := auth.Token(); token != "helper-token-1" {
+		t.Fatalf("Expected helper-token-1, got %q", token)
+	}
+
+	// A 401 re-runs the helper with no user interaction.
+	writeHelperToken(t, tokenFile, "helper-token-2")
+	authURL, wait, err := auth.HandleChallenge(context.Background(), &http.Response{
+		StatusCode: http.StatusUnauthorized,
+	})
+	if err != nil {
+		t.Fatalf("HandleChallenge failed: %v", err)
+	}
+	if authURL != "" {
+		t.Errorf("Expected no auth URL, got %q", authURL)
+	}
+	wait()
+	if token := auth.Token(); token != "helper-token-2" {
+		t.Errorf("Expected the re-fetched token, got %q", token)
+	}
+}
+
+func TestCredentialHelper_Errors(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	t.Run("failing helper", func(t *testing.T) {
+		auth, err := NewCredentialHelperAuthenticator(CredentialHelperConfig{
+			Command: "false",
+		}, logger)
+		if err != nil {
+			t.Fatalf("Failed to create authenticator: %v", err)
+		}
+		if err := auth.Refresh(context.Background()); err == nil {
+			t.Error("Expected an error from a failing helper")
+		}
+		// Token degrades to empty so the server's error surfaces.
+		if token := auth.Token(); token != "" {
+			t.Errorf("Expected an empty token, got %q", token)
+		}
+	})
+
+	t.Run("empty output", func(t *testing.T) {
+		auth, err := NewCredentialHelperAuthenticator(CredentialHelperConfig{
+			Command: "true",
+		}, logger)
+		if err != nil {
+			t.Fatalf("Failed to create authenticator: %v", err)
+		}
+		if err := auth.Refresh(context.Background()); err == nil {
+			t.Error("Expected an error when the helper prints no token")
+		}
+	})
+}
@@ -0,0 +1,339 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DashboardAddr returns the bound address of the debug dashboard, or ""
+// when none is configured or it isn't up yet.
+func (mcp *MCPEngine) DashboardAddr() string {
+	mcp.statusMu.Lock()
+	dashboard := mcp.dashboard
+	mcp.statusMu.Unlock()
+	if dashboard == nil {
+		return ""
+	}
+	return dashboard.Addr()
+}
+
+// dashboardMaxEvents caps the captured traffic ring so a long session
+// doesn't grow without bound; the UI shows the most recent window.
+const dashboardMaxEvents = 500
+
+// dashboardEvent is one captured message, as served to the UI.
+type dashboardEvent struct {
+	Seq       int       `json:"seq"`
+	Time      time.Time `json:"time"`
+	Direction string    `json:"direction"` // "out" (client to server) or "in".
+	Message   string    `json:"message"`
+}
+
+// pauseGate lets the dashboard hold outbound messages: while paused, the
+// capture middleware blocks before forwarding, and each step releases one
+// message. Only the outbound direction is gated, so server streams can't
+// deadlock behind the pause.
+type pauseGate struct {
+	mu     sync.Mutex
+	paused bool
+	resume chan struct{}
+	step   chan struct{}
+}
+
+func newPauseGate() *pauseGate {
+	return &pauseGate{step: make(chan struct{}, 1)}
+}
+
+// Pause holds subsequent messages until Resume or a Step.
+func (g *pauseGate) Pause() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.paused {
+		g.paused = true
+		g.resume = make(chan struct{})
+	}
+}
+
+// Resume releases everything held and lets traffic flow again.
+func (g *pauseGate) Resume() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.paused {
+		g.paused = false
+		close(g.resume)
+	}
+}
+
+// Step releases a single held message while staying paused. A step with
+// nothing waiting releases the next message to arrive.
+func (g *pauseGate) Step() {
+	select {
+	case g.step <- struct{}{}:
+	default:
+	}
+}
+
+// Paused reports the gate's state.
+func (g *pauseGate) Paused() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.paused
+}
+
+// wait blocks while the gate is paused, returning on a resume or when a
+// step releases this message.
+func (g *pauseGate) wait() {
+	for {
+		g.mu.Lock()
+		if !g.paused {
+			g.mu.Unlock()
+			return
+		}
+		resume := g.resume
+		g.mu.Unlock()
+		select {
+		case <-resume:
+		case <-g.step:
+			return
+		}
+	}
+}
+
+// debugDashboard serves a localhost web UI showing live traffic, auth and
+// worker state, with pause/step control over outbound messages — a
+// lightweight built-in alternative to wiring up an external inspector. It
+// runs as a worker alongside the transports, like the health server.
+type debugDashboard struct {
+	addr   string
+	engine *MCPEngine
+	gate   *pauseGate
+	logger Logger
+
+	mu        sync.Mutex
+	boundAddr string
+	events    []dashboardEvent
+	nextSeq   int
+}
+
+// newDebugDashboard constructs a dashboard listening on addr.
+func newDebugDashboard(addr string, engine *MCPEngine, logger Logger) *debugDashboard {
+	return &debugDashboard{
+		addr:   addr,
+		engine: engine,
+		gate:   newPauseGate(),
+		logger: logger,
+	}
+}
+
+// Addr returns the listener's bound address, empty until the listener is up.
+func (d *debugDashboard) Addr() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.boundAddr
+}
+
+// record appends one captured message to the ring.
+func (d *debugDashboard) record(direction, msg string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, dashboardEvent{
+		Seq:       d.nextSeq,
+		Time:      time.Now(),
+		Direction: direction,
+		Message:   msg,
+	})
+	d.nextSeq++
+	if len(d.events) > dashboardMaxEvents {
+		d.events = d.events[len(d.events)-dashboardMaxEvents:]
+	}
+}
+
+// captureOutbound is the outbound middleware: it records the message and
+// honors the pause gate before letting it through.
+func (d *debugDashboard) captureOutbound(msg string) (string, error) {
+	d.record("out", msg)
+	d.gate.wait()
+	return msg, nil
+}
+
+// captureInbound is the inbound middleware: server messages are recorded
+// but never held, so SSE streams and responses keep flowing.
+func (d *debugDashboard) captureInbound(msg string) (string, error) {
+	d.record("in", msg)
+	return msg, nil
+}
+
+// eventsSince returns the captured events with Seq >= since and the next
+// sequence number to poll from.
+func (d *debugDashboard) eventsSince(since int) ([]dashboardEvent, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	events := make([]dashboardEvent, 0, len(d.events))
+	for _, event := range d.events {
+		if event.Seq >= since {
+			events = append(events, event)
+		}
+	}
+	return events, d.nextSeq
+}
+
+// Run serves the dashboard until the context is cancelled.
+func (d *debugDashboard) Run(ctx context.Context, cancel context.CancelFunc) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", d.handleIndex)
+	mux.HandleFunc("/api/events", d.handleEvents)
+	mux.HandleFunc("/api/status", d.handleStatus)
+	mux.HandleFunc("/api/pause", d.handlePause)
+	mux.HandleFunc("/api/resume", d.handleResume)
+	mux.HandleFunc("/api/step", d.handleStep)
+
+	listener, err := net.Listen("tcp", d.addr)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("dashboard listener on %s: %w", d.addr, err)
+	}
+	d.mu.Lock()
+	d.boundAddr = listener.Addr().String()
+	d.mu.Unlock()
+	d.logger.Infow("Debug dashboard listening", "addr", listener.Addr().String())
+
+	server := &http.Server{Handler: mux}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		// A pause must not outlive the session it was pausing.
+		d.gate.Resume()
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second)
+		defer shutdownCancel()
+		server.Shutdown(shutdownCtx)
+		return ctx.Err()
+	case err := <-errChan:
+		return err
+	}
+}
+
+// handleIndex serves the single-page UI.
+func (d *debugDashboard) handleIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, dashboardPage)
+}
+
+// handleEvents answers the captured traffic from ?since=N on.
+func (d *debugDashboard) handleEvents(w http.ResponseWriter, r *http.Request) {
+	since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+	events, next := d.eventsSince(since)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"events": events,
+		"next":   next,
+	}); err != nil {
+		d.logger.Errorf("Failed to encode events: %v", err)
+	}
+}
+
+// handleStatus answers the engine snapshot plus the gate state.
+func (d *debugDashboard) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status := d.engine.Status()
+	payload := map[string]interface{}{
+		"state":            status.State,
+		"transport":        status.Transport,
+		"endpoint":         status.Endpoint,
+		"token_present":    status.TokenPresent,
+		"token_expired":    status.TokenExpired,
+		"pending_requests": status.PendingRequests,
+		"paused":           d.gate.Paused(),
+	}
+	if status.LastError != nil {
+		payload["last_error"] = status.LastError.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		d.logger.Errorf("Failed to encode status: %v", err)
+	}
+}
+
+func (d *debugDashboard) handlePause(w http.ResponseWriter, r *http.Request) {
+	d.gate.Pause()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *debugDashboard) handleResume(w http.ResponseWriter, r *http.Request) {
+	d.gate.Resume()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *debugDashboard) handleStep(w http.ResponseWriter, r *http.Request) {
+	d.gate.Step()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// dashboardPage is the embedded UI: a polling view of the traffic ring with
+// the pause/step controls. Kept dependency-free on purpose.
+const dashboardPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>mcpengine debug</title>
+<style>
+body { font-family: monospace; margin: 1rem; background: #111; color: #ddd; }
+h1 { font-size: 1rem; }
+#status { margin-bottom: .5rem; color: #8c8; }
+#controls button { margin-right: .5rem; }
+table { border-collapse: collapse; width: 100%; }
+td { border-bottom: 1px solid #333; padding: 2px 6px; vertical-align: top; }
+td.dir-out { color: #6cf; }
+td.dir-in { color: #fc6; }
+td.msg { white-space: pre-wrap; word-break: break-all; }
+</style>
+</head>
+<body>
+<h1>mcpengine debug dashboard</h1>
+<div id="status">loading…</div>
+<div id="controls">
+<button onclick="post('pause')">Pause</button>
+<button onclick="post('step')">Step</button>
+<button onclick="post('resume')">Resume</button>
+</div>
+<table id="events"></table>
+<script>
+let since = 0;
+function post(action) { fetch('/api/' + action, {method: 'POST'}); }
+async function poll() {
+  try {
+    const status = await (await fetch('/api/status')).json();
+    document.getElementById('status').textContent =
+      'state=' + status.state + ' transport=' + status.transport +
+      ' token=' + (status.token_present ? (status.token_expired ? 'expired' : 'ok') : 'none') +
+      ' pending=' + status.pending_requests + (status.paused ? ' [PAUSED]' : '');
+    const data = await (await fetch('/api/events?since=' + since)).json();
+    since = data.next;
+    const table = document.getElementById('events');
+    for (const ev of data.events) {
+      const row = table.insertRow(0);
+      row.insertCell().textContent = new Date(ev.time).toLocaleTimeString();
+      const dir = row.insertCell();
+      dir.textContent = ev.direction === 'out' ? '→' : '←';
+      dir.className = 'dir-' + ev.direction;
+      const msg = row.insertCell();
+      msg.textContent = ev.message;
+      msg.className = 'msg';
+    }
+    while (table.rows.length > 500) table.deleteRow(-1);
+  } catch (e) { /* proxy restarting; keep polling */ }
+  setTimeout(poll, 500);
+}
+poll();
+</script>
+</body>
+</html>
+`
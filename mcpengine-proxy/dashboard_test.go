@@ -0,0 +1,181 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Debug Dashboard Tests =====
+
+// startTestDashboard runs a dashboard worker on a free port and returns it
+// with its base URL.
+func startTestDashboard(t *testing.T, engine *MCPEngine) (*debugDashboard, string) {
+	t.Helper()
+	dashboard := newDebugDashboard("127.0.0.1:0", engine, NewZapLogger(zap.NewNop().Sugar()))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go dashboard.Run(ctx, cancel)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for dashboard.Addr() == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("Dashboard never bound its listener")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return dashboard, "http://" + dashboard.Addr()
+}
+
+func TestDashboard_CapturesTraffic(t *testing.T) {
+	dashboard, base := startTestDashboard(t, &MCPEngine{})
+
+	dashboard.captureOutbound(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	dashboard.captureInbound(`{"jsonrpc":"2.0","id":1,"result":{}}`)
+
+	resp, err := http.Get(base + "/api/events?since=0")
+	if err != nil {
+		t.Fatalf("Failed to fetch events: %v", err)
+	}
+	defer resp.Body.Close()
+	var payload struct {
+		Events []dashboardEvent `json:"events"`
+		Next   int              `json:"next"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("Failed to decode events: %v", err)
+	}
+	if len(payload.Events) != 2 || payload.Next != 2 {
+		t.Fatalf("Expected 2 events, got %d (next %d)", len(payload.Events), payload.Next)
+	}
+	if payload.Events[0].Direction != "out" || payload.Events[1].Direction != "in" {
+		t.Errorf("Unexpected directions: %s, %s", payload.Events[0].Direction, payload.Events[1].Direction)
+	}
+
+	// Polling from next returns nothing new.
+	resp2, err := http.Get(fmt.Sprintf("%s/api/events?since=%d", base, payload.Next))
+	if err != nil {
+		t.Fatalf("Failed to fetch events: %v", err)
+	}
+	defer resp2.Body.Close()
+	var empty struct {
+		Events []dashboardEvent `json:"events"`
+	}
+	if err := json.NewDecoder(resp2.Body).Decode(&empty); err != nil {
+		t.Fatalf("Failed to decode events: %v", err)
+	}
+	if len(empty.Events) != 0 {
+		t.Errorf("Expected no new events, got %d", len(empty.Events))
+	}
+}
+
+func TestDashboard_EventRing(t *testing.T) {
+	dashboard := newDebugDashboard("127.0.0.1:0", &MCPEngine{}, NewZapLogger(zap.NewNop().Sugar()))
+	for i := 0; i < dashboardMaxEvents+50; i++ {
+		dashboard.record("out", fmt.Sprintf("msg-%d", i))
+	}
+	events, next := dashboard.eventsSince(0)
+	if len(events) != dashboardMaxEvents {
+		t.Errorf("Expected the ring capped at %d, got %d", dashboardMaxEvents, len(events))
+	}
+	if next != dashboardMaxEvents+50 {
+		t.Errorf("Expected sequence numbers to keep climbing, got %d", next)
+	}
+	if events[0].Message != "msg-50" {
+		t.Errorf("Expected the oldest events dropped, got %q first", events[0].Message)
+	}
+}
+
+func TestDashboard_PauseStepResume(t *testing.T) {
+	dashboard, base := startTestDashboard(t, &MCPEngine{})
+
+	post := func(action string) {
+		t.Helper()
+		resp, err := http.Post(base+"/api/"+action, "", nil)
+		if err != nil {
+			t.Fatalf("Failed to POST %s: %v", action, err)
+		}
+		resp.Body.Close()
+	}
+
+	post("pause")
+	released := make(chan int, 2)
+	for i := 1; i <= 2; i++ {
+		msg := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"ping"}`, i)
+		n := i
+		go func() {
+			dashboard.captureOutbound(msg)
+			released <- n
+		}()
+	}
+	select {
+	case n := <-released:
+		t.Fatalf("Expected messages held while paused, but %d passed", n)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// A step releases exactly one message.
+	post("step")
+	select {
+	case <-released:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Step did not release a message")
+	}
+	select {
+	case n := <-released:
+		t.Fatalf("Step released more than one message (%d)", n)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Resume releases the rest and lets new traffic through directly.
+	post("resume")
+	select {
+	case <-released:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Resume did not release the held message")
+	}
+	done := make(chan struct{})
+	go func() {
+		dashboard.captureOutbound(`{"jsonrpc":"2.0","id":3,"method":"ping"}`)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Traffic still held after resume")
+	}
+}
+
+func TestDashboard_StatusAndPage(t *testing.T) {
+	dashboard, base := startTestDashboard(t, &MCPEngine{})
+	dashboard.gate.Pause()
+	defer dashboard.gate.Resume()
+
+	resp, err := http.Get(base + "/api/status")
+	if err != nil {
+		t.Fatalf("Failed to fetch status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if status["paused"] != true {
+		t.Errorf("Expected paused status, got %v", status["paused"])
+	}
+
+	page, err := http.Get(base + "/")
+	if err != nil {
+		t.Fatalf("Failed to fetch page: %v", err)
+	}
+	defer page.Body.Close()
+	if got := page.Header.Get("Content-Type"); !strings.HasPrefix(got, "text/html") {
+		t.Errorf("Expected an HTML page, got %q", got)
+	}
+}
@@ -0,0 +1,122 @@
+package mcpengine
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DeadLetter is one undeliverable message, as persisted to the dead-letter
+// file: the original JSON-RPC line along with why and when delivery failed.
+type DeadLetter struct {
+	Time    time.Time `json:"time"`
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+}
+
+// DeadLetterQueue persists messages that could not be delivered — POST
+// failures, unexpected status codes — to a local JSONL file so they survive
+// the session and can be inspected or re-dispatched once the upstream
+// recovers (see the dlq subcommand).
+//
+// A nil DeadLetterQueue is valid and drops every entry, so call sites don't
+// need to guard.
+type DeadLetterQueue struct {
+	path   string
+	logger Logger
+	mu     sync.Mutex
+}
+
+// NewDeadLetterQueue creates a queue backed by the file at path, which is
+// created on the first entry.
+func NewDeadLetterQueue(path string, logger Logger) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		path:   path,
+		logger: logger,
+	}
+}
+
+// Add appends one undeliverable message with its failure reason. Failures
+// to persist are logged, never propagated: the dead-letter file is a best
+// effort on top of the JSON-RPC error the client already receives.
+func (dlq *DeadLetterQueue) Add(message, reason string) {
+	if dlq == nil {
+		return
+	}
+	entry, err := json.Marshal(DeadLetter{
+		Time:    time.Now(),
+		Reason:  reason,
+		Message: message,
+	})
+	if err != nil {
+		dlq.logger.Errorf("Failed to marshal dead letter: %v", err)
+		return
+	}
+	dlq.mu.Lock()
+	defer dlq.mu.Unlock()
+	file, err := os.OpenFile(dlq.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		dlq.logger.Errorw("Failed to open dead-letter file", "path", dlq.path, "err", err)
+		return
+	}
+	defer file.Close()
+	if _, err := fmt.Fprintf(file, "%s\n", entry); err != nil {
+		dlq.logger.Errorw("Failed to write dead letter", "path", dlq.path, "err", err)
+		return
+	}
+	dlq.logger.Warnw("Message dead-lettered", "reason", reason, "path", dlq.path)
+}
+
+// ReadDeadLetters loads every entry from a dead-letter file. A missing file
+// is an empty queue, not an error.
+func ReadDeadLetters(path string) ([]DeadLetter, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var letters []DeadLetter
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), defaultMaxMessageSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if text == "" {
+			continue
+		}
+		var letter DeadLetter
+		if err := json.Unmarshal([]byte(text), &letter); err != nil {
+			return nil, fmt.Errorf("dead-letter file %s line %d: %w", path, line, err)
+		}
+		letters = append(letters, letter)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return letters, nil
+}
+
+// TakeDeadLetters reads every entry and truncates the file, handing the
+// messages to the caller for re-dispatch. Entries are only removed after a
+// successful read, so a corrupt file is never silently discarded.
+func TakeDeadLetters(path string) ([]DeadLetter, error) {
+	letters, err := ReadDeadLetters(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(letters) == 0 {
+		return nil, nil
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		return nil, fmt.Errorf("failed to clear dead-letter file: %w", err)
+	}
+	return letters, nil
+}
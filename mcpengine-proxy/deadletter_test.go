@@ -0,0 +1,130 @@
+package mcpengine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Dead-Letter Queue Tests =====
+
+func TestDeadLetterQueue_AddAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	dlq := NewDeadLetterQueue(path, NewZapLogger(zap.NewNop().Sugar()))
+
+	dlq.Add(`{"jsonrpc":"2.0","id":1,"method":"ping"}`, "delivery failed: connection refused")
+	dlq.Add(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, "unexpected status 503")
+
+	letters, err := ReadDeadLetters(path)
+	if err != nil {
+		t.Fatalf("Failed to read dead letters: %v", err)
+	}
+	if len(letters) != 2 {
+		t.Fatalf("Expected 2 dead letters, got %d", len(letters))
+	}
+	if letters[0].Reason != "delivery failed: connection refused" {
+		t.Errorf("Unexpected reason: %q", letters[0].Reason)
+	}
+	if !strings.Contains(letters[1].Message, `"tools/list"`) {
+		t.Errorf("Unexpected message: %q", letters[1].Message)
+	}
+	if letters[0].Time.IsZero() {
+		t.Error("Expected a timestamp on the entry")
+	}
+}
+
+func TestDeadLetterQueue_NilIsSafe(t *testing.T) {
+	var dlq *DeadLetterQueue
+	dlq.Add("msg", "reason") // Must not panic.
+}
+
+func TestReadDeadLetters_MissingFile(t *testing.T) {
+	letters, err := ReadDeadLetters(filepath.Join(t.TempDir(), "absent.jsonl"))
+	if err != nil {
+		t.Fatalf("A missing file should be an empty queue, got %v", err)
+	}
+	if len(letters) != 0 {
+		t.Errorf("Expected no letters, got %d", len(letters))
+	}
+}
+
+func TestTakeDeadLetters_DrainsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	dlq := NewDeadLetterQueue(path, NewZapLogger(zap.NewNop().Sugar()))
+	dlq.Add(`{"jsonrpc":"2.0","id":1,"method":"ping"}`, "unexpected status 500")
+
+	letters, err := TakeDeadLetters(path)
+	if err != nil {
+		t.Fatalf("Failed to take dead letters: %v", err)
+	}
+	if len(letters) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(letters))
+	}
+
+	remaining, err := ReadDeadLetters(path)
+	if err != nil {
+		t.Fatalf("Failed to re-read the file: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("Expected the file drained, got %d entries", len(remaining))
+	}
+}
+
+func TestHTTPPostSender_DeadLettersOnUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 10)
+
+	sender := NewHTTPPostSender(server.Client(), server.URL, endpointChan, inputChan, outputChan, NewAuthManager(nil, logger), logger)
+	sender.SetDeadLetterQueue(NewDeadLetterQueue(path, logger))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	endpointChan <- "/mcp"
+	request := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	inputChan <- request
+
+	// The client still gets its JSON-RPC error.
+	select {
+	case msg := <-outputChan:
+		if !strings.Contains(msg, `"code":-32013`) {
+			t.Errorf("Expected an upstream server error, got %s", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No error reached the output channel")
+	}
+
+	// And the message lands in the dead-letter file.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		letters, err := ReadDeadLetters(path)
+		if err == nil && len(letters) == 1 {
+			if letters[0].Message != request {
+				t.Errorf("Expected the original message, got %q", letters[0].Message)
+			}
+			if letters[0].Reason != "unexpected status 503" {
+				t.Errorf("Unexpected reason: %q", letters[0].Reason)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Dead letter never appeared (entries: %d, err: %v)", len(letters), err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
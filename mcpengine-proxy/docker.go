@@ -0,0 +1,41 @@
+package mcpengine
+
+// DockerConfig describes a containerized MCP server. The engine runs the
+// image with `docker run -i --rm` and bridges stdio through the container,
+// so untrusted servers can be sandboxed with a single config entry. Works
+// with any docker-compatible CLI (podman) via Binary.
+//   - Image:   Container image to run. Required.
+//   - Command: Command and arguments passed to the image's entrypoint.
+//   - Env:     KEY=VALUE entries set inside the container.
+//   - Mounts:  Volume specs as "host:container[:ro]".
+//   - Network: Docker network for the container, e.g. "none" to isolate a
+//     server that needs no egress.
+//   - Binary:  The container CLI to invoke. Defaults to "docker".
+type DockerConfig struct {
+	Image   string
+	Command []string
+	Env     []string
+	Mounts  []string
+	Network string
+	Binary  string
+}
+
+// commandLine assembles the host command the StdioBridge launches.
+func (dc DockerConfig) commandLine() []string {
+	binary := dc.Binary
+	if binary == "" {
+		binary = "docker"
+	}
+	args := []string{binary, "run", "-i", "--rm"}
+	for _, env := range dc.Env {
+		args = append(args, "-e", env)
+	}
+	for _, mount := range dc.Mounts {
+		args = append(args, "-v", mount)
+	}
+	if dc.Network != "" {
+		args = append(args, "--network", dc.Network)
+	}
+	args = append(args, dc.Image)
+	return append(args, dc.Command...)
+}
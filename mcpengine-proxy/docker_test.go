@@ -0,0 +1,118 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"go.uber.org/zap"
+)
+
+// ===== Docker Transport Tests =====
+
+func TestDockerConfig_CommandLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   DockerConfig
+		expected []string
+	}{
+		{
+			name:     "image only",
+			config:   DockerConfig{Image: "mcp/server"},
+			expected: []string{"docker", "run", "-i", "--rm", "mcp/server"},
+		},
+		{
+			name: "full configuration",
+			config: DockerConfig{
+				Image:   "mcp/server:v2",
+				Command: []string{"serve", "--fast"},
+				Env:     []string{"A=1", "B=2"},
+				Mounts:  []string{"/data:/data:ro"},
+				Network: "none",
+				Binary:  "podman",
+			},
+			expected: []string{
+				"podman", "run", "-i", "--rm",
+				"-e", "A=1", "-e", "B=2",
+				"-v", "/data:/data:ro",
+				"--network", "none",
+				"mcp/server:v2", "serve", "--fast",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.commandLine(); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDockerTransport_Bridge(t *testing.T) {
+	// A stub docker CLI that ignores its arguments and echoes stdio stands
+	// in for the real daemon.
+	stub := filepath.Join(t.TempDir(), "docker-stub")
+	script := "#!/bin/sh\nexec cat\n"
+	if err := os.WriteFile(stub, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write stub: %v", err)
+	}
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	input := mcpenginetest.NewBlockReader(strings.NewReader(request + "\n"))
+	outputReader, outputWriter := io.Pipe()
+	engine, err := New(Config{
+		Docker: &DockerConfig{Image: "mcp/server", Binary: stub},
+		Logger: NewZapLogger(zap.NewNop().Sugar()),
+		Input:  input,
+		Output: outputWriter,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	lineChan := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		if scanner.Scan() {
+			lineChan <- scanner.Text()
+		}
+	}()
+	select {
+	case got := <-lineChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back through the container bridge")
+	}
+}
+
+func TestConfigValidate_Docker(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	if err := (Config{Transport: TransportDocker, Logger: logger}).Validate(); err == nil {
+		t.Error("Expected an error for a missing image")
+	}
+	if err := (Config{Docker: &DockerConfig{Image: "mcp/server"}, Logger: logger}).Validate(); err != nil {
+		t.Errorf("Expected a docker config to validate, got %v", err)
+	}
+	if err := (Config{
+		Docker:  &DockerConfig{Image: "mcp/server"},
+		Command: []string{"cat"},
+		Logger:  logger,
+	}).Validate(); err == nil {
+		t.Error("Expected an error combining Command and Docker")
+	}
+}
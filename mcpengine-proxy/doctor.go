@@ -0,0 +1,270 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DoctorStatus classifies the outcome of one diagnostic check.
+type DoctorStatus string
+
+const (
+	DoctorPass DoctorStatus = "pass"
+	DoctorWarn DoctorStatus = "warn"
+	DoctorFail DoctorStatus = "fail"
+	DoctorSkip DoctorStatus = "skip"
+)
+
+// DoctorCheck is the outcome of one diagnostic: what was checked, how it
+// went, and — on failure — what to do about it.
+type DoctorCheck struct {
+	Name   string
+	Status DoctorStatus
+	Detail string
+	Remedy string
+}
+
+// DoctorConfig describes the server a doctor run diagnoses. The zero
+// values mirror the CLI defaults.
+type DoctorConfig struct {
+	Endpoint       string
+	Transport      TransportMode
+	SSEPath        string
+	MCPPath        string
+	OIDCConfigPath string
+	// CallbackPort is the auth callback port whose availability is
+	// verified. Zero checks the default (8181); negative skips the check,
+	// matching EphemeralPort's semantics.
+	CallbackPort int
+	// KeychainAccount keys the cached-token check; empty skips it. The
+	// CLI passes the -host value, matching how tokens are stored.
+	KeychainAccount string
+	// HTTPClient performs the connectivity and discovery probes. Nil uses
+	// a short-timeout default.
+	HTTPClient *http.Client
+}
+
+// RunDoctor performs the diagnostics in order and returns one check per
+// area: endpoint connectivity, OIDC discovery, callback port availability,
+// and cached token validity. It never returns an error; problems are the
+// results.
+func RunDoctor(ctx context.Context, cfg DoctorConfig) []DoctorCheck {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return []DoctorCheck{
+		checkEndpoint(ctx, client, cfg),
+		checkOIDCDiscovery(ctx, client, cfg),
+		checkCallbackPort(cfg.CallbackPort),
+		checkCachedToken(cfg.KeychainAccount),
+	}
+}
+
+// probePath returns the path the connectivity probe requests, matching
+// what the proxy itself would contact first.
+func (cfg DoctorConfig) probePath() string {
+	switch cfg.Transport {
+	case TransportSSE, "":
+		if cfg.SSEPath != "" {
+			return cfg.SSEPath
+		}
+		return "/sse"
+	default:
+		if cfg.MCPPath != "" {
+			return cfg.MCPPath
+		}
+		return "/mcp"
+	}
+}
+
+// checkEndpoint verifies the server answers at all on the transport's
+// path. An auth challenge counts as reachable: it proves the server is
+// there.
+func checkEndpoint(ctx context.Context, client *http.Client, cfg DoctorConfig) DoctorCheck {
+	check := DoctorCheck{Name: "endpoint"}
+	if cfg.Endpoint == "" {
+		check.Status = DoctorSkip
+		check.Detail = "no endpoint configured"
+		return check
+	}
+	switch cfg.Transport {
+	case TransportStdio, TransportDocker, TransportPipe, TransportGRPC, TransportTCP:
+		check.Status = DoctorSkip
+		check.Detail = fmt.Sprintf("the %s transport is not probed over HTTP", cfg.Transport)
+		return check
+	}
+
+	probeURL := cfg.Endpoint + cfg.probePath()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, probeURL, nil)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("invalid endpoint URL: %v", err)
+		check.Remedy = "check the -host value; it must be an absolute URL like https://mcp.example.com"
+		return check
+	}
+	req.Header.Set("Accept", "text/event-stream, application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("connection failed: %v", err)
+		check.Remedy = "verify the server is running and reachable (DNS, firewall, proxy settings); try curl " + probeURL
+		return check
+	}
+	// Only the headers matter; an SSE body would stream forever.
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		check.Status = DoctorPass
+		check.Detail = fmt.Sprintf("reachable (%s; authentication required)", resp.Status)
+	case resp.StatusCode >= 200 && resp.StatusCode < 400:
+		check.Status = DoctorPass
+		check.Detail = fmt.Sprintf("reachable (%s)", resp.Status)
+	case resp.StatusCode == http.StatusNotFound:
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("%s returned %s", probeURL, resp.Status)
+		check.Remedy = "the path looks wrong; check -sse_path/-mcp_path or try -mode auto to detect the transport"
+	default:
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("%s returned %s", probeURL, resp.Status)
+		check.Remedy = "the server answered but is unhealthy; check its logs"
+	}
+	return check
+}
+
+// checkOIDCDiscovery fetches the OpenID configuration the auth flow would
+// use and verifies it names the endpoints token exchange needs.
+func checkOIDCDiscovery(ctx context.Context, client *http.Client, cfg DoctorConfig) DoctorCheck {
+	check := DoctorCheck{Name: "oidc-discovery"}
+	if cfg.Endpoint == "" || !strings.HasPrefix(cfg.Endpoint, "http") {
+		check.Status = DoctorSkip
+		check.Detail = "no HTTP endpoint to discover against"
+		return check
+	}
+	configPath := cfg.OIDCConfigPath
+	if configPath == "" {
+		configPath = "/.well-known/openid-configuration"
+	}
+	configURL := strings.TrimSuffix(cfg.Endpoint, "/") + configPath
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, configURL, nil)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("invalid discovery URL: %v", err)
+		check.Remedy = "check the -host value"
+		return check
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("discovery request failed: %v", err)
+		check.Remedy = "verify the server is reachable; OAuth cannot complete without discovery"
+		return check
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		check.Status = DoctorWarn
+		check.Detail = fmt.Sprintf("%s returned %s", configURL, resp.Status)
+		check.Remedy = "the server publishes no OIDC configuration; auth challenges will rely on RFC 9728 resource metadata, or use -bearer_token/-api_key"
+		return check
+	}
+	if resp.StatusCode != http.StatusOK {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("%s returned %s", configURL, resp.Status)
+		check.Remedy = "the discovery document must answer 200; check the identity provider"
+		return check
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("failed to read discovery document: %v", err)
+		check.Remedy = "retry; if it persists, check the identity provider"
+		return check
+	}
+	var oidcConfig OpenIDConfiguration
+	if err := json.Unmarshal(body, &oidcConfig); err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("discovery document is not valid JSON: %v", err)
+		check.Remedy = "the path may be served by a catch-all handler; check -host and the OIDC config path"
+		return check
+	}
+	if oidcConfig.AuthorizationEndpoint == "" || oidcConfig.TokenEndpoint == "" {
+		check.Status = DoctorFail
+		check.Detail = "discovery document names no authorization or token endpoint"
+		check.Remedy = "the identity provider's configuration is incomplete; OAuth cannot complete"
+		return check
+	}
+	check.Status = DoctorPass
+	check.Detail = fmt.Sprintf("issuer %s", oidcConfig.Issuer)
+	return check
+}
+
+// checkCallbackPort verifies the auth callback listener could bind.
+func checkCallbackPort(port int) DoctorCheck {
+	check := DoctorCheck{Name: "callback-port"}
+	if port < 0 {
+		check.Status = DoctorSkip
+		check.Detail = "an ephemeral port is used; nothing to collide with"
+		return check
+	}
+	if port == 0 {
+		port = 8181
+	}
+	listener, err := net.Listen("tcp", fmt.Sprintf("localhost:%d", port))
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("port %d is not free: %v", port, err)
+		check.Remedy = "another process holds the callback port; stop it, pick another with -auth_port, or use -auth_port 0 for an ephemeral one"
+		return check
+	}
+	listener.Close()
+	check.Status = DoctorPass
+	check.Detail = fmt.Sprintf("port %d is free", port)
+	return check
+}
+
+// checkCachedToken reports whether the keychain holds a usable token for
+// the server.
+func checkCachedToken(account string) DoctorCheck {
+	check := DoctorCheck{Name: "cached-token"}
+	if account == "" {
+		check.Status = DoctorSkip
+		check.Detail = "no keychain account to check"
+		return check
+	}
+	token, err := NewKeychainTokenStore(account).Load()
+	if err != nil {
+		check.Status = DoctorWarn
+		check.Detail = fmt.Sprintf("failed to read the keychain: %v", err)
+		check.Remedy = "the OS credential store is unavailable; tokens won't persist across restarts"
+		return check
+	}
+	if token == nil {
+		check.Status = DoctorWarn
+		check.Detail = "no cached token"
+		check.Remedy = "the first request will trigger a browser auth flow; run with -keychain to persist the result"
+		return check
+	}
+	if !token.Expiry.IsZero() && token.Expiry.Before(time.Now()) {
+		if token.RefreshToken != "" {
+			check.Status = DoctorPass
+			check.Detail = "cached token is expired but a refresh token is present"
+			return check
+		}
+		check.Status = DoctorWarn
+		check.Detail = "cached token is expired with no refresh token"
+		check.Remedy = "the next request will re-authenticate; delete the stale entry with \"mcpengine uninstall -purge\" if it causes trouble"
+		return check
+	}
+	check.Status = DoctorPass
+	check.Detail = "cached token is valid"
+	return check
+}
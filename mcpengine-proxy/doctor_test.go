@@ -0,0 +1,149 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+
+	"golang.org/x/oauth2"
+)
+
+// ===== Doctor Tests =====
+
+// checkByName finds one check in a doctor run's results.
+func checkByName(t *testing.T, checks []DoctorCheck, name string) DoctorCheck {
+	t.Helper()
+	for _, check := range checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("No check named %q in %v", name, checks)
+	return DoctorCheck{}
+}
+
+func TestRunDoctor_HealthyServer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/sse":
+			w.Header().Set("Content-Type", "text/event-stream")
+		case "/.well-known/openid-configuration":
+			fmt.Fprintf(w, `{"issuer": %q, "authorization_endpoint": "%s/auth", "token_endpoint": "%s/token"}`,
+				"https://idp.example.com", "https://idp.example.com", "https://idp.example.com")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	checks := RunDoctor(context.Background(), DoctorConfig{
+		Endpoint:     ts.URL,
+		Transport:    TransportSSE,
+		CallbackPort: EphemeralPort,
+	})
+	if check := checkByName(t, checks, "endpoint"); check.Status != DoctorPass {
+		t.Errorf("Expected the endpoint check to pass, got %s: %s", check.Status, check.Detail)
+	}
+	if check := checkByName(t, checks, "oidc-discovery"); check.Status != DoctorPass {
+		t.Errorf("Expected discovery to pass, got %s: %s", check.Status, check.Detail)
+	}
+	if check := checkByName(t, checks, "callback-port"); check.Status != DoctorSkip {
+		t.Errorf("Expected the port check to be skipped for an ephemeral port, got %s", check.Status)
+	}
+}
+
+func TestRunDoctor_AuthChallengeCountsAsReachable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	checks := RunDoctor(context.Background(), DoctorConfig{Endpoint: ts.URL, Transport: TransportHTTP})
+	if check := checkByName(t, checks, "endpoint"); check.Status != DoctorPass {
+		t.Errorf("Expected a 401 to count as reachable, got %s: %s", check.Status, check.Detail)
+	}
+}
+
+func TestRunDoctor_Failures(t *testing.T) {
+	// Nothing listens here: connectivity and discovery must both fail
+	// with remedies attached.
+	checks := RunDoctor(context.Background(), DoctorConfig{
+		Endpoint:   "http://127.0.0.1:1",
+		Transport:  TransportHTTP,
+		HTTPClient: &http.Client{Timeout: time.Second},
+	})
+	endpoint := checkByName(t, checks, "endpoint")
+	if endpoint.Status != DoctorFail || endpoint.Remedy == "" {
+		t.Errorf("Expected a failure with a remedy, got %s: %q", endpoint.Status, endpoint.Remedy)
+	}
+	discovery := checkByName(t, checks, "oidc-discovery")
+	if discovery.Status != DoctorFail || discovery.Remedy == "" {
+		t.Errorf("Expected discovery to fail with a remedy, got %s: %q", discovery.Status, discovery.Remedy)
+	}
+}
+
+func TestRunDoctor_MissingDiscoveryIsAWarning(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mcp" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	checks := RunDoctor(context.Background(), DoctorConfig{Endpoint: ts.URL, Transport: TransportStreamableHTTP})
+	if check := checkByName(t, checks, "oidc-discovery"); check.Status != DoctorWarn {
+		t.Errorf("Expected a 404 on discovery to warn, got %s: %s", check.Status, check.Detail)
+	}
+}
+
+func TestCheckCallbackPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	if check := checkCallbackPort(port); check.Status != DoctorFail || check.Remedy == "" {
+		t.Errorf("Expected an occupied port to fail with a remedy, got %s: %q", check.Status, check.Remedy)
+	}
+
+	listener.Close()
+	if check := checkCallbackPort(port); check.Status != DoctorPass {
+		t.Errorf("Expected a free port to pass, got %s: %s", check.Status, check.Detail)
+	}
+}
+
+func TestCheckCachedToken(t *testing.T) {
+	keyring.MockInit()
+
+	if check := checkCachedToken(""); check.Status != DoctorSkip {
+		t.Errorf("Expected no account to skip, got %s", check.Status)
+	}
+	if check := checkCachedToken("doctor.example.com"); check.Status != DoctorWarn {
+		t.Errorf("Expected a missing token to warn, got %s", check.Status)
+	}
+
+	store := NewKeychainTokenStore("doctor.example.com")
+	if err := store.Save(&oauth2.Token{AccessToken: "tok", Expiry: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	if check := checkCachedToken("doctor.example.com"); check.Status != DoctorPass {
+		t.Errorf("Expected a valid token to pass, got %s: %s", check.Status, check.Detail)
+	}
+
+	if err := store.Save(&oauth2.Token{AccessToken: "tok", Expiry: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+	if check := checkCachedToken("doctor.example.com"); check.Status != DoctorWarn || check.Remedy == "" {
+		t.Errorf("Expected an expired token to warn with a remedy, got %s: %q", check.Status, check.Remedy)
+	}
+}
@@ -0,0 +1,195 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+)
+
+// GRPCConfig describes the experimental gRPC transport, for internal
+// platforms that wrap MCP in a gRPC bidirectional stream. The service
+// definition is deliberately trivial: one bidi-streaming method whose
+// messages are raw JSON-RPC payloads, so no generated code is needed on
+// either side.
+//   - Target:    The gRPC server as host:port. Required.
+//   - Method:    Full method name of the bidi stream. Defaults to
+//     "/mcp.MCPEngine/Session".
+//   - PlainText: Dial without TLS, for internal deployments. When false,
+//     Config.TLS applies.
+type GRPCConfig struct {
+	Target    string
+	Method    string
+	PlainText bool
+}
+
+// grpcDefaultMethod is the bidi-streaming method used when none is
+// configured.
+const grpcDefaultMethod = "/mcp.MCPEngine/Session"
+
+// method returns the configured or default stream method.
+func (gc GRPCConfig) method() string {
+	if gc.Method != "" {
+		return gc.Method
+	}
+	return grpcDefaultMethod
+}
+
+// grpcRawCodec passes message bytes through untouched, so the stream
+// carries raw JSON-RPC lines instead of protobuf-encoded wrappers.
+type grpcRawCodec struct{}
+
+// grpcRawFrame is the message type the codec moves bytes in and out of.
+type grpcRawFrame struct {
+	data []byte
+}
+
+func (grpcRawCodec) Marshal(v interface{}) ([]byte, error) {
+	frame, ok := v.(*grpcRawFrame)
+	if !ok {
+		return nil, fmt.Errorf("grpc: unexpected message type %T", v)
+	}
+	return frame.data, nil
+}
+
+func (grpcRawCodec) Unmarshal(data []byte, v interface{}) error {
+	frame, ok := v.(*grpcRawFrame)
+	if !ok {
+		return fmt.Errorf("grpc: unexpected message type %T", v)
+	}
+	frame.data = data
+	return nil
+}
+
+func (grpcRawCodec) Name() string { return "mcp-raw" }
+
+// grpcStreamDesc describes the bidi session stream.
+var grpcStreamDesc = &grpc.StreamDesc{
+	StreamName:    "Session",
+	ClientStreams: true,
+	ServerStreams: true,
+}
+
+// grpcBridge holds a bidirectional gRPC stream open and pumps JSON-RPC
+// messages over it, attaching the Authenticator's token as per-RPC
+// authorization metadata.
+type grpcBridge struct {
+	cfg        GRPCConfig
+	tlsCfg     *TLSClientConfig
+	auth       Authenticator
+	inputChan  chan string // Messages sent on the stream.
+	outputChan chan string // Messages received from the stream.
+	logger     Logger
+}
+
+// newGRPCBridge constructs a grpcBridge from the given config.
+func newGRPCBridge(cfg GRPCConfig, tlsCfg *TLSClientConfig, auth Authenticator, inputChan, outputChan chan string, logger Logger) *grpcBridge {
+	return &grpcBridge{
+		cfg:        cfg,
+		tlsCfg:     tlsCfg,
+		auth:       auth,
+		inputChan:  inputChan,
+		outputChan: outputChan,
+		logger:     logger,
+	}
+}
+
+// dialCredentials builds the transport credentials for the dial.
+func (gb *grpcBridge) dialCredentials() (credentials.TransportCredentials, error) {
+	if gb.cfg.PlainText {
+		return insecure.NewCredentials(), nil
+	}
+	if gb.tlsCfg != nil {
+		tlsConfig, err := gb.tlsCfg.build()
+		if err != nil {
+			return nil, err
+		}
+		return credentials.NewTLS(tlsConfig), nil
+	}
+	return credentials.NewTLS(nil), nil
+}
+
+// Run dials the server, opens the session stream and pumps messages until
+// the context is cancelled, the input channel closes, or the stream
+// breaks.
+func (gb *grpcBridge) Run(ctx context.Context, cancel context.CancelFunc) error {
+	creds, err := gb.dialCredentials()
+	if err != nil {
+		cancel()
+		return fmt.Errorf("grpc: %w", err)
+	}
+	conn, err := grpc.NewClient(gb.cfg.Target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		cancel()
+		return fmt.Errorf("grpc: failed to dial %s: %w", gb.cfg.Target, err)
+	}
+	defer conn.Close()
+
+	streamCtx := ctx
+	if gb.auth != nil {
+		if token := gb.auth.Token(); token != "" {
+			streamCtx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+		}
+	}
+	stream, err := conn.NewStream(streamCtx, grpcStreamDesc, gb.cfg.method(), grpc.ForceCodec(grpcRawCodec{}))
+	if err != nil {
+		cancel()
+		return fmt.Errorf("grpc: failed to open stream: %w", err)
+	}
+	gb.logger.Infow("gRPC session stream open", "target", gb.cfg.Target, "method", gb.cfg.method())
+
+	readErrChan := make(chan error, 1)
+	go func() {
+		for {
+			frame := &grpcRawFrame{}
+			if err := stream.RecvMsg(frame); err != nil {
+				readErrChan <- err
+				return
+			}
+			line := string(frame.data)
+			gb.logger.Debugw("Received from stream", "line", line)
+			select {
+			case gb.outputChan <- line:
+			case <-ctx.Done():
+				readErrChan <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErrChan:
+			cancel()
+			if ctx.Err() == nil {
+				gb.logger.Errorw("Lost the gRPC stream", "err", err)
+				return err
+			}
+			return ctx.Err()
+		case msg, ok := <-gb.inputChan:
+			if !ok {
+				gb.logger.Info("Client input closed; closing stream")
+				stream.CloseSend()
+				cancel()
+				return nil
+			}
+			if err := stream.SendMsg(&grpcRawFrame{data: []byte(msg)}); err != nil {
+				gb.logger.Errorw("Failed to send on stream", "err", err)
+				cancel()
+				return err
+			}
+		}
+	}
+}
+
+// init registers the raw codec so servers built on the same convention can
+// opt into it by name.
+func init() {
+	encoding.RegisterCodec(grpcRawCodec{})
+}
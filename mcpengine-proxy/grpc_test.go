@@ -0,0 +1,177 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"go.uber.org/zap"
+)
+
+// ===== gRPC Transport Tests =====
+
+// serveGRPCEcho starts a gRPC server that echoes raw frames on any bidi
+// stream and captures the authorization metadata it saw.
+func serveGRPCEcho(t *testing.T, authChan chan string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(grpcRawCodec{}),
+		grpc.UnknownServiceHandler(func(srv interface{}, stream grpc.ServerStream) error {
+			if authChan != nil {
+				if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+					if values := md.Get("authorization"); len(values) > 0 {
+						select {
+						case authChan <- values[0]:
+						default:
+						}
+					}
+				}
+			}
+			for {
+				frame := &grpcRawFrame{}
+				if err := stream.RecvMsg(frame); err != nil {
+					return err
+				}
+				if err := stream.SendMsg(frame); err != nil {
+					return err
+				}
+			}
+		}),
+	)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+func TestGRPCBridge_Echo(t *testing.T) {
+	target := serveGRPCEcho(t, nil)
+
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	bridge := newGRPCBridge(GRPCConfig{Target: target, PlainText: true}, nil, nil,
+		inputChan, outputChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bridge.Run(ctx, cancel)
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	inputChan <- request
+	select {
+	case got := <-outputChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back through the stream")
+	}
+}
+
+func TestGRPCBridge_BearerMetadata(t *testing.T) {
+	authChan := make(chan string, 1)
+	target := serveGRPCEcho(t, authChan)
+
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	auth := NewStaticTokenAuthenticator("grpc-token", NewZapLogger(zap.NewNop().Sugar()))
+	bridge := newGRPCBridge(GRPCConfig{Target: target, PlainText: true}, nil, auth,
+		inputChan, outputChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bridge.Run(ctx, cancel)
+
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	<-outputChan
+	select {
+	case got := <-authChan:
+		if got != "Bearer grpc-token" {
+			t.Errorf("Expected the bearer token in metadata, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server never saw authorization metadata")
+	}
+}
+
+func TestGRPCTransport_Engine(t *testing.T) {
+	target := serveGRPCEcho(t, nil)
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	input := mcpenginetest.NewBlockReader(strings.NewReader(request + "\n"))
+	outputReader, outputWriter := io.Pipe()
+	engine, err := New(Config{
+		GRPC:   &GRPCConfig{Target: target, PlainText: true},
+		Logger: NewZapLogger(zap.NewNop().Sugar()),
+		Input:  input,
+		Output: outputWriter,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	lineChan := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		if scanner.Scan() {
+			lineChan <- scanner.Text()
+		}
+	}()
+	select {
+	case got := <-lineChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back through the engine")
+	}
+}
+
+func TestGRPCConfig_Validation(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	if err := (Config{
+		GRPC:   &GRPCConfig{},
+		Logger: logger,
+	}).Validate(); err == nil {
+		t.Error("Expected an error for a missing target")
+	}
+	if err := (Config{
+		Endpoint: "http://localhost:8000",
+		GRPC:     &GRPCConfig{Target: "localhost:9000"},
+		Logger:   logger,
+	}).Validate(); err == nil {
+		t.Error("Expected an error combining an Endpoint with the grpc transport")
+	}
+	if err := (Config{
+		GRPC:    &GRPCConfig{Target: "localhost:9000"},
+		MCPPath: "/mcp",
+		Logger:  logger,
+	}).Validate(); err == nil {
+		t.Error("Expected an error for MCPPath on the grpc transport")
+	}
+	if err := (Config{
+		GRPC:   &GRPCConfig{Target: "localhost:9000"},
+		Logger: logger,
+	}).Validate(); err != nil {
+		t.Errorf("Expected a minimal grpc config to validate, got %v", err)
+	}
+	if got := (GRPCConfig{}).method(); got != grpcDefaultMethod {
+		t.Errorf("Expected the default method, got %q", got)
+	}
+}
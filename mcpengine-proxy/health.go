@@ -0,0 +1,159 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HealthAddr returns the bound address of the health listener, or "" when
+// none is configured or it isn't up yet.
+func (mcp *MCPEngine) HealthAddr() string {
+	mcp.statusMu.Lock()
+	health := mcp.health
+	mcp.statusMu.Unlock()
+	if health == nil {
+		return ""
+	}
+	return health.Addr()
+}
+
+// healthServer serves /healthz and /status on a local listener so
+// orchestration tools and desktop supervisors can detect a wedged proxy
+// without speaking MCP. It runs as a worker alongside the transports.
+type healthServer struct {
+	addr   string
+	engine *MCPEngine
+	logger Logger
+
+	mu        sync.Mutex
+	boundAddr string
+}
+
+// newHealthServer constructs a healthServer listening on addr.
+func newHealthServer(addr string, engine *MCPEngine, logger Logger) *healthServer {
+	return &healthServer{
+		addr:   addr,
+		engine: engine,
+		logger: logger,
+	}
+}
+
+// Addr returns the listener's bound address, which differs from the
+// configured one when port 0 was requested. Empty until the listener is up.
+func (hs *healthServer) Addr() string {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+	return hs.boundAddr
+}
+
+// Run serves health requests until the context is cancelled.
+func (hs *healthServer) Run(ctx context.Context, cancel context.CancelFunc) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", hs.handleHealthz)
+	mux.HandleFunc("/status", hs.handleStatus)
+
+	listener, err := net.Listen("tcp", hs.addr)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("health listener on %s: %w", hs.addr, err)
+	}
+	hs.mu.Lock()
+	hs.boundAddr = listener.Addr().String()
+	hs.mu.Unlock()
+	hs.logger.Infow("Health endpoint listening", "addr", listener.Addr().String())
+
+	server := &http.Server{Handler: mux}
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second)
+		defer shutdownCancel()
+		server.Shutdown(shutdownCtx)
+		return ctx.Err()
+	case err := <-errChan:
+		return err
+	}
+}
+
+// handleHealthz answers 200 while the engine can deliver messages and 503
+// otherwise, so a plain liveness probe needs no JSON parsing.
+func (hs *healthServer) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	status := hs.engine.Status()
+	if status.State == StateConnected {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "ok")
+		return
+	}
+	w.WriteHeader(http.StatusServiceUnavailable)
+	fmt.Fprintln(w, string(status.State))
+}
+
+// statusPayload is the JSON shape of /status. LastError is flattened to its
+// message since errors don't marshal.
+type statusPayload struct {
+	State           EngineState          `json:"state"`
+	Transport       TransportMode        `json:"transport"`
+	Endpoint        string               `json:"endpoint"`
+	POSTPath        string               `json:"post_path,omitempty"`
+	SessionID       string               `json:"session_id,omitempty"`
+	TokenPresent    bool                 `json:"token_present"`
+	TokenExpired    bool                 `json:"token_expired"`
+	PendingRequests int                  `json:"pending_requests"`
+	RequestStats    []methodStatsPayload `json:"request_stats,omitempty"`
+	LastError       string               `json:"last_error,omitempty"`
+}
+
+// methodStatsPayload is the JSON shape of one method's aggregates; latencies
+// are reported in milliseconds.
+type methodStatsPayload struct {
+	Method    string  `json:"method"`
+	Count     int     `json:"count"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"error_rate"`
+	P50Ms     float64 `json:"p50_ms"`
+	P95Ms     float64 `json:"p95_ms"`
+}
+
+// handleStatus answers the full introspection snapshot as JSON.
+func (hs *healthServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status := hs.engine.Status()
+	payload := statusPayload{
+		State:           status.State,
+		Transport:       status.Transport,
+		Endpoint:        status.Endpoint,
+		POSTPath:        status.POSTPath,
+		SessionID:       status.SessionID,
+		TokenPresent:    status.TokenPresent,
+		TokenExpired:    status.TokenExpired,
+		PendingRequests: status.PendingRequests,
+	}
+	for _, stats := range status.RequestStats {
+		entry := methodStatsPayload{
+			Method: stats.Method,
+			Count:  stats.Count,
+			Errors: stats.Errors,
+			P50Ms:  float64(stats.P50) / float64(time.Millisecond),
+			P95Ms:  float64(stats.P95) / float64(time.Millisecond),
+		}
+		if stats.Count > 0 {
+			entry.ErrorRate = float64(stats.Errors) / float64(stats.Count)
+		}
+		payload.RequestStats = append(payload.RequestStats, entry)
+	}
+	if status.LastError != nil {
+		payload.LastError = status.LastError.Error()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(payload); err != nil {
+		hs.logger.Errorf("Failed to encode status: %v", err)
+	}
+}
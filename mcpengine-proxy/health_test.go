@@ -0,0 +1,121 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"go.uber.org/zap"
+)
+
+// ===== Health Endpoint Tests =====
+
+func TestHealthEndpoint(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	input := mcpenginetest.NewBlockReader(strings.NewReader(""))
+	engine, err := New(Config{
+		Endpoint:   ts.URL,
+		MCPPath:    "/mcp",
+		Logger:     NewZapLogger(zap.NewNop().Sugar()),
+		Input:      input,
+		HealthAddr: "127.0.0.1:0",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	var addr string
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if addr = engine.HealthAddr(); addr != "" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if addr == "" {
+		t.Fatal("Health listener never came up")
+	}
+
+	// /healthz flips to 200 once the engine is connected.
+	deadline = time.Now().Add(2 * time.Second)
+	var lastStatus int
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(fmt.Sprintf("http://%s/healthz", addr))
+		if err != nil {
+			t.Fatalf("Failed to probe healthz: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		lastStatus = resp.StatusCode
+		if lastStatus == http.StatusOK {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if lastStatus != http.StatusOK {
+		t.Fatalf("Expected healthz 200 once connected, got %d", lastStatus)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/status", addr))
+	if err != nil {
+		t.Fatalf("Failed to fetch status: %v", err)
+	}
+	defer resp.Body.Close()
+	var payload statusPayload
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if payload.State != StateConnected {
+		t.Errorf("Expected connected state, got %q", payload.State)
+	}
+	if payload.POSTPath != "/mcp" {
+		t.Errorf("Expected the POST path in status, got %q", payload.POSTPath)
+	}
+	if payload.Endpoint != ts.URL {
+		t.Errorf("Expected the endpoint in status, got %q", payload.Endpoint)
+	}
+}
+
+func TestHealthEndpoint_BadAddress(t *testing.T) {
+	input := mcpenginetest.NewBlockReader(strings.NewReader(""))
+	engine, err := New(Config{
+		Endpoint:   "http://localhost:8000",
+		MCPPath:    "/mcp",
+		Logger:     NewZapLogger(zap.NewNop().Sugar()),
+		Input:      input,
+		HealthAddr: "256.0.0.1:0",
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// An unusable health address shuts the engine down rather than running
+	// silently unprobeable.
+	done := make(chan struct{})
+	go func() {
+		engine.Start(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Engine did not exit on an unusable health address")
+	}
+}
@@ -0,0 +1,66 @@
+package mcpengine
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+// HTTP2Mode selects HTTP/2 behavior for the outbound POST/SSE connections.
+// The default negotiates the protocol via ALPN like any Go client; the
+// explicit modes pin HTTP/2 so many concurrent requests multiplex over one
+// upstream connection.
+type HTTP2Mode string
+
+const (
+	// HTTP2Default negotiates HTTP/2 over TLS when the server offers it.
+	HTTP2Default HTTP2Mode = ""
+	// HTTP2Force requires HTTP/2 over TLS, failing instead of silently
+	// downgrading to HTTP/1.1.
+	HTTP2Force HTTP2Mode = "force"
+	// HTTP2H2C speaks plaintext HTTP/2 with prior knowledge, for internal
+	// deployments that terminate TLS elsewhere.
+	HTTP2H2C HTTP2Mode = "h2c"
+)
+
+// validate rejects unknown modes.
+func (m HTTP2Mode) validate() error {
+	switch m {
+	case HTTP2Default, HTTP2Force, HTTP2H2C:
+		return nil
+	}
+	return fmt.Errorf("unknown HTTP2 mode %q (expected one of: %s, %s)", m, HTTP2Force, HTTP2H2C)
+}
+
+// configureHTTP2 swaps the client's transport for an explicit HTTP/2 one.
+// The http2 transport has no proxy support, which Validate has already
+// ruled out.
+func configureHTTP2(client *http.Client, mode HTTP2Mode, tlsCfg *TLSClientConfig) error {
+	switch mode {
+	case HTTP2Force:
+		transport := &http2.Transport{}
+		if tlsCfg != nil {
+			tlsConfig, err := tlsCfg.build()
+			if err != nil {
+				return err
+			}
+			transport.TLSClientConfig = tlsConfig
+		}
+		client.Transport = transport
+	case HTTP2H2C:
+		client.Transport = &http2.Transport{
+			AllowHTTP: true,
+			// Prior-knowledge h2c: dial plain TCP where the transport
+			// would normally expect TLS.
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}
+	}
+	return nil
+}
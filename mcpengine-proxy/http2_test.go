@@ -0,0 +1,70 @@
+package mcpengine
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"go.uber.org/zap"
+)
+
+// ===== HTTP/2 Tests =====
+
+func TestConfigureHTTP2_H2C(t *testing.T) {
+	// An h2c server only answers prior-knowledge HTTP/2; the handler
+	// reports the negotiated protocol.
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	})
+	ts := httptest.NewServer(h2c.NewHandler(handler, &http2.Server{}))
+	defer ts.Close()
+
+	client, err := NewHTTPClient("", nil)
+	if err != nil {
+		t.Fatalf("Failed to build client: %v", err)
+	}
+	if err := configureHTTP2(client, HTTP2H2C, nil); err != nil {
+		t.Fatalf("Failed to configure h2c: %v", err)
+	}
+
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "HTTP/2.0" {
+		t.Errorf("Expected an HTTP/2 request, server saw %q", body)
+	}
+}
+
+func TestHTTP2Mode_Validation(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	if err := (Config{
+		Endpoint: "http://localhost:8000", MCPPath: "/mcp",
+		Transport: TransportHTTP, Logger: logger,
+		HTTP2: "spdy",
+	}).Validate(); err == nil {
+		t.Error("Expected an error for an unknown HTTP2 mode")
+	}
+	if err := (Config{
+		Endpoint: "http://localhost:8000", MCPPath: "/mcp",
+		Transport: TransportHTTP, Logger: logger,
+		HTTP2:    HTTP2H2C,
+		ProxyURL: "http://proxy.corp:3128",
+	}).Validate(); err == nil {
+		t.Error("Expected an error combining HTTP2 with a proxy")
+	}
+	if err := (Config{
+		Endpoint: "http://localhost:8000", MCPPath: "/mcp",
+		Transport: TransportHTTP, Logger: logger,
+		HTTP2: HTTP2Force,
+	}).Validate(); err != nil {
+		t.Errorf("Expected the force mode to validate, got %v", err)
+	}
+}
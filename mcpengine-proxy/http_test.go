@@ -6,11 +6,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
 )
 
 // RequestData holds details about each HTTP request received by the test server.
@@ -55,14 +57,12 @@ func TestHTTPPostSender_WritesMessages(t *testing.T) {
 	// Send endpoint
 	endpointChan <- "/test-endpoint"
 
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 	client := &http.Client{Timeout: 2 * time.Second}
 	auth := NewAuthManager(nil, logger)
 
 	// Set token
-	auth.tokenMutex.Lock()
-	auth.accessToken = "test-token"
-	auth.tokenMutex.Unlock()
+	auth.setToken(&oauth2.Token{AccessToken: "test-token"})
 
 	sender := NewHTTPPostSender(client, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
 
@@ -94,7 +94,7 @@ func TestHTTPPostSender_Cancellation(t *testing.T) {
 	inputChan := make(chan string)
 	outputChan := make(chan string)
 
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 	client := &http.Client{}
 	auth := NewAuthManager(nil, logger)
 
@@ -128,7 +128,7 @@ func TestHTTPPostSender_InvalidURL(t *testing.T) {
 	// Send invalid URL
 	endpointChan <- ":\\invalid"
 
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 	client := &http.Client{}
 	auth := NewAuthManager(nil, logger)
 
@@ -155,7 +155,7 @@ func TestHTTPPostSender_HTTPError(t *testing.T) {
 	// Set up message
 	inputChan <- "test message"
 
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	// Create client that always returns an error
 	client := &http.Client{
@@ -196,7 +196,7 @@ func TestHTTPPostSender_UnexpectedStatusCode(t *testing.T) {
 	endpointChan <- "/api"
 	inputChan <- "test message"
 
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 	client := &http.Client{}
 	auth := NewAuthManager(nil, logger)
 
@@ -213,3 +213,643 @@ func TestHTTPPostSender_UnexpectedStatusCode(t *testing.T) {
 
 	// Success is that no crash occurred and execution reaches here
 }
+
+func TestHTTPPostSender_SynthesizesErrorOnNetworkFailure(t *testing.T) {
+	// A failed POST must answer the request with a JSON-RPC error instead
+	// of leaving the client waiting.
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+
+	endpointChan <- "/api"
+	inputChan <- `{"jsonrpc": "2.0", "id": 5, "method": "tools/call"}`
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	client := &http.Client{
+		Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, fmt.Errorf("simulated network error")
+		}),
+	}
+	auth := NewAuthManager(nil, logger)
+	sender := NewHTTPPostSender(client, "http://localhost:1", endpointChan, inputChan, outputChan, auth, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	select {
+	case got := <-outputChan:
+		if !strings.Contains(got, `"id":5`) {
+			t.Errorf("Error response should carry the request id, got %q", got)
+		}
+		if !strings.Contains(got, "failed to deliver message") {
+			t.Errorf("Expected a delivery error, got %q", got)
+		}
+		if !strings.Contains(got, "simulated network error") {
+			t.Errorf("Error data should include the cause, got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No error response was synthesized")
+	}
+}
+
+func TestHTTPPostSender_SynthesizesErrorOnUnexpectedStatus(t *testing.T) {
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	endpointChan <- "/api"
+	inputChan <- `{"jsonrpc": "2.0", "id": 6, "method": "tools/list"}`
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := NewAuthManager(nil, logger)
+	sender := NewHTTPPostSender(&http.Client{}, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	select {
+	case got := <-outputChan:
+		if !strings.Contains(got, `"id":6`) {
+			t.Errorf("Error response should carry the request id, got %q", got)
+		}
+		if !strings.Contains(got, `"status":500`) {
+			t.Errorf("Error data should include the HTTP status, got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No error response was synthesized")
+	}
+}
+
+func TestHTTPPostSender_CustomHeaders(t *testing.T) {
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+
+	headerChan := make(chan http.Header, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerChan <- r.Header.Clone()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	endpointChan <- "/api"
+	inputChan <- `{"jsonrpc": "2.0", "id": 8, "method": "ping"}`
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := NewAuthManager(nil, logger)
+	sender := NewHTTPPostSender(&http.Client{}, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
+	sender.SetHeaders(map[string]string{
+		"X-Api-Key":  "secret",
+		"User-Agent": "mcpengine-test",
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	select {
+	case headers := <-headerChan:
+		if got := headers.Get("X-Api-Key"); got != "secret" {
+			t.Errorf("Expected X-Api-Key %q, got %q", "secret", got)
+		}
+		if got := headers.Get("User-Agent"); got != "mcpengine-test" {
+			t.Errorf("Expected User-Agent %q, got %q", "mcpengine-test", got)
+		}
+		if got := headers.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Custom headers must not displace Content-Type, got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No request was received")
+	}
+}
+
+// ===== HTTP Client Proxy Tests =====
+
+func TestNewHTTPClient_ExplicitProxy(t *testing.T) {
+	proxiedChan := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A plain-HTTP request through a proxy arrives with an absolute URI.
+		proxiedChan <- r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	client, err := NewHTTPClient(proxy.URL, nil)
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+
+	resp, err := client.Get("http://mcp-server.invalid/sse")
+	if err != nil {
+		t.Fatalf("Request through proxy failed: %v", err)
+	}
+	resp.Body.Close()
+
+	select {
+	case got := <-proxiedChan:
+		if got != "http://mcp-server.invalid/sse" {
+			t.Errorf("Expected proxied request for %q, got %q", "http://mcp-server.invalid/sse", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Proxy did not receive the request")
+	}
+}
+
+func TestNewHTTPClient_InvalidProxy(t *testing.T) {
+	if _, err := NewHTTPClient("://bad", nil); err == nil {
+		t.Error("Expected an error for an invalid proxy URL")
+	}
+}
+
+// ===== Expiry Preflight Tests =====
+
+// expiringAuthenticator is an Authenticator whose credential starts out
+// expired; Refresh swaps in a fresh token.
+type expiringAuthenticator struct {
+	mu       sync.Mutex
+	token    string
+	expired  bool
+	refreshs int
+}
+
+func (e *expiringAuthenticator) HandleChallenge(ctx context.Context, resp *http.Response) (string, func(), error) {
+	return "", func() {}, fmt.Errorf("no challenge handling in test")
+}
+
+func (e *expiringAuthenticator) Token() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.token
+}
+
+func (e *expiringAuthenticator) Refresh(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.refreshs++
+	e.token = "fresh-token"
+	e.expired = false
+	return nil
+}
+
+func (e *expiringAuthenticator) TokenExpired() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.expired
+}
+
+func TestHTTPPostSender_ProactiveRefresh(t *testing.T) {
+	authHeaders := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	endpointChan <- "/mcp"
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := &expiringAuthenticator{token: "stale-token", expired: true}
+	sender := NewHTTPPostSender(&http.Client{Timeout: 2 * time.Second}, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	select {
+	case header := <-authHeaders:
+		if header != "Bearer fresh-token" {
+			t.Errorf("Expected the refreshed token on the wire, got %q", header)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server did not receive the request")
+	}
+	auth.mu.Lock()
+	defer auth.mu.Unlock()
+	if auth.refreshs != 1 {
+		t.Errorf("Expected exactly one proactive refresh, got %d", auth.refreshs)
+	}
+}
+
+func TestStreamableHTTPSender_ProactiveRefresh(t *testing.T) {
+	authHeaders := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	endpointChan <- "/mcp"
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := &expiringAuthenticator{token: "stale-token", expired: true}
+	sender := NewStreamableHTTPSender(&http.Client{Timeout: 2 * time.Second}, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	select {
+	case header := <-authHeaders:
+		if header != "Bearer fresh-token" {
+			t.Errorf("Expected the refreshed token on the wire, got %q", header)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server did not receive the request")
+	}
+}
+
+// ===== Concurrent POST Tests =====
+
+func TestHTTPPostSender_ConcurrentPostsUnblockSlowRequests(t *testing.T) {
+	// The first request blocks until the second arrives, which only a
+	// concurrent sender can deliver.
+	release := make(chan struct{})
+	done := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"id": 1`) {
+			select {
+			case <-release:
+			case <-time.After(3 * time.Second):
+			}
+		} else {
+			close(release)
+		}
+		done <- string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 2)
+	outputChan := make(chan string, 2)
+	endpointChan <- "/mcp"
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "slow"}`
+	inputChan <- `{"jsonrpc": "2.0", "id": 2, "method": "fast"}`
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(&http.Client{Timeout: 5 * time.Second}, ts.URL, endpointChan, inputChan, outputChan, NewAuthManager(nil, logger), logger)
+	sender.SetPostConcurrency(2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Requests did not proceed concurrently")
+		}
+	}
+}
+
+func TestHTTPPostSender_MethodOrderingSerializesSameMethod(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 4)
+	outputChan := make(chan string, 4)
+	endpointChan <- "/mcp"
+	for i := 1; i <= 4; i++ {
+		inputChan <- fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "method": "tools/call"}`, i)
+	}
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(&http.Client{Timeout: 5 * time.Second}, ts.URL, endpointChan, inputChan, outputChan, NewAuthManager(nil, logger), logger)
+	sender.SetPostConcurrency(4)
+	sender.SetPostOrdering(PostOrderingMethod)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sender.Run(ctx, cancel); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxInFlight != 1 {
+		t.Errorf("Expected same-method calls serialized, saw %d in flight", maxInFlight)
+	}
+}
+
+func TestPostConcurrency_ConfigValidation(t *testing.T) {
+	base := Config{
+		Endpoint: "http://localhost:8080",
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+	}
+
+	cfg := base
+	cfg.PostOrdering = PostOrderingMethod
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected PostOrdering without PostConcurrency rejected")
+	}
+
+	cfg = base
+	cfg.PostConcurrency = 4
+	cfg.ParkOnAuth = true
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected PostConcurrency with ParkOnAuth rejected")
+	}
+
+	cfg = base
+	cfg.PostConcurrency = 4
+	cfg.PostOrdering = PostOrderingGlobal
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid concurrency config, got %v", err)
+	}
+}
+
+func TestHTTPPostSender_WaitsForFreshEndpointAfterAuth(t *testing.T) {
+	paths := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	endpointChan := make(chan string, 1)
+	endpointChan <- "/messages/old?session_id=abc"
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(ts.Client(), ts.URL, endpointChan, inputChan, outputChan,
+		NewAuthManager(nil, logger), logger)
+	sender.SetParkOnAuth(true)
+	sender.SetAwaitEndpointOnAuth(true)
+	// A request parked by a 401 before the auth flow completed.
+	sender.authPending = true
+	sender.parked = []string{`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+	time.Sleep(100 * time.Millisecond)
+
+	// Auth completes, and the re-subscribed SSE stream announces the new
+	// session's endpoint; the replay must target it, not the old session.
+	sender.authDone <- struct{}{}
+	endpointChan <- "/messages/new?session_id=def"
+
+	select {
+	case path := <-paths:
+		if path != "/messages/new" {
+			t.Errorf("Expected the parked request replayed to the fresh endpoint, got %q", path)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Parked request was never replayed")
+	}
+}
+
+func TestHTTPPostSender_ResolveEndpoint(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(&http.Client{}, "http://mcp.example.com:8080",
+		nil, nil, nil, NewAuthManager(nil, logger), logger)
+	sender.SetAllowedEndpointHosts([]string{"sessions.example.com"})
+
+	testCases := []struct {
+		name     string
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "relative path joins the configured host",
+			endpoint: "/messages/abc?session_id=1",
+			want:     "http://mcp.example.com:8080/messages/abc?session_id=1",
+		},
+		{
+			name:     "absolute URL on the configured host",
+			endpoint: "http://mcp.example.com:8080/messages/abc",
+			want:     "http://mcp.example.com:8080/messages/abc",
+		},
+		{
+			name:     "absolute URL on an allowlisted host",
+			endpoint: "https://sessions.example.com/messages/abc",
+			want:     "https://sessions.example.com/messages/abc",
+		},
+		{
+			name:     "absolute URL on an unknown host",
+			endpoint: "https://evil.example.net/messages/abc",
+			wantErr:  true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := sender.resolveEndpoint(tc.endpoint)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveEndpoint returned error: %v", err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got.String())
+			}
+		})
+	}
+}
+
+func TestAllowedEndpointHosts_ConfigValidation(t *testing.T) {
+	cfg := Config{
+		Endpoint:             "http://localhost:8080",
+		MCPPath:              "/mcp",
+		Logger:               NewZapLogger(zap.NewNop().Sugar()),
+		AllowedEndpointHosts: []string{"other.example.com"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected AllowedEndpointHosts on the http transport rejected")
+	}
+}
+
+func TestHTTPPostSender_FollowsSameOriginRedirect(t *testing.T) {
+	received := make(chan RequestData, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/messages/old" {
+			http.Redirect(w, r, "/messages/new", http.StatusTemporaryRedirect)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- RequestData{
+			Body:       string(body),
+			AuthHeader: r.Header.Get("Authorization"),
+			URL:        r.URL.Path,
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	client, err := NewHTTPClient("", nil)
+	if err != nil {
+		t.Fatalf("NewHTTPClient returned error: %v", err)
+	}
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	endpointChan := make(chan string, 1)
+	endpointChan <- "/messages/old"
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := NewAuthManager(nil, logger)
+	auth.setToken(&oauth2.Token{AccessToken: "test-token"})
+	sender := NewHTTPPostSender(client, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	msg := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	inputChan <- msg
+	select {
+	case req := <-received:
+		if req.URL != "/messages/new" {
+			t.Errorf("Expected the redirect followed to /messages/new, got %q", req.URL)
+		}
+		if req.AuthHeader != "Bearer test-token" {
+			t.Errorf("Expected the Authorization header preserved, got %q", req.AuthHeader)
+		}
+		if req.Body != msg {
+			t.Errorf("Expected the body re-sent on redirect, got %q", req.Body)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Redirected request never arrived")
+	}
+}
+
+func TestHTTPClient_RefusesCrossOriginRedirect(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Cross-origin redirect target should never be contacted")
+	}))
+	defer other.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/messages", http.StatusTemporaryRedirect)
+	}))
+	defer ts.Close()
+
+	client, err := NewHTTPClient("", nil)
+	if err != nil {
+		t.Fatalf("NewHTTPClient returned error: %v", err)
+	}
+	resp, err := client.Post(ts.URL+"/messages", "application/json", strings.NewReader("{}"))
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected the cross-origin redirect to fail")
+	}
+	if !strings.Contains(err.Error(), "cross-origin redirect") {
+		t.Errorf("Expected a descriptive cross-origin error, got %v", err)
+	}
+}
+
+func TestHTTPPostSender_RetriesAfterRateLimit(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	done := make(chan struct{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		done <- struct{}{}
+	}))
+	defer ts.Close()
+
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	endpointChan := make(chan string, 1)
+	endpointChan <- "/messages"
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(ts.Client(), ts.URL, endpointChan, inputChan, outputChan,
+		NewAuthManager(nil, logger), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	start := time.Now()
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	select {
+	case <-done:
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("Expected the retry delayed by Retry-After, took %v", elapsed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Rate-limited request was never retried")
+	}
+	select {
+	case msg := <-outputChan:
+		t.Errorf("Transparent retry must not surface an error, got %q", msg)
+	default:
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	header := func(value string) *http.Response {
+		resp := &http.Response{Header: http.Header{}}
+		if value != "" {
+			resp.Header.Set("Retry-After", value)
+		}
+		return resp
+	}
+
+	if got := retryAfterDelay(header("30"), logger); got != 30*time.Second {
+		t.Errorf("Expected 30s for delta-seconds, got %v", got)
+	}
+	if got := retryAfterDelay(header(""), logger); got != rateLimitDefaultDelay {
+		t.Errorf("Expected the default delay for a missing header, got %v", got)
+	}
+	if got := retryAfterDelay(header("not-a-delay"), logger); got != rateLimitDefaultDelay {
+		t.Errorf("Expected the default delay for an unparseable header, got %v", got)
+	}
+	if got := retryAfterDelay(header("86400"), logger); got != rateLimitMaxDelay {
+		t.Errorf("Expected the delay capped, got %v", got)
+	}
+	at := time.Now().Add(20 * time.Second).UTC().Format(http.TimeFormat)
+	if got := retryAfterDelay(header(at), logger); got < 15*time.Second || got > 20*time.Second {
+		t.Errorf("Expected an HTTP-date delay near 20s, got %v", got)
+	}
+}
@@ -0,0 +1,48 @@
+package mcpengine
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// upstreamErrorBodyLimit caps how much of an upstream error body is
+// embedded in the JSON-RPC error data.
+const upstreamErrorBodyLimit = 4 * 1024
+
+// httpStatusError maps an upstream HTTP error status to a JSON-RPC error
+// code and message. Statuses with a useful distinction for clients get
+// their own code; everything else keeps the generic unexpected-status one.
+func httpStatusError(status int) (int, string) {
+	switch {
+	case status == http.StatusBadRequest:
+		return upstreamBadRequestCode, "server rejected the request as malformed"
+	case status == http.StatusNotFound:
+		return upstreamNotFoundCode, "server does not recognize the endpoint or session"
+	case status == http.StatusConflict:
+		return upstreamConflictCode, "server reported a conflicting request"
+	case status >= 500:
+		return upstreamServerErrorCode, "server failed to process the request"
+	}
+	return unexpectedStatusErrorCode, "server returned an unexpected status"
+}
+
+// upstreamErrorString builds the JSON-RPC error answering a request that
+// drew an HTTP error from the server. The server's own error body is
+// embedded in the data — as JSON when it parses, as truncated text
+// otherwise — so the client sees what actually went wrong instead of a
+// bare status code.
+func upstreamErrorString(id json.RawMessage, resp *http.Response, logger Logger) string {
+	code, message := httpStatusError(resp.StatusCode)
+	data := map[string]interface{}{"status": resp.StatusCode}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, upstreamErrorBodyLimit))
+	if trimmed := bytes.TrimSpace(body); err == nil && len(trimmed) > 0 {
+		if json.Valid(trimmed) {
+			data["body"] = json.RawMessage(trimmed)
+		} else {
+			data["body"] = string(trimmed)
+		}
+	}
+	return jsonRPCErrorString(id, code, message, data, logger)
+}
@@ -0,0 +1,83 @@
+package mcpengine
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// ===== Upstream HTTP Error Mapping Tests =====
+
+func TestHTTPStatusError_DistinctCodes(t *testing.T) {
+	testCases := []struct {
+		status int
+		code   int
+	}{
+		{http.StatusBadRequest, upstreamBadRequestCode},
+		{http.StatusNotFound, upstreamNotFoundCode},
+		{http.StatusConflict, upstreamConflictCode},
+		{http.StatusInternalServerError, upstreamServerErrorCode},
+		{http.StatusBadGateway, upstreamServerErrorCode},
+		{http.StatusTeapot, unexpectedStatusErrorCode},
+	}
+	for _, tc := range testCases {
+		if code, _ := httpStatusError(tc.status); code != tc.code {
+			t.Errorf("Status %d: expected code %d, got %d", tc.status, tc.code, code)
+		}
+	}
+}
+
+func TestUpstreamErrorString_EmbedsBody(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	resp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Body:       io.NopCloser(strings.NewReader(`{"error": "missing field params.name"}`)),
+	}
+	errStr := upstreamErrorString(json.RawMessage(`1`), resp, logger)
+
+	var envelope struct {
+		ID    int `json:"id"`
+		Error struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Data    struct {
+				Status int             `json:"status"`
+				Body   json.RawMessage `json:"body"`
+			} `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(errStr), &envelope); err != nil {
+		t.Fatalf("Error string is not valid JSON: %v", err)
+	}
+	if envelope.ID != 1 {
+		t.Errorf("Expected the request id echoed, got %d", envelope.ID)
+	}
+	if envelope.Error.Code != upstreamBadRequestCode {
+		t.Errorf("Expected code %d, got %d", upstreamBadRequestCode, envelope.Error.Code)
+	}
+	if envelope.Error.Data.Status != http.StatusBadRequest {
+		t.Errorf("Expected the status in data, got %d", envelope.Error.Data.Status)
+	}
+	if !strings.Contains(string(envelope.Error.Data.Body), "missing field params.name") {
+		t.Errorf("Expected the server's error body embedded, got %s", envelope.Error.Data.Body)
+	}
+}
+
+func TestUpstreamErrorString_TruncatesTextBodies(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	resp := &http.Response{
+		StatusCode: http.StatusInternalServerError,
+		Body:       io.NopCloser(strings.NewReader(strings.Repeat("stack frame\n", 10_000))),
+	}
+	errStr := upstreamErrorString(json.RawMessage(`2`), resp, logger)
+	if len(errStr) > upstreamErrorBodyLimit+1024 {
+		t.Errorf("Expected the embedded body truncated, error is %d bytes", len(errStr))
+	}
+	if !strings.Contains(errStr, "stack frame") {
+		t.Error("Expected part of the text body embedded")
+	}
+}
@@ -0,0 +1,103 @@
+package mcpengine
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPTuning adjusts the HTTP transport the engine builds, for deployments
+// whose throughput or latency profile the defaults don't fit — many
+// concurrent POSTs to one host, satellite links, aggressive middleboxes.
+// Zero values keep Go's defaults.
+type HTTPTuning struct {
+	// DialTimeout bounds establishing a new TCP connection. Go's default
+	// is 30 seconds.
+	DialTimeout time.Duration
+	// KeepAlive sets the interval between TCP keep-alive probes on new
+	// connections; negative disables them. Go's default is 15 seconds.
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake. Go's default is 10
+	// seconds.
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout bounds the wait for a response's headers
+	// after the request is fully written. It does not limit reading the
+	// body, so SSE streams are unaffected. Go applies no limit by
+	// default.
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout is how long an idle connection stays pooled before
+	// closing. Go's default is 90 seconds.
+	IdleConnTimeout time.Duration
+	// MaxIdleConns caps the pool across all hosts. Go's default is 100.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost caps the pool per host — the knob that matters
+	// under PostConcurrency, where Go's default of 2 churns connections.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost caps total connections per host, dialing included.
+	// Go applies no limit by default.
+	MaxConnsPerHost int
+}
+
+// validate rejects values that would silently misconfigure the transport.
+func (t *HTTPTuning) validate() error {
+	if t == nil {
+		return nil
+	}
+	for name, d := range map[string]time.Duration{
+		"DialTimeout":           t.DialTimeout,
+		"TLSHandshakeTimeout":   t.TLSHandshakeTimeout,
+		"ResponseHeaderTimeout": t.ResponseHeaderTimeout,
+		"IdleConnTimeout":       t.IdleConnTimeout,
+	} {
+		if d < 0 {
+			return fmt.Errorf("HTTPTuning.%s must not be negative, got %v", name, d)
+		}
+	}
+	for name, n := range map[string]int{
+		"MaxIdleConns":        t.MaxIdleConns,
+		"MaxIdleConnsPerHost": t.MaxIdleConnsPerHost,
+		"MaxConnsPerHost":     t.MaxConnsPerHost,
+	} {
+		if n < 0 {
+			return fmt.Errorf("HTTPTuning.%s must not be negative, got %d", name, n)
+		}
+	}
+	return nil
+}
+
+// apply overlays the set options onto transport, leaving unset ones at the
+// transport's existing values.
+func (t *HTTPTuning) apply(transport *http.Transport) {
+	if t == nil {
+		return
+	}
+	if t.DialTimeout > 0 || t.KeepAlive != 0 {
+		// Mirror net/http's default dialer settings for whichever knob
+		// stays unset.
+		dialer := &net.Dialer{Timeout: 30 * time.Second}
+		if t.DialTimeout > 0 {
+			dialer.Timeout = t.DialTimeout
+		}
+		dialer.KeepAlive = t.KeepAlive
+		transport.DialContext = dialer.DialContext
+	}
+	if t.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = t.TLSHandshakeTimeout
+	}
+	if t.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = t.ResponseHeaderTimeout
+	}
+	if t.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = t.IdleConnTimeout
+	}
+	if t.MaxIdleConns > 0 {
+		transport.MaxIdleConns = t.MaxIdleConns
+	}
+	if t.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+	}
+	if t.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = t.MaxConnsPerHost
+	}
+}
@@ -0,0 +1,79 @@
+package mcpengine
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== HTTP Tuning Tests =====
+
+func TestHTTPTuning_AppliesSetOptions(t *testing.T) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tuning := &HTTPTuning{
+		DialTimeout:           5 * time.Second,
+		TLSHandshakeTimeout:   3 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+		IdleConnTimeout:       2 * time.Minute,
+		MaxIdleConns:          200,
+		MaxIdleConnsPerHost:   32,
+		MaxConnsPerHost:       64,
+	}
+	tuning.apply(transport)
+
+	if transport.DialContext == nil {
+		t.Error("Expected a custom dialer installed")
+	}
+	if transport.TLSHandshakeTimeout != 3*time.Second {
+		t.Errorf("Expected a 3s handshake timeout, got %v", transport.TLSHandshakeTimeout)
+	}
+	if transport.ResponseHeaderTimeout != 20*time.Second {
+		t.Errorf("Expected a 20s response header timeout, got %v", transport.ResponseHeaderTimeout)
+	}
+	if transport.IdleConnTimeout != 2*time.Minute {
+		t.Errorf("Expected a 2m idle timeout, got %v", transport.IdleConnTimeout)
+	}
+	if transport.MaxIdleConns != 200 || transport.MaxIdleConnsPerHost != 32 || transport.MaxConnsPerHost != 64 {
+		t.Errorf("Expected pool sizes applied, got %d/%d/%d",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.MaxConnsPerHost)
+	}
+}
+
+func TestHTTPTuning_ZeroValuesKeepDefaults(t *testing.T) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	idle := transport.IdleConnTimeout
+	handshake := transport.TLSHandshakeTimeout
+
+	var tuning *HTTPTuning
+	tuning.apply(transport)
+	(&HTTPTuning{}).apply(transport)
+
+	if transport.IdleConnTimeout != idle || transport.TLSHandshakeTimeout != handshake {
+		t.Error("Expected untouched defaults for zero-valued tuning")
+	}
+}
+
+func TestHTTPTuning_ConfigValidation(t *testing.T) {
+	cfg := Config{
+		Endpoint:   "http://localhost:8080",
+		MCPPath:    "/mcp",
+		Logger:     NewZapLogger(zap.NewNop().Sugar()),
+		HTTPTuning: &HTTPTuning{DialTimeout: -time.Second},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative DialTimeout rejected")
+	}
+
+	cfg.HTTPTuning = &HTTPTuning{MaxIdleConnsPerHost: -1}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative MaxIdleConnsPerHost rejected")
+	}
+
+	// A negative KeepAlive is meaningful: it disables keep-alive probes.
+	cfg.HTTPTuning = &HTTPTuning{KeepAlive: -1}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a negative KeepAlive accepted, got %v", err)
+	}
+}
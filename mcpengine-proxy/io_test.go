@@ -37,7 +37,7 @@ func TestFileReader_ReadsLines(t *testing.T) {
 	defer tmpFile.Close()
 
 	outputChan := make(chan string, 10)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	fr := NewFileReader(tmpFile, outputChan, logger)
 
@@ -67,7 +67,7 @@ func TestFileReader_EmptyFile(t *testing.T) {
 	defer tmpFile.Close()
 
 	outputChan := make(chan string, 10)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	fr := NewFileReader(tmpFile, outputChan, logger)
 
@@ -101,7 +101,7 @@ func TestFileReader_Cancellation(t *testing.T) {
 	defer tmpFile.Close()
 
 	outputChan := make(chan string, 1000)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 	fr := NewFileReader(tmpFile, outputChan, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -128,7 +128,7 @@ func TestFileReader_FileError(t *testing.T) {
 	os.Remove(tmpName) // Remove file to ensure error
 
 	outputChan := make(chan string, 10)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	// Open non-existent file to cause error
 	badFile, _ := os.Open(tmpName)
@@ -159,7 +159,7 @@ func TestOutputProxy_WritesMessages(t *testing.T) {
 	}
 	close(inputChan)
 
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 	proxy := NewOutputProxy(tmpFile, inputChan, logger)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -186,7 +186,7 @@ func TestOutputProxy_WriteFails(t *testing.T) {
 	tmpFile.Close() // Close file to cause write errors
 
 	inputChan := make(chan string, 1)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	// Send a message
 	inputChan <- "test message"
@@ -211,7 +211,7 @@ func TestOutputProxy_Cancellation(t *testing.T) {
 
 	// Create an input channel that never closes
 	inputChan := make(chan string)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 	proxy := NewOutputProxy(tmpFile, inputChan, logger)
 
 	// Create a context that is canceled after a short delay
@@ -232,7 +232,7 @@ func TestOutputProxy_ChannelClosedWhileBlocked(t *testing.T) {
 	defer tmpFile.Close()
 
 	inputChan := make(chan string)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 	proxy := NewOutputProxy(tmpFile, inputChan, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -268,7 +268,7 @@ func TestOutputProxy_FlushAfterEachMessage(t *testing.T) {
 	defer tmpFile.Close()
 
 	inputChan := make(chan string, 3)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 	proxy := NewOutputProxy(tmpFile, inputChan, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -307,3 +307,212 @@ func TestOutputProxy_FlushAfterEachMessage(t *testing.T) {
 		t.Errorf("Expected file to contain 'second message', got: %q", got)
 	}
 }
+
+func TestOutputProxy_MirrorsMessages(t *testing.T) {
+	// Messages should be copied to every registered mirror writer.
+	tmpFile := createTempFile(t, "outputproxy_mirror", "")
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	messages := []string{"one", "two"}
+	inputChan := make(chan string, len(messages))
+	for _, msg := range messages {
+		inputChan <- msg
+	}
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	proxy := NewOutputProxy(tmpFile, inputChan, logger)
+
+	var mirror1, mirror2 bytes.Buffer
+	proxy.AddMirror(&mirror1)
+	proxy.AddMirror(&mirror2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := proxy.Run(ctx, cancel); err != nil {
+		t.Fatalf("OutputProxy Run returned error: %v", err)
+	}
+
+	expected := strings.Join(messages, "\n") + "\n"
+	if got := mirror1.String(); got != expected {
+		t.Errorf("Mirror 1: expected %q, got %q", expected, got)
+	}
+	if got := mirror2.String(); got != expected {
+		t.Errorf("Mirror 2: expected %q, got %q", expected, got)
+	}
+
+	// The primary writer should still get everything.
+	data, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read temp file: %v", err)
+	}
+	if got := string(data); got != expected {
+		t.Errorf("Primary: expected %q, got %q", expected, got)
+	}
+}
+
+// failingWriter always errors, to simulate a broken mirror.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestOutputProxy_MirrorFailureDoesNotStopProxy(t *testing.T) {
+	tmpFile := createTempFile(t, "outputproxy_mirror_fail", "")
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	inputChan := make(chan string, 1)
+	inputChan <- "still delivered"
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	proxy := NewOutputProxy(tmpFile, inputChan, logger)
+	proxy.AddMirror(failingWriter{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := proxy.Run(ctx, cancel); err != nil {
+		t.Fatalf("OutputProxy Run returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		t.Fatalf("Failed to read temp file: %v", err)
+	}
+	if got := string(data); !strings.Contains(got, "still delivered") {
+		t.Errorf("Expected primary output despite mirror failure, got: %q", got)
+	}
+}
+
+func TestFileReader_FollowContinuesAfterEOF(t *testing.T) {
+	// In follow mode the reader must keep going at EOF and pick up data
+	// appended later, like tail -F.
+	tmpFile := createTempFile(t, "filereader_follow", "first\n")
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	outputChan := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	fr := NewFileReader(tmpFile, outputChan, logger)
+	fr.SetFollow(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fr.Run(ctx, cancel)
+
+	select {
+	case line := <-outputChan:
+		if line != "first" {
+			t.Fatalf("Expected %q, got %q", "first", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for first line")
+	}
+
+	// Append another line after the reader has hit EOF.
+	appender, err := os.OpenFile(tmpFile.Name(), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("Failed to reopen temp file: %v", err)
+	}
+	if _, err := appender.WriteString("second\n"); err != nil {
+		t.Fatalf("Failed to append to temp file: %v", err)
+	}
+	appender.Close()
+
+	select {
+	case line := <-outputChan:
+		if line != "second" {
+			t.Fatalf("Expected %q, got %q", "second", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Follow mode did not pick up appended data")
+	}
+}
+
+func TestFileReader_FollowRespectsCancellation(t *testing.T) {
+	tmpFile := createTempFile(t, "filereader_follow_cancel", "")
+	defer os.Remove(tmpFile.Name())
+	defer tmpFile.Close()
+
+	outputChan := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	fr := NewFileReader(tmpFile, outputChan, logger)
+	fr.SetFollow(true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fr.Run(ctx, cancel)
+	}()
+
+	// Give the reader time to reach EOF and start polling, then cancel.
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-errCh:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Following FileReader did not stop after cancellation")
+	}
+}
+
+func TestFileReader_LargeLineWithinLimit(t *testing.T) {
+	// Lines over bufio.Scanner's 64KB default must flow through; large tool
+	// results depend on the raised cap.
+	largeLine := strings.Repeat("x", 100*1024)
+	reader := strings.NewReader(largeLine + "\n")
+	outputChan := make(chan string, 1)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	fr := NewFileReader(reader, outputChan, logger)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go fr.Run(ctx, cancel)
+
+	select {
+	case got := <-outputChan:
+		if got != largeLine {
+			t.Errorf("Large line was corrupted: got %d bytes, want %d", len(got), len(largeLine))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Large line was not forwarded")
+	}
+}
+
+func TestFileReader_OversizedLineEmitsError(t *testing.T) {
+	reader := strings.NewReader(strings.Repeat("x", 1024) + "\n")
+	outputChan := make(chan string, 1)
+	errorChan := make(chan string, 1)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	fr := NewFileReader(reader, outputChan, logger)
+	fr.SetMaxMessageSize(100)
+	fr.SetErrorChan(errorChan)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go fr.Run(ctx, cancel)
+
+	select {
+	case got := <-errorChan:
+		if !strings.Contains(got, "exceeds the maximum size") {
+			t.Errorf("Expected a size-limit error, got %q", got)
+		}
+		if !strings.Contains(got, `"max_bytes":100`) {
+			t.Errorf("Error data should include the cap, got %q", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No error was emitted for the oversized line")
+	}
+}
@@ -0,0 +1,158 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// keepalivePingIDPrefix marks the ids of pings the proxy itself injects, so
+// their responses can be recognized and swallowed before reaching the
+// client.
+const keepalivePingIDPrefix = "mcpengine-keepalive-"
+
+// KeepaliveWorker detects silently dead upstreams. A broken SSE stream or
+// TCP peer can stop delivering without an error, leaving the proxy waiting
+// forever; the worker watches inbound traffic and, after a configurable idle
+// period, injects an MCP ping request. A ping that goes unanswered past the
+// timeout deems the connection dead: the client is notified and the engine
+// shuts down so its supervisor can restart it, rather than wedging
+// indefinitely.
+type KeepaliveWorker struct {
+	interval time.Duration // Ping after this much inbound silence.
+	timeout  time.Duration // Dead once a ping is unanswered this long.
+	sendChan chan string   // The transport's input, where pings are injected.
+	notifier *Notifier
+	logger   Logger
+
+	mu           sync.Mutex
+	lastActivity time.Time
+	awaitingID   string
+	pingSent     time.Time
+	seq          int
+}
+
+// NewKeepaliveWorker constructs a KeepaliveWorker. A timeout of zero falls
+// back to the interval itself.
+func NewKeepaliveWorker(interval, timeout time.Duration, sendChan chan string, logger Logger) *KeepaliveWorker {
+	if timeout <= 0 {
+		timeout = interval
+	}
+	return &KeepaliveWorker{
+		interval: interval,
+		timeout:  timeout,
+		sendChan: sendChan,
+		logger:   logger,
+	}
+}
+
+// SetNotifier attaches a Notifier that tells the client when the connection
+// is deemed dead. Must be called before Run.
+func (ka *KeepaliveWorker) SetNotifier(n *Notifier) {
+	ka.notifier = n
+}
+
+// observeInbound is the inbound middleware: every server message counts as
+// activity, and responses to the worker's own pings are swallowed so the
+// client never sees answers to requests it didn't make.
+func (ka *KeepaliveWorker) observeInbound(msg string) (string, error) {
+	ka.mu.Lock()
+	ka.lastActivity = time.Now()
+	awaiting := ka.awaitingID
+	ka.mu.Unlock()
+
+	if awaiting == "" {
+		return msg, nil
+	}
+	var envelope struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(msg), &envelope); err != nil {
+		return msg, nil
+	}
+	var id string
+	if err := json.Unmarshal(envelope.ID, &id); err != nil || id != awaiting {
+		return msg, nil
+	}
+	ka.mu.Lock()
+	ka.awaitingID = ""
+	ka.mu.Unlock()
+	ka.logger.Debugw("Keepalive ping answered", "id", id)
+	return "", fmt.Errorf("keepalive ping response consumed")
+}
+
+// checkInterval is how often the worker evaluates idle and ping deadlines.
+func (ka *KeepaliveWorker) checkInterval() time.Duration {
+	interval := ka.interval / 4
+	if interval > time.Second {
+		interval = time.Second
+	}
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	return interval
+}
+
+// Run watches for inbound silence, pings after the idle interval, and shuts
+// the engine down when a ping goes unanswered past the timeout.
+func (ka *KeepaliveWorker) Run(ctx context.Context, cancel context.CancelFunc) error {
+	ka.logger.Debug("Starting KeepaliveWorker")
+	ka.mu.Lock()
+	ka.lastActivity = time.Now()
+	ka.mu.Unlock()
+
+	ticker := time.NewTicker(ka.checkInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			ka.logger.Info("KeepaliveWorker canceled")
+			return ctx.Err()
+		case <-ticker.C:
+			now := time.Now()
+			ka.mu.Lock()
+			awaiting := ka.awaitingID
+			pingSent := ka.pingSent
+			idle := now.Sub(ka.lastActivity)
+			ka.mu.Unlock()
+
+			if awaiting != "" {
+				if now.Sub(pingSent) < ka.timeout {
+					continue
+				}
+				err := fmt.Errorf("upstream deemed dead: keepalive ping unanswered after %v", ka.timeout)
+				ka.logger.Errorw("Keepalive failed; shutting down", "err", err)
+				ka.notifier.Notify("error", "Upstream connection is not responding; shutting down", map[string]interface{}{
+					"idle":    idle.String(),
+					"timeout": ka.timeout.String(),
+				})
+				cancel()
+				return err
+			}
+			if idle < ka.interval {
+				continue
+			}
+			ka.sendPing(now)
+		}
+	}
+}
+
+// sendPing injects one MCP ping request into the transport's input. A full
+// channel skips this attempt; the next tick retries.
+func (ka *KeepaliveWorker) sendPing(now time.Time) {
+	ka.mu.Lock()
+	defer ka.mu.Unlock()
+	ka.seq++
+	id := fmt.Sprintf("%s%d", keepalivePingIDPrefix, ka.seq)
+	ping := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %q, "method": "ping"}`, id)
+	select {
+	case ka.sendChan <- ping:
+		ka.awaitingID = id
+		ka.pingSent = now
+		ka.logger.Debugw("Sent keepalive ping", "id", id)
+	default:
+		ka.logger.Warn("Transport input full; skipping keepalive ping")
+	}
+}
@@ -0,0 +1,167 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Keepalive Tests =====
+
+func TestKeepalive_PingsAfterIdle(t *testing.T) {
+	sendChan := make(chan string, 10)
+	keepalive := NewKeepaliveWorker(50*time.Millisecond, time.Minute, sendChan, NewZapLogger(zap.NewNop().Sugar()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go keepalive.Run(ctx, cancel)
+
+	select {
+	case msg := <-sendChan:
+		var ping struct {
+			ID     string `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal([]byte(msg), &ping); err != nil {
+			t.Fatalf("Ping is not valid JSON: %v", err)
+		}
+		if ping.Method != "ping" {
+			t.Errorf("Expected method ping, got %q", ping.Method)
+		}
+		if !strings.HasPrefix(ping.ID, keepalivePingIDPrefix) {
+			t.Errorf("Expected a keepalive id, got %q", ping.ID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No ping was sent after the idle interval")
+	}
+}
+
+func TestKeepalive_ActivityDefersPing(t *testing.T) {
+	sendChan := make(chan string, 10)
+	keepalive := NewKeepaliveWorker(100*time.Millisecond, time.Minute, sendChan, NewZapLogger(zap.NewNop().Sugar()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go keepalive.Run(ctx, cancel)
+
+	// Steady inbound traffic keeps resetting the idle clock.
+	for i := 0; i < 10; i++ {
+		if _, err := keepalive.observeInbound(`{"jsonrpc":"2.0","method":"notifications/progress"}`); err != nil {
+			t.Fatalf("Ordinary message was dropped: %v", err)
+		}
+		time.Sleep(30 * time.Millisecond)
+	}
+	select {
+	case msg := <-sendChan:
+		t.Fatalf("Ping sent despite steady traffic: %s", msg)
+	default:
+	}
+}
+
+func TestKeepalive_ResponseConsumedAndConnectionStaysUp(t *testing.T) {
+	sendChan := make(chan string, 10)
+	keepalive := NewKeepaliveWorker(40*time.Millisecond, 200*time.Millisecond, sendChan, NewZapLogger(zap.NewNop().Sugar()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- keepalive.Run(ctx, cancel) }()
+
+	// Answer each ping like a live server would.
+	answered := 0
+	deadline := time.After(500 * time.Millisecond)
+	for answered < 3 {
+		select {
+		case msg := <-sendChan:
+			var ping struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal([]byte(msg), &ping); err != nil {
+				t.Fatalf("Ping is not valid JSON: %v", err)
+			}
+			response := fmt.Sprintf(`{"jsonrpc":"2.0","id":%q,"result":{}}`, ping.ID)
+			if _, err := keepalive.observeInbound(response); err == nil {
+				t.Error("Expected the ping response to be consumed")
+			}
+			answered++
+		case err := <-done:
+			t.Fatalf("Worker exited while pings were being answered: %v", err)
+		case <-deadline:
+			t.Fatalf("Only %d pings were answered before the deadline", answered)
+		}
+	}
+	if ctx.Err() != nil {
+		t.Error("Context cancelled despite answered pings")
+	}
+}
+
+func TestKeepalive_UnansweredPingShutsDown(t *testing.T) {
+	sendChan := make(chan string, 10)
+	keepalive := NewKeepaliveWorker(30*time.Millisecond, 60*time.Millisecond, sendChan, NewZapLogger(zap.NewNop().Sugar()))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- keepalive.Run(ctx, cancel) }()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "deemed dead") {
+			t.Errorf("Expected a dead-connection error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Worker did not shut down on an unanswered ping")
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Context was not cancelled on an unanswered ping")
+	}
+}
+
+func TestKeepalive_PassesUnrelatedResponses(t *testing.T) {
+	sendChan := make(chan string, 10)
+	keepalive := NewKeepaliveWorker(time.Minute, time.Minute, sendChan, NewZapLogger(zap.NewNop().Sugar()))
+	keepalive.awaitingID = keepalivePingIDPrefix + "1"
+
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":1,"result":{}}`,
+		`{"jsonrpc":"2.0","id":"client-5","result":{}}`,
+		`{"jsonrpc":"2.0","method":"notifications/progress"}`,
+		`not json`,
+	} {
+		got, err := keepalive.observeInbound(msg)
+		if err != nil {
+			t.Errorf("Message %q was dropped: %v", msg, err)
+		}
+		if got != msg {
+			t.Errorf("Message %q was altered to %q", msg, got)
+		}
+	}
+}
+
+func TestKeepalive_ConfigValidation(t *testing.T) {
+	base := Config{
+		Endpoint: "http://localhost:1234",
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+	}
+
+	cfg := base
+	cfg.KeepaliveInterval = -time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for a negative KeepaliveInterval")
+	}
+	cfg = base
+	cfg.KeepaliveTimeout = time.Second
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for KeepaliveTimeout without KeepaliveInterval")
+	}
+	cfg = base
+	cfg.KeepaliveInterval = 30 * time.Second
+	cfg.KeepaliveTimeout = 10 * time.Second
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid keepalive config, got %v", err)
+	}
+}
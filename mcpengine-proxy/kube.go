@@ -0,0 +1,127 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// KubeForwardConfig describes a Kubernetes target the engine reaches
+// through `kubectl port-forward`, sparing users from juggling the forward
+// manually. The engine derives its endpoint from the forwarded local port
+// and keeps the forward alive across pod restarts.
+//   - Target:    What to forward to, e.g. "service/mcp" or "pod/mcp-0".
+//     Required.
+//   - Port:      Remote port the MCP server listens on. Required.
+//   - LocalPort: Local port to bind. Zero picks a free port.
+//   - Context:   Kube context to use. Empty uses the current one.
+//   - Namespace: Namespace of the target. Empty uses the context default.
+//   - Binary:    The kubectl-compatible CLI to invoke. Defaults to
+//     "kubectl".
+type KubeForwardConfig struct {
+	Target    string
+	Port      int
+	LocalPort int
+	Context   string
+	Namespace string
+	Binary    string
+}
+
+// kubeRetryInterval is how long the forwarder waits before re-establishing
+// a forward that dropped (e.g. because the pod restarted).
+const kubeRetryInterval = 2 * time.Second
+
+// args assembles the port-forward invocation for the given local port.
+func (kc KubeForwardConfig) args(localPort int) []string {
+	args := []string{"port-forward"}
+	if kc.Context != "" {
+		args = append(args, "--context", kc.Context)
+	}
+	if kc.Namespace != "" {
+		args = append(args, "-n", kc.Namespace)
+	}
+	return append(args, kc.Target, fmt.Sprintf("%d:%d", localPort, kc.Port))
+}
+
+// binary returns the CLI to invoke.
+func (kc KubeForwardConfig) binary() string {
+	if kc.Binary != "" {
+		return kc.Binary
+	}
+	return "kubectl"
+}
+
+// pickFreePort binds port 0 to learn a free local port and releases it for
+// the forwarder to claim.
+func pickFreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// kubeForwarder keeps a kubectl port-forward running as a worker. When the
+// forward drops it is re-established after kubeRetryInterval, so a pod
+// restart shows up as a brief delivery failure instead of a dead proxy.
+type kubeForwarder struct {
+	cfg       KubeForwardConfig
+	localPort int
+	logger    Logger
+}
+
+// newKubeForwarder constructs a forwarder bound to the given local port.
+func newKubeForwarder(cfg KubeForwardConfig, localPort int, logger Logger) *kubeForwarder {
+	return &kubeForwarder{
+		cfg:       cfg,
+		localPort: localPort,
+		logger:    logger,
+	}
+}
+
+// Run maintains the port-forward until the context is cancelled. A forward
+// that can never be established (e.g. the CLI is missing) shuts the engine
+// down.
+func (kf *kubeForwarder) Run(ctx context.Context, cancel context.CancelFunc) error {
+	args := kf.cfg.args(kf.localPort)
+	for {
+		cmd := exec.CommandContext(ctx, kf.cfg.binary(), args...)
+		stderr, err := cmd.StderrPipe()
+		if err == nil {
+			go func() {
+				scanner := bufio.NewScanner(stderr)
+				for scanner.Scan() {
+					kf.logger.Warnw("Port-forward stderr", "line", scanner.Text())
+				}
+			}()
+		}
+		if err := cmd.Start(); err != nil {
+			cancel()
+			return fmt.Errorf("kube: failed to start %q: %w", kf.cfg.binary(), err)
+		}
+		kf.logger.Infow("Port-forward established",
+			"target", kf.cfg.Target, "local_port", kf.localPort, "pid", cmd.Process.Pid)
+
+		err = cmd.Wait()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		kf.logger.Warnw("Port-forward exited; re-establishing",
+			"err", err, "retry_in", kubeRetryInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(kubeRetryInterval):
+		}
+	}
+}
+
+// kubeEndpoint derives the engine endpoint for a forwarded local port.
+func kubeEndpoint(localPort int) string {
+	return "http://127.0.0.1:" + strconv.Itoa(localPort)
+}
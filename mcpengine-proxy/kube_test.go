@@ -0,0 +1,148 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Kube Port-Forward Tests =====
+
+func TestKubeForwardConfig_Args(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   KubeForwardConfig
+		expected []string
+	}{
+		{
+			name:     "target and port",
+			config:   KubeForwardConfig{Target: "service/mcp", Port: 8000},
+			expected: []string{"port-forward", "service/mcp", "9000:8000"},
+		},
+		{
+			name: "context and namespace",
+			config: KubeForwardConfig{
+				Target:    "pod/mcp-0",
+				Port:      8000,
+				Context:   "staging",
+				Namespace: "tools",
+			},
+			expected: []string{
+				"port-forward", "--context", "staging", "-n", "tools",
+				"pod/mcp-0", "9000:8000",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.args(9000); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNew_KubeDerivesEndpoint(t *testing.T) {
+	engine, err := New(Config{
+		Kube:      &KubeForwardConfig{Target: "service/mcp", Port: 8000, LocalPort: 9137},
+		Transport: TransportHTTP,
+		MCPPath:   "/mcp",
+		Logger:    NewZapLogger(zap.NewNop().Sugar()),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if engine.endpoint != "http://127.0.0.1:9137" {
+		t.Errorf("Expected the forwarded endpoint, got %q", engine.endpoint)
+	}
+
+	// Port 0 picks a free local port.
+	engine, err = New(Config{
+		Kube:      &KubeForwardConfig{Target: "service/mcp", Port: 8000},
+		Transport: TransportHTTP,
+		MCPPath:   "/mcp",
+		Logger:    NewZapLogger(zap.NewNop().Sugar()),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if engine.kubeLocalPort == 0 || engine.endpoint == "http://127.0.0.1:0" {
+		t.Errorf("Expected a concrete local port, got %q", engine.endpoint)
+	}
+}
+
+func TestKubeForwarder_Reestablishes(t *testing.T) {
+	// A stub kubectl that records each invocation and exits immediately,
+	// standing in for a forward dropped by a pod restart.
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "invocations")
+	stub := filepath.Join(dir, "kubectl-stub")
+	script := fmt.Sprintf("#!/bin/sh\necho run >> %s\n", marker)
+	if err := os.WriteFile(stub, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write stub: %v", err)
+	}
+
+	forwarder := newKubeForwarder(KubeForwardConfig{
+		Target: "service/mcp",
+		Port:   8000,
+		Binary: stub,
+	}, 9000, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go forwarder.Run(ctx, cancel)
+
+	deadline := time.Now().Add(2*kubeRetryInterval + 2*time.Second)
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(marker)
+		if strings.Count(string(data), "run") >= 2 {
+			cancel()
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	t.Fatal("Forwarder did not re-establish after the forward dropped")
+}
+
+func TestConfigValidate_Kube(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	if err := (Config{
+		Kube:    &KubeForwardConfig{Port: 8000},
+		MCPPath: "/mcp", Transport: TransportHTTP, Logger: logger,
+	}).Validate(); err == nil {
+		t.Error("Expected an error for a missing target")
+	}
+	if err := (Config{
+		Kube:    &KubeForwardConfig{Target: "service/mcp"},
+		MCPPath: "/mcp", Transport: TransportHTTP, Logger: logger,
+	}).Validate(); err == nil {
+		t.Error("Expected an error for a missing port")
+	}
+	if err := (Config{
+		Kube:     &KubeForwardConfig{Target: "service/mcp", Port: 8000},
+		Endpoint: "http://localhost:8000",
+		MCPPath:  "/mcp", Transport: TransportHTTP, Logger: logger,
+	}).Validate(); err == nil {
+		t.Error("Expected an error for an explicit endpoint with Kube")
+	}
+	if err := (Config{
+		Kube:    &KubeForwardConfig{Target: "service/mcp", Port: 8000},
+		Command: []string{"cat"},
+		Logger:  logger,
+	}).Validate(); err == nil {
+		t.Error("Expected an error combining Kube with the stdio transport")
+	}
+	if err := (Config{
+		Kube:    &KubeForwardConfig{Target: "service/mcp", Port: 8000},
+		MCPPath: "/mcp", Transport: TransportHTTP, Logger: logger,
+	}).Validate(); err != nil {
+		t.Errorf("Expected a kube config to validate, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package mcpengine
+
+// LifecycleHooks carries optional callbacks that surface proxy state to
+// host applications (GUIs, tray apps). Nil fields are skipped. Callbacks
+// run synchronously on engine goroutines, so they must return quickly and
+// not block.
+type LifecycleHooks struct {
+	// OnConnected fires once when the engine is ready to deliver messages
+	// (the POST endpoint is known).
+	OnConnected func()
+	// OnEndpointReceived fires for every POST endpoint the server
+	// announces, including SSE reconnections.
+	OnEndpointReceived func(path string)
+	// OnAuthRequired fires when the server demands authentication and a
+	// URL must be visited by the user.
+	OnAuthRequired func(url string)
+	// OnAuthCompleted fires when an authentication flow finishes.
+	OnAuthCompleted func()
+	// OnDisconnected fires when the engine's workers have all exited.
+	OnDisconnected func()
+	// OnFatalError fires when the engine gives up, just before Start
+	// returns the error.
+	OnFatalError func(err error)
+}
+
+// SetLifecycleHooks registers the callbacks. Must be called before Start.
+func (mcp *MCPEngine) SetLifecycleHooks(hooks LifecycleHooks) {
+	mcp.hooks = hooks
+}
+
+// The fire helpers keep call sites free of nil checks; the zero value of
+// LifecycleHooks is safe to fire.
+
+func (h LifecycleHooks) connected() {
+	if h.OnConnected != nil {
+		h.OnConnected()
+	}
+}
+
+func (h LifecycleHooks) endpointReceived(path string) {
+	if h.OnEndpointReceived != nil {
+		h.OnEndpointReceived(path)
+	}
+}
+
+func (h LifecycleHooks) authRequired(url string) {
+	if h.OnAuthRequired != nil {
+		h.OnAuthRequired(url)
+	}
+}
+
+func (h LifecycleHooks) authCompleted() {
+	if h.OnAuthCompleted != nil {
+		h.OnAuthCompleted()
+	}
+}
+
+func (h LifecycleHooks) disconnected() {
+	if h.OnDisconnected != nil {
+		h.OnDisconnected()
+	}
+}
+
+func (h LifecycleHooks) fatalError(err error) {
+	if h.OnFatalError != nil {
+		h.OnFatalError(err)
+	}
+}
@@ -0,0 +1,257 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"go.uber.org/zap"
+)
+
+// ===== Lifecycle Hook Tests =====
+
+// hookRecorder collects fired lifecycle events for assertions.
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *hookRecorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *hookRecorder) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func (r *hookRecorder) waitFor(t *testing.T, event string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, got := range r.snapshot() {
+			if got == event {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Event %q did not fire; saw %v", event, r.snapshot())
+}
+
+func TestLifecycleHooks_EngineEvents(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	recorder := &hookRecorder{}
+	input := mcpenginetest.NewBlockReader(strings.NewReader(""))
+	engine, err := New(Config{
+		Endpoint: ts.URL,
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+		Input:    input,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	engine.SetLifecycleHooks(LifecycleHooks{
+		OnConnected:        func() { recorder.record("connected") },
+		OnEndpointReceived: func(path string) { recorder.record("endpoint:" + path) },
+		OnDisconnected:     func() { recorder.record("disconnected") },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		engine.Start(ctx)
+		close(done)
+	}()
+
+	recorder.waitFor(t, "endpoint:/mcp")
+	recorder.waitFor(t, "connected")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Engine did not stop after cancellation")
+	}
+	recorder.waitFor(t, "disconnected")
+}
+
+func TestLifecycleHooks_FatalError(t *testing.T) {
+	recorder := &hookRecorder{}
+	input := mcpenginetest.NewBlockReader(strings.NewReader(""))
+	engine, err := New(Config{
+		Endpoint:       "http://localhost:1",
+		Transport:      TransportSSE,
+		SSEPath:        "/sse",
+		Logger:         NewZapLogger(zap.NewNop().Sugar()),
+		Input:          input,
+		StartupTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	engine.SetLifecycleHooks(LifecycleHooks{
+		OnFatalError: func(err error) { recorder.record("fatal:" + err.Error()) },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := engine.Start(ctx); err == nil {
+		t.Fatal("Expected Start to fail on the startup deadline")
+	}
+	events := recorder.snapshot()
+	if len(events) != 1 || !strings.Contains(events[0], "startup deadline") {
+		t.Errorf("Expected a fatal error event, got %v", events)
+	}
+}
+
+// challengeAuthenticator is an Authenticator whose challenge hands back a
+// URL for the user, with an immediately-complete flow.
+type challengeAuthenticator struct{}
+
+func (challengeAuthenticator) HandleChallenge(ctx context.Context, resp *http.Response) (string, func(), error) {
+	return "https://auth.example.com/login", func() {}, nil
+}
+func (challengeAuthenticator) Token() string                     { return "" }
+func (challengeAuthenticator) Refresh(ctx context.Context) error { return fmt.Errorf("no refresh") }
+
+func TestLifecycleHooks_AuthEvents(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 2)
+	endpointChan <- "/mcp"
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	close(inputChan)
+
+	recorder := &hookRecorder{}
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(&http.Client{Timeout: 2 * time.Second}, ts.URL, endpointChan, inputChan, outputChan, challengeAuthenticator{}, logger)
+	sender.SetLifecycleHooks(LifecycleHooks{
+		OnAuthRequired:  func(url string) { recorder.record("required:" + url) },
+		OnAuthCompleted: func() { recorder.record("completed") },
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	recorder.waitFor(t, "required:https://auth.example.com/login")
+	recorder.waitFor(t, "completed")
+}
+
+// parkingAuthenticator holds its auth flow open until released, then mints
+// a token that subsequent requests carry.
+type parkingAuthenticator struct {
+	release chan struct{}
+	mu      sync.Mutex
+	token   string
+}
+
+func (a *parkingAuthenticator) HandleChallenge(ctx context.Context, resp *http.Response) (string, func(), error) {
+	return "https://auth.example.com/login", func() {
+		<-a.release
+		a.mu.Lock()
+		a.token = "parked-token"
+		a.mu.Unlock()
+	}, nil
+}
+
+func (a *parkingAuthenticator) Token() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.token
+}
+
+func (a *parkingAuthenticator) Refresh(ctx context.Context) error { return fmt.Errorf("no refresh") }
+
+func TestHTTPPostSender_ParksAndReplaysOnAuth(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	auth := &parkingAuthenticator{release: make(chan struct{})}
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 2)
+	outputChan := make(chan string, 4)
+	endpointChan <- "/mcp"
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(&http.Client{Timeout: 2 * time.Second}, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
+	sender.SetParkOnAuth(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`
+	// Let the first request hit the challenge, then queue one mid-flow.
+	time.Sleep(100 * time.Millisecond)
+	inputChan <- `{"jsonrpc": "2.0", "id": 2, "method": "ping"}`
+	time.Sleep(100 * time.Millisecond)
+
+	// While parked, the client sees neither a result nor a synthetic
+	// auth error.
+	select {
+	case msg := <-outputChan:
+		t.Fatalf("Expected nothing while parked, got %s", msg)
+	default:
+	}
+
+	close(auth.release)
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		mu.Lock()
+		count := len(received)
+		mu.Unlock()
+		if count >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Parked requests never replayed (got %d)", count)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(received[0], `"id": 1`) || !strings.Contains(received[1], `"id": 2`) {
+		t.Errorf("Requests replayed out of order: %v", received)
+	}
+	select {
+	case msg := <-outputChan:
+		t.Errorf("Expected no synthetic auth result after replay, got %s", msg)
+	default:
+	}
+}
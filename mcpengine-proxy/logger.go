@@ -0,0 +1,113 @@
+package mcpengine
+
+import (
+	"fmt"
+	"log/slog"
+
+	"go.uber.org/zap"
+)
+
+// Logger is the logging surface the engine needs, so embedders can plug in
+// whatever logging library they already use instead of being forced onto
+// zap. The method set mirrors zap's sugared logger — print-style, printf-
+// style and structured key/value variants per level — because that is what
+// the engine's workers call. NewZapLogger and NewSlogLogger adapt the two
+// common implementations.
+type Logger interface {
+	Debug(args ...interface{})
+	Info(args ...interface{})
+	Warn(args ...interface{})
+	Error(args ...interface{})
+	Debugf(template string, args ...interface{})
+	Infof(template string, args ...interface{})
+	Warnf(template string, args ...interface{})
+	Errorf(template string, args ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+	// With returns a child logger carrying the given key/value context on
+	// every subsequent entry.
+	With(keysAndValues ...interface{}) Logger
+}
+
+// zapLogger adapts a *zap.SugaredLogger to Logger. Only With needs
+// translating; the leveled methods line up exactly.
+type zapLogger struct {
+	s *zap.SugaredLogger
+}
+
+// NewZapLogger wraps a zap sugared logger in the Logger interface.
+func NewZapLogger(s *zap.SugaredLogger) Logger {
+	return zapLogger{s: s}
+}
+
+func (z zapLogger) Debug(args ...interface{}) { z.s.Debug(args...) }
+func (z zapLogger) Info(args ...interface{})  { z.s.Info(args...) }
+func (z zapLogger) Warn(args ...interface{})  { z.s.Warn(args...) }
+func (z zapLogger) Error(args ...interface{}) { z.s.Error(args...) }
+
+func (z zapLogger) Debugf(template string, args ...interface{}) { z.s.Debugf(template, args...) }
+func (z zapLogger) Infof(template string, args ...interface{})  { z.s.Infof(template, args...) }
+func (z zapLogger) Warnf(template string, args ...interface{})  { z.s.Warnf(template, args...) }
+func (z zapLogger) Errorf(template string, args ...interface{}) { z.s.Errorf(template, args...) }
+
+func (z zapLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	z.s.Debugw(msg, keysAndValues...)
+}
+func (z zapLogger) Infow(msg string, keysAndValues ...interface{}) { z.s.Infow(msg, keysAndValues...) }
+func (z zapLogger) Warnw(msg string, keysAndValues ...interface{}) { z.s.Warnw(msg, keysAndValues...) }
+func (z zapLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	z.s.Errorw(msg, keysAndValues...)
+}
+
+func (z zapLogger) With(keysAndValues ...interface{}) Logger {
+	return zapLogger{s: z.s.With(keysAndValues...)}
+}
+
+// slogLogger adapts a *slog.Logger to Logger. slog has no print- or
+// printf-style entry points, so those variants format the message up front.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger wraps a standard-library slog logger in the Logger
+// interface.
+func NewSlogLogger(l *slog.Logger) Logger {
+	return slogLogger{l: l}
+}
+
+func (s slogLogger) Debug(args ...interface{}) { s.l.Debug(fmt.Sprint(args...)) }
+func (s slogLogger) Info(args ...interface{})  { s.l.Info(fmt.Sprint(args...)) }
+func (s slogLogger) Warn(args ...interface{})  { s.l.Warn(fmt.Sprint(args...)) }
+func (s slogLogger) Error(args ...interface{}) { s.l.Error(fmt.Sprint(args...)) }
+
+func (s slogLogger) Debugf(template string, args ...interface{}) {
+	s.l.Debug(fmt.Sprintf(template, args...))
+}
+func (s slogLogger) Infof(template string, args ...interface{}) {
+	s.l.Info(fmt.Sprintf(template, args...))
+}
+func (s slogLogger) Warnf(template string, args ...interface{}) {
+	s.l.Warn(fmt.Sprintf(template, args...))
+}
+func (s slogLogger) Errorf(template string, args ...interface{}) {
+	s.l.Error(fmt.Sprintf(template, args...))
+}
+
+func (s slogLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	s.l.Debug(msg, keysAndValues...)
+}
+func (s slogLogger) Infow(msg string, keysAndValues ...interface{}) {
+	s.l.Info(msg, keysAndValues...)
+}
+func (s slogLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	s.l.Warn(msg, keysAndValues...)
+}
+func (s slogLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	s.l.Error(msg, keysAndValues...)
+}
+
+func (s slogLogger) With(keysAndValues ...interface{}) Logger {
+	return slogLogger{l: s.l.With(keysAndValues...)}
+}
@@ -0,0 +1,48 @@
+package mcpengine
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// ===== Logger Adapter Tests =====
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	logger := NewSlogLogger(slog.New(handler))
+
+	logger.Infow("request sent", "id", 7)
+	if got := buf.String(); !strings.Contains(got, "request sent") || !strings.Contains(got, "id=7") {
+		t.Errorf("Expected structured fields in output, got %q", got)
+	}
+
+	buf.Reset()
+	logger.Debugf("retry %d", 3)
+	if got := buf.String(); !strings.Contains(got, "retry 3") {
+		t.Errorf("Expected formatted message in output, got %q", got)
+	}
+
+	buf.Reset()
+	logger.With("worker", "sender").Warn("slow response")
+	got := buf.String()
+	if !strings.Contains(got, "worker=sender") || !strings.Contains(got, "slow response") {
+		t.Errorf("Expected With context in output, got %q", got)
+	}
+}
+
+func TestZapLogger_With(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	child := logger.With("worker", "sender")
+	if child == nil {
+		t.Fatal("Expected With to return a child logger")
+	}
+	// The adapter must not panic on any variant.
+	child.Debug("a")
+	child.Infof("%s", "b")
+	child.Errorw("c", "k", "v")
+}
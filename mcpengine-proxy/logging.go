@@ -0,0 +1,99 @@
+package mcpengine
+
+import (
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// LogConfig describes the logging sinks for the engine. Logs can go to
+// stderr, a file, or both simultaneously, each with its own level, so
+// interactive visibility and persistence no longer have to be traded off.
+//   - StderrLevel: Level for the stderr sink (zero value is Info).
+//   - FilePath:    Path of the log file. Empty disables the file sink.
+//   - FileLevel:   Level for the file sink (zero value is Info).
+//
+// Proxies run for weeks inside desktop clients, so the file sink can rotate
+// itself: setting any of the rotation fields switches the sink to a rotating
+// writer that renames the file aside once it exceeds FileMaxSizeMB and prunes
+// old rotations by count and age. All three at zero keep the plain
+// append-forever behavior.
+type LogConfig struct {
+	StderrLevel zapcore.Level
+	FilePath    string
+	FileLevel   zapcore.Level
+	// FileMaxSizeMB rotates the log file once it exceeds this size in
+	// megabytes (rotation's default is 100 when only the retention fields
+	// are set).
+	FileMaxSizeMB int
+	// FileMaxBackups keeps at most this many rotated files; zero keeps all.
+	FileMaxBackups int
+	// FileMaxAgeDays deletes rotated files older than this many days; zero
+	// keeps them forever.
+	FileMaxAgeDays int
+}
+
+// rotationEnabled reports whether any rotation field is set.
+func (cfg LogConfig) rotationEnabled() bool {
+	return cfg.FileMaxSizeMB > 0 || cfg.FileMaxBackups > 0 || cfg.FileMaxAgeDays > 0
+}
+
+// NewLogger builds a SugaredLogger that writes to stderr and, if FilePath is
+// set, also to that file with an independent level. It returns the logger and
+// a cleanup function that flushes and closes the sinks.
+func NewLogger(cfg LogConfig) (*zap.SugaredLogger, func(), error) {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	stderrCore := zapcore.NewCore(
+		zapcore.NewConsoleEncoder(encoderCfg),
+		zapcore.Lock(os.Stderr),
+		cfg.StderrLevel,
+	)
+
+	cores := []zapcore.Core{stderrCore}
+	cleanup := func() {}
+
+	if cfg.FilePath != "" && cfg.rotationEnabled() {
+		rotator := &lumberjack.Logger{
+			Filename:   cfg.FilePath,
+			MaxSize:    cfg.FileMaxSizeMB,
+			MaxBackups: cfg.FileMaxBackups,
+			MaxAge:     cfg.FileMaxAgeDays,
+		}
+		fileCore := zapcore.NewCore(
+			zapcore.NewJSONEncoder(encoderCfg),
+			zapcore.AddSync(rotator),
+			cfg.FileLevel,
+		)
+		cores = append(cores, fileCore)
+		cleanup = func() {
+			rotator.Close()
+		}
+	} else if cfg.FilePath != "" {
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file %s: %w", cfg.FilePath, err)
+		}
+		fileCore := zapcore.NewCore(
+			zapcore.NewJSONEncoder(encoderCfg),
+			zapcore.Lock(file),
+			cfg.FileLevel,
+		)
+		cores = append(cores, fileCore)
+		cleanup = func() {
+			file.Sync()
+			file.Close()
+		}
+	}
+
+	logger := zap.New(zapcore.NewTee(cores...))
+	flush := func() {
+		logger.Sync()
+		cleanup()
+	}
+	return logger.Sugar(), flush, nil
+}
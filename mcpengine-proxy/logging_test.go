@@ -0,0 +1,155 @@
+package mcpengine
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ===== Logging Tests =====
+
+func TestNewLogger_StderrOnly(t *testing.T) {
+	logger, flush, err := NewLogger(LogConfig{})
+	if err != nil {
+		t.Fatalf("NewLogger returned error: %v", err)
+	}
+	defer flush()
+
+	if logger == nil {
+		t.Fatal("Expected a logger, got nil")
+	}
+	// Should not panic when logging.
+	logger.Info("stderr-only logger works")
+}
+
+func TestNewLogger_WritesToFile(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "engine.log")
+
+	logger, flush, err := NewLogger(LogConfig{
+		StderrLevel: zapcore.ErrorLevel,
+		FilePath:    logPath,
+		FileLevel:   zapcore.DebugLevel,
+	})
+	if err != nil {
+		t.Fatalf("NewLogger returned error: %v", err)
+	}
+
+	logger.Debugw("debug message for file", "key", "value")
+	logger.Info("info message for file")
+	flush()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "debug message for file") {
+		t.Errorf("Expected file to contain debug message, got: %q", content)
+	}
+	if !strings.Contains(content, "info message for file") {
+		t.Errorf("Expected file to contain info message, got: %q", content)
+	}
+}
+
+func TestNewLogger_FileLevelFiltering(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "engine.log")
+
+	logger, flush, err := NewLogger(LogConfig{
+		FilePath:  logPath,
+		FileLevel: zapcore.WarnLevel,
+	})
+	if err != nil {
+		t.Fatalf("NewLogger returned error: %v", err)
+	}
+
+	logger.Info("filtered info message")
+	logger.Warn("kept warn message")
+	flush()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	content := string(data)
+	if strings.Contains(content, "filtered info message") {
+		t.Errorf("Expected info message to be filtered from file, got: %q", content)
+	}
+	if !strings.Contains(content, "kept warn message") {
+		t.Errorf("Expected warn message in file, got: %q", content)
+	}
+}
+
+func TestNewLogger_RotatingSinkWrites(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "engine.log")
+
+	logger, flush, err := NewLogger(LogConfig{
+		StderrLevel:    zapcore.ErrorLevel,
+		FilePath:       logPath,
+		FileLevel:      zapcore.DebugLevel,
+		FileMaxSizeMB:  1,
+		FileMaxBackups: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewLogger returned error: %v", err)
+	}
+
+	logger.Info("message through the rotating sink")
+	flush()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if !strings.Contains(string(data), "message through the rotating sink") {
+		t.Errorf("Expected the rotating sink to receive the message, got: %q", data)
+	}
+}
+
+func TestNewLogger_RotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "engine.log")
+
+	logger, flush, err := NewLogger(LogConfig{
+		StderrLevel:   zapcore.ErrorLevel,
+		FilePath:      logPath,
+		FileLevel:     zapcore.DebugLevel,
+		FileMaxSizeMB: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewLogger returned error: %v", err)
+	}
+
+	// Push well past one megabyte so the file must roll at least once.
+	filler := strings.Repeat("x", 1024)
+	for i := 0; i < 1500; i++ {
+		logger.Infow("fill", "i", i, "payload", filler)
+	}
+	flush()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read log dir: %v", err)
+	}
+	if len(entries) < 2 {
+		t.Errorf("Expected a rotated file beside the log, found %d files", len(entries))
+	}
+	info, err := os.Stat(logPath)
+	if err != nil {
+		t.Fatalf("Failed to stat log file: %v", err)
+	}
+	if info.Size() > 2<<20 {
+		t.Errorf("Expected the active file to stay near the cap, got %d bytes", info.Size())
+	}
+}
+
+func TestNewLogger_BadFilePath(t *testing.T) {
+	_, _, err := NewLogger(LogConfig{
+		FilePath: filepath.Join(t.TempDir(), "missing-dir", "engine.log"),
+	})
+	if err == nil {
+		t.Fatal("Expected error for unwritable log file path, got nil")
+	}
+}
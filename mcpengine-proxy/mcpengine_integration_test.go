@@ -1,6 +1,7 @@
 package mcpengine
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"io"
@@ -13,44 +14,17 @@ import (
 	"testing/iotest"
 	"time"
 
+	"mcpengine/mcpenginetest"
 	"mcpengine/testutil"
 
 	"github.com/r3labs/sse/v2"
 	"go.uber.org/zap"
 )
 
-// integrationSSEClient mocks the SSE client for integration testing
-type integrationSSEClient struct {
-	mu           sync.Mutex
-	subscribers  map[string]chan *sse.Event
-	triggerError bool
-}
-
-func newIntegrationSSEClient() *integrationSSEClient {
-	return &integrationSSEClient{
-		subscribers: make(map[string]chan *sse.Event),
-	}
-}
-
-func (c *integrationSSEClient) SubscribeChan(stream string, msgChan chan *sse.Event) error {
-	if c.triggerError {
-		return fmt.Errorf("simulated subscription error")
-	}
-
-	c.mu.Lock()
-	c.subscribers[stream] = msgChan
-	c.mu.Unlock()
-
-	return nil
-}
-
-func (c *integrationSSEClient) SendEvent(stream string, event *sse.Event) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	if ch, ok := c.subscribers[stream]; ok {
-		ch <- event
-	}
+// newIntegrationSSEClient mocks the SSE client for integration testing using
+// the public test harness.
+func newIntegrationSSEClient() *mcpenginetest.FakeSSEClient {
+	return mcpenginetest.NewFakeSSEClient()
 }
 
 // TestMCPEngine_Integration tests the entire engine working together
@@ -60,7 +34,7 @@ func TestMCPEngine_Integration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
-	sugarLogger := logger.Sugar()
+	sugarLogger := NewZapLogger(logger.Sugar())
 
 	// Write messages to the input file
 	messages := []string{
@@ -209,7 +183,7 @@ func TestMCPEngine_StressTest(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
-	sugarLogger := logger.Sugar()
+	sugarLogger := NewZapLogger(logger.Sugar())
 
 	const messageCount = 100
 	// Generate and write a large number of messages
@@ -328,7 +302,7 @@ func TestMCPEngine_WorkerError(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
-	sugarLogger := logger.Sugar()
+	sugarLogger := NewZapLogger(logger.Sugar())
 
 	// Create temp files for input and output
 	inputFile := testutil.CreateTempBlockReader(t, "")
@@ -343,7 +317,7 @@ func TestMCPEngine_WorkerError(t *testing.T) {
 
 	// Create a mock SSE client that errors on subscribe
 	sseClient := newIntegrationSSEClient()
-	sseClient.triggerError = true
+	sseClient.SubscribeErr = fmt.Errorf("simulated subscription error")
 
 	// Create the engine with mocked components
 	engine := &MCPEngine{
@@ -374,7 +348,7 @@ func TestMCPEngine_Shutdown(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
-	sugarLogger := logger.Sugar()
+	sugarLogger := NewZapLogger(logger.Sugar())
 
 	// Create temp files for input and output
 	inputFile := testutil.CreateTempBlockReader(t, "")
@@ -433,7 +407,7 @@ func TestMCPEngine_StdinExit(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to create logger: %v", err)
 	}
-	sugarLogger := logger.Sugar()
+	sugarLogger := NewZapLogger(logger.Sugar())
 
 	// Create temp files for input and output
 	inputFile := iotest.ErrReader(io.EOF)
@@ -476,3 +450,164 @@ func TestMCPEngine_StdinExit(t *testing.T) {
 		t.Fatal("Engine did not shut down within timeout")
 	}
 }
+
+// TestMCPEngine_StartupDeadline tests that Start fails fast when no endpoint
+// event ever arrives within the configured deadline.
+func TestMCPEngine_StartupDeadline(t *testing.T) {
+	sugarLogger := NewZapLogger(zap.NewNop().Sugar())
+
+	inputFile := testutil.CreateTempBlockReader(t, "")
+
+	// SSE client that subscribes successfully but never sends an endpoint.
+	sseClient := newIntegrationSSEClient()
+
+	engine := &MCPEngine{
+		endpoint:       "http://localhost:1",
+		inputFile:      inputFile,
+		outputFile:     io.Discard,
+		useSse:         true,
+		sseClient:      sseClient,
+		httpClient:     &http.Client{},
+		logger:         sugarLogger,
+		auth:           NewAuthManager(nil, sugarLogger),
+		startupTimeout: 100 * time.Millisecond,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- engine.Start(context.Background())
+	}()
+
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Fatal("Expected startup deadline error, got nil")
+		}
+		if !strings.Contains(err.Error(), "startup deadline") {
+			t.Errorf("Expected startup deadline error, got: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Engine did not fail within the startup deadline")
+	}
+}
+
+// TestMCPEngine_Ready tests that Ready fires once the POST endpoint is known.
+func TestMCPEngine_Ready(t *testing.T) {
+	sugarLogger := NewZapLogger(zap.NewNop().Sugar())
+
+	inputFile := testutil.CreateTempBlockReader(t, "")
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer mockServer.Close()
+
+	engine := &MCPEngine{
+		endpoint:   mockServer.URL,
+		inputFile:  inputFile,
+		outputFile: io.Discard,
+		useSse:     false,
+		mcpPath:    "/mcp",
+		httpClient: mockServer.Client(),
+		logger:     sugarLogger,
+		auth:       NewAuthManager(nil, sugarLogger),
+		readyChan:  make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	select {
+	case <-engine.Ready():
+		// Success - engine reported readiness.
+	case <-time.After(2 * time.Second):
+		t.Fatal("Engine did not report readiness")
+	}
+}
+
+// TestMCPEngine_CustomInputOutput verifies that an embedded engine can be
+// driven through Config.Input/Output instead of stdin and stdout.
+func TestMCPEngine_CustomInputOutput(t *testing.T) {
+	recording := `{"jsonrpc": "2.0", "id": 1, "result": "pong"}
+`
+	// The input blocks after EOF like a live stdin, so the engine keeps
+	// running until the test cancels it.
+	input := mcpenginetest.NewBlockReader(strings.NewReader(
+		`{"jsonrpc": "2.0", "id": 1, "method": "ping"}` + "\n"))
+	outputReader, outputWriter := io.Pipe()
+
+	engine, err := New(Config{
+		Logger:     NewZapLogger(zap.NewNop().Sugar()),
+		ReplayFrom: strings.NewReader(recording),
+		Input:      input,
+		Output:     outputWriter,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	lines := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		if scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `"result": "pong"`) {
+			t.Errorf("Expected the replayed response on the output writer, got %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No response arrived on the output writer")
+	}
+}
+
+// TestMCPEngine_StopAndWait verifies the engine works as a managed
+// component: Stop shuts it down without an external context, and Wait
+// reports the terminal error.
+func TestMCPEngine_StopAndWait(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	input := mcpenginetest.NewBlockReader(strings.NewReader(""))
+	engine, err := New(Config{
+		Endpoint: ts.URL,
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+		Input:    input,
+		Output:   io.Discard,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	go engine.Start(context.Background())
+
+	select {
+	case <-engine.Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Engine did not report readiness")
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := engine.Stop(stopCtx); err != nil {
+		t.Fatalf("Stop returned an error: %v", err)
+	}
+	// Stop is idempotent, and Wait reports the same outcome.
+	if err := engine.Stop(stopCtx); err != nil {
+		t.Fatalf("Second Stop returned an error: %v", err)
+	}
+	if err := engine.Wait(); err != nil {
+		t.Fatalf("Wait returned an error: %v", err)
+	}
+}
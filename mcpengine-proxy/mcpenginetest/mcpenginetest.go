@@ -0,0 +1,206 @@
+// Package mcpenginetest provides test doubles for applications that embed
+// mcpengine: a fake SSE client, a reader that blocks after EOF like a live
+// stdin, and a fake MCP server with pluggable method handlers. They are the
+// same doubles the engine's own tests use, exported so downstream users can
+// write integration tests without copying internals.
+package mcpenginetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/r3labs/sse/v2"
+)
+
+// FakeSSEClient implements the engine's SSE client interface in memory.
+// Tests push events with SendEvent; the engine receives them as if they
+// arrived on a live SSE stream.
+type FakeSSEClient struct {
+	mu          sync.Mutex
+	subscribers map[string]chan *sse.Event
+
+	// SubscribeErr, if set, is returned from SubscribeChan to simulate a
+	// failed subscription.
+	SubscribeErr error
+}
+
+// NewFakeSSEClient creates a FakeSSEClient with no subscribers.
+func NewFakeSSEClient() *FakeSSEClient {
+	return &FakeSSEClient{
+		subscribers: make(map[string]chan *sse.Event),
+	}
+}
+
+// SubscribeChan registers msgChan as the subscriber for stream.
+func (c *FakeSSEClient) SubscribeChan(stream string, msgChan chan *sse.Event) error {
+	if c.SubscribeErr != nil {
+		return c.SubscribeErr
+	}
+	c.mu.Lock()
+	c.subscribers[stream] = msgChan
+	c.mu.Unlock()
+	return nil
+}
+
+// SendEvent delivers event to the subscriber of stream, if any.
+func (c *FakeSSEClient) SendEvent(stream string, event *sse.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ch, ok := c.subscribers[stream]; ok {
+		ch <- event
+	}
+}
+
+// Subscribed reports whether a subscriber is registered for stream.
+func (c *FakeSSEClient) Subscribed(stream string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.subscribers[stream]
+	return ok
+}
+
+// BlockReader reads from the underlying Reader until EOF,
+// then blocks forever on subsequent reads — mimicking an interactive stdin
+// that has delivered all of its buffered input.
+type BlockReader struct {
+	r          io.Reader
+	reachedEOF bool
+	blockChan  chan struct{}
+}
+
+// NewBlockReader creates a new reader that will block after EOF.
+func NewBlockReader(r io.Reader) *BlockReader {
+	return &BlockReader{
+		r:          r,
+		reachedEOF: false,
+		blockChan:  make(chan struct{}), // Unbuffered channel that's never closed
+	}
+}
+
+func (r *BlockReader) Read(p []byte) (n int, err error) {
+	if r.reachedEOF {
+		// Block forever by trying to receive from a channel that will never send
+		<-r.blockChan
+		return 0, nil // This line is never reached
+	}
+
+	n, err = r.r.Read(p)
+	if err == io.EOF {
+		r.reachedEOF = true
+		return n, nil // Return the data but suppress the EOF
+	}
+	return n, err
+}
+
+// CreateTempBlockReader writes content to a temp file (removed on cleanup)
+// and returns a BlockReader over it.
+func CreateTempBlockReader(t *testing.T, content string) io.Reader {
+	t.Helper()
+	tmpFile, err := os.CreateTemp("", "mcpengine_input_*")
+	if err != nil {
+		t.Fatalf("Failed to create temporary file: %v", err)
+	}
+	t.Cleanup(func() {
+		err := os.Remove(tmpFile.Name())
+		if err != nil {
+			t.Errorf("Failed to remove temporary file: %v", err)
+		}
+	})
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		t.Fatalf("Failed to write to temporary file: %v", err)
+	}
+	if _, err := tmpFile.Seek(0, 0); err != nil {
+		t.Fatalf("Failed to reset temporary file: %v", err)
+	}
+	return NewBlockReader(tmpFile)
+}
+
+// HandlerFunc answers a single JSON-RPC method on a fake MCP server. It
+// returns the value placed in the response's result field.
+type HandlerFunc func(params json.RawMessage) (result interface{}, err error)
+
+// Server is a fake MCP server backed by httptest. Requests with a handler
+// registered for their method get a JSON-RPC response; notifications and
+// unhandled methods are acknowledged with 202 Accepted.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	handlers map[string]HandlerFunc
+	requests []string
+}
+
+// NewServer spins up a fake MCP server. It is shut down automatically when
+// the test finishes.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+	s := &Server{
+		handlers: make(map[string]HandlerFunc),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Handle registers fn as the responder for method.
+func (s *Server) Handle(method string, fn HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = fn
+}
+
+// Requests returns the raw bodies of every request received so far.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		ID     *json.RawMessage `json:"id"`
+		Method string           `json:"method"`
+		Params json.RawMessage  `json:"params"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, string(body))
+	handler, ok := s.handlers[req.Method]
+	s.mu.Unlock()
+
+	// Notifications and unhandled methods are accepted without a body.
+	if req.ID == nil || !ok {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	result, err := handler(req.Params)
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %s, "error": {"code": -32603, "message": %q}}`, *req.ID, err.Error())
+		return
+	}
+	resultData, err := json.Marshal(result)
+	if err != nil {
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %s, "error": {"code": -32603, "message": %q}}`, *req.ID, err.Error())
+		return
+	}
+	fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": %s, "result": %s}`, *req.ID, resultData)
+}
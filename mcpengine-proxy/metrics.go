@@ -0,0 +1,181 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultMetricsInterval is how often the collector logs its summary line.
+const defaultMetricsInterval = time.Minute
+
+// metricsMaxSamples caps the latency samples retained per method; once full,
+// new samples overwrite the oldest, so percentiles reflect recent traffic
+// and memory stays bounded on long-running sessions.
+const metricsMaxSamples = 512
+
+// MethodStats aggregates the outcomes of one JSON-RPC method: how many
+// requests were sent, how many were answered with an error, and latency
+// percentiles over the retained samples.
+type MethodStats struct {
+	Method string
+	Count  int
+	Errors int
+	P50    time.Duration
+	P95    time.Duration
+}
+
+// pendingCall remembers an in-flight request so the response can be matched
+// back to its method and start time.
+type pendingCall struct {
+	method string
+	start  time.Time
+}
+
+// methodRecord is the per-method accumulator behind MethodStats.
+type methodRecord struct {
+	count   int
+	errors  int
+	samples []time.Duration
+	next    int // Ring position once samples is full.
+}
+
+// MetricsCollector measures request-to-response latency and outcomes,
+// spliced into the pipeline as middleware the same way tracing is. It also
+// runs as a worker, logging a periodic summary so latency regressions show
+// up in plain log files without scraping the status endpoint.
+type MetricsCollector struct {
+	interval time.Duration
+	logger   Logger
+
+	mu        sync.Mutex
+	pending   map[string]pendingCall
+	perMethod map[string]*methodRecord
+}
+
+// NewMetricsCollector constructs a collector; interval is how often the
+// summary line is logged (zero uses the one-minute default).
+func NewMetricsCollector(interval time.Duration, logger Logger) *MetricsCollector {
+	if interval <= 0 {
+		interval = defaultMetricsInterval
+	}
+	return &MetricsCollector{
+		interval:  interval,
+		logger:    logger,
+		pending:   make(map[string]pendingCall),
+		perMethod: make(map[string]*methodRecord),
+	}
+}
+
+// recordOutbound is the outbound middleware: it starts the clock for every
+// id-carrying request. Messages flow through unchanged.
+func (mc *MetricsCollector) recordOutbound(msg string) (string, error) {
+	var envelope struct {
+		Method string          `json:"method"`
+		ID     json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(msg), &envelope); err != nil {
+		return msg, nil
+	}
+	if len(envelope.ID) == 0 || string(envelope.ID) == "null" || envelope.Method == "" {
+		return msg, nil
+	}
+	mc.mu.Lock()
+	mc.pending[string(envelope.ID)] = pendingCall{method: envelope.Method, start: time.Now()}
+	mc.mu.Unlock()
+	return msg, nil
+}
+
+// recordInbound is the inbound middleware: it stops the clock for the
+// response's id and folds the outcome into the method's record.
+func (mc *MetricsCollector) recordInbound(msg string) (string, error) {
+	var envelope struct {
+		ID    json.RawMessage `json:"id"`
+		Error *JSONRPCError   `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(msg), &envelope); err != nil {
+		return msg, nil
+	}
+	if len(envelope.ID) == 0 || string(envelope.ID) == "null" {
+		return msg, nil
+	}
+	now := time.Now()
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	call, ok := mc.pending[string(envelope.ID)]
+	if !ok {
+		return msg, nil
+	}
+	delete(mc.pending, string(envelope.ID))
+	record := mc.perMethod[call.method]
+	if record == nil {
+		record = &methodRecord{}
+		mc.perMethod[call.method] = record
+	}
+	record.count++
+	if envelope.Error != nil {
+		record.errors++
+	}
+	elapsed := now.Sub(call.start)
+	if len(record.samples) < metricsMaxSamples {
+		record.samples = append(record.samples, elapsed)
+	} else {
+		record.samples[record.next] = elapsed
+		record.next = (record.next + 1) % metricsMaxSamples
+	}
+	return msg, nil
+}
+
+// percentile returns the pct-th percentile of samples, which must be sorted.
+func percentile(sorted []time.Duration, pct int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[(len(sorted)-1)*pct/100]
+}
+
+// Snapshot returns the per-method statistics, sorted by method name.
+func (mc *MetricsCollector) Snapshot() []MethodStats {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	stats := make([]MethodStats, 0, len(mc.perMethod))
+	for method, record := range mc.perMethod {
+		sorted := append([]time.Duration{}, record.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		stats = append(stats, MethodStats{
+			Method: method,
+			Count:  record.count,
+			Errors: record.errors,
+			P50:    percentile(sorted, 50),
+			P95:    percentile(sorted, 95),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Method < stats[j].Method })
+	return stats
+}
+
+// Run logs the summary line on the configured interval until the context is
+// cancelled. Methods without traffic since startup are omitted.
+func (mc *MetricsCollector) Run(ctx context.Context, cancel context.CancelFunc) error {
+	ticker := time.NewTicker(mc.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			mc.logger.Info("MetricsCollector canceled")
+			return ctx.Err()
+		case <-ticker.C:
+			for _, stats := range mc.Snapshot() {
+				mc.logger.Infow("Request metrics",
+					"method", stats.Method,
+					"count", stats.Count,
+					"errors", stats.Errors,
+					"p50", stats.P50,
+					"p95", stats.P95,
+				)
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Metrics Tests =====
+
+func newTestCollector() *MetricsCollector {
+	return NewMetricsCollector(time.Minute, NewZapLogger(zap.NewNop().Sugar()))
+}
+
+func TestMetricsCollector_CountsAndErrors(t *testing.T) {
+	collector := newTestCollector()
+
+	for i := 1; i <= 3; i++ {
+		collector.recordOutbound(fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call"}`, i))
+	}
+	collector.recordOutbound(`{"jsonrpc":"2.0","id":10,"method":"resources/read"}`)
+
+	collector.recordInbound(`{"jsonrpc":"2.0","id":1,"result":{}}`)
+	collector.recordInbound(`{"jsonrpc":"2.0","id":2,"error":{"code":-32000,"message":"boom"}}`)
+	collector.recordInbound(`{"jsonrpc":"2.0","id":3,"result":{}}`)
+	collector.recordInbound(`{"jsonrpc":"2.0","id":10,"result":{}}`)
+
+	stats := collector.Snapshot()
+	if len(stats) != 2 {
+		t.Fatalf("Expected stats for two methods, got %d", len(stats))
+	}
+	// Snapshot sorts by method name.
+	read, call := stats[0], stats[1]
+	if call.Method != "tools/call" || read.Method != "resources/read" {
+		t.Fatalf("Unexpected methods: %q, %q", read.Method, call.Method)
+	}
+	if call.Count != 3 || call.Errors != 1 {
+		t.Errorf("Expected 3 calls with 1 error, got %d/%d", call.Count, call.Errors)
+	}
+	if read.Count != 1 || read.Errors != 0 {
+		t.Errorf("Expected 1 clean read, got %d/%d", read.Count, read.Errors)
+	}
+	if call.P50 < 0 || call.P95 < call.P50 {
+		t.Errorf("Expected ordered percentiles, got p50=%v p95=%v", call.P50, call.P95)
+	}
+}
+
+func TestMetricsCollector_IgnoresUnmatchedAndNotifications(t *testing.T) {
+	collector := newTestCollector()
+
+	// A notification carries no id and never becomes pending.
+	collector.recordOutbound(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	// A response nothing asked for (a server-initiated message) is ignored.
+	collector.recordInbound(`{"jsonrpc":"2.0","id":99,"result":{}}`)
+
+	if len(collector.pending) != 0 {
+		t.Errorf("Expected nothing pending, got %d", len(collector.pending))
+	}
+	if stats := collector.Snapshot(); len(stats) != 0 {
+		t.Errorf("Expected no stats, got %v", stats)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if got := percentile(sorted, 50); got != 5 {
+		t.Errorf("Expected p50 of 5, got %v", got)
+	}
+	if got := percentile(sorted, 95); got != 9 {
+		t.Errorf("Expected p95 of 9, got %v", got)
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("Expected 0 for no samples, got %v", got)
+	}
+}
+
+func TestMetricsCollector_SampleCap(t *testing.T) {
+	collector := newTestCollector()
+	for i := 0; i < metricsMaxSamples+100; i++ {
+		id := fmt.Sprintf("%d", i)
+		collector.recordOutbound(`{"jsonrpc":"2.0","id":` + id + `,"method":"tools/call"}`)
+		collector.recordInbound(`{"jsonrpc":"2.0","id":` + id + `,"result":{}}`)
+	}
+	stats := collector.Snapshot()
+	if stats[0].Count != metricsMaxSamples+100 {
+		t.Errorf("Expected the full count, got %d", stats[0].Count)
+	}
+	if got := len(collector.perMethod["tools/call"].samples); got != metricsMaxSamples {
+		t.Errorf("Expected samples capped at %d, got %d", metricsMaxSamples, got)
+	}
+}
+
+func TestEngineStatus_IncludesRequestStats(t *testing.T) {
+	collector := newTestCollector()
+	collector.recordOutbound(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	collector.recordInbound(`{"jsonrpc":"2.0","id":1,"result":{}}`)
+
+	engine := &MCPEngine{metrics: collector}
+	status := engine.Status()
+	if len(status.RequestStats) != 1 || status.RequestStats[0].Method != "ping" {
+		t.Errorf("Expected ping stats in the status snapshot, got %v", status.RequestStats)
+	}
+}
+
+func TestMetricsCollector_RunStopsOnCancel(t *testing.T) {
+	collector := NewMetricsCollector(10*time.Millisecond, NewZapLogger(zap.NewNop().Sugar()))
+	ctx, cancel := context.WithCancel(context.Background())
+	errChan := make(chan error, 1)
+	go func() { errChan <- collector.Run(ctx, cancel) }()
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-errChan:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Collector did not stop on cancel")
+	}
+}
@@ -0,0 +1,90 @@
+package mcpengine
+
+import (
+	"context"
+)
+
+// MessageMiddleware inspects one JSON-RPC message and returns the message
+// to forward in its place. Returning an error drops the message; returning
+// a different string rewrites it. Middleware runs on the worker goroutine,
+// so slow hooks throttle the direction they are attached to.
+type MessageMiddleware func(msg string) (string, error)
+
+// OnOutbound appends a middleware to the client-to-server chain, letting
+// library users rewrite, block, or annotate requests without forking the
+// workers. Middleware runs in registration order. Must be called before
+// Run.
+func (mcp *MCPEngine) OnOutbound(mw MessageMiddleware) {
+	mcp.outboundMiddleware = append(mcp.outboundMiddleware, mw)
+}
+
+// OnInbound appends a middleware to the server-to-client chain. Middleware
+// runs in registration order. Must be called before Run.
+func (mcp *MCPEngine) OnInbound(mw MessageMiddleware) {
+	mcp.inboundMiddleware = append(mcp.inboundMiddleware, mw)
+}
+
+// middlewareWorker applies a middleware chain to every message flowing from
+// its input to its output channel, spliced into the pipeline like the
+// request tracker.
+type middlewareWorker struct {
+	direction  string
+	chain      []MessageMiddleware
+	inputChan  chan string
+	outputChan chan string
+	logger     Logger
+}
+
+// newMiddlewareWorker constructs a middlewareWorker; direction is only used
+// for logging.
+func newMiddlewareWorker(direction string, chain []MessageMiddleware, inputChan, outputChan chan string, logger Logger) *middlewareWorker {
+	return &middlewareWorker{
+		direction:  direction,
+		chain:      chain,
+		inputChan:  inputChan,
+		outputChan: outputChan,
+		logger:     logger,
+	}
+}
+
+// Run forwards messages until the input channel closes or the context is
+// cancelled. On a closed input the output channel is closed too, so
+// downstream workers terminate; on cancellation it is left open, since
+// other workers may still hold it.
+func (mw *middlewareWorker) Run(ctx context.Context, cancel context.CancelFunc) error {
+	for {
+		select {
+		case <-ctx.Done():
+			mw.logger.Infof("Middleware worker (%s) canceled", mw.direction)
+			return ctx.Err()
+		case msg, ok := <-mw.inputChan:
+			if !ok {
+				mw.logger.Infof("Input channel closed, terminating middleware worker (%s)", mw.direction)
+				close(mw.outputChan)
+				return nil
+			}
+			msg, err := mw.apply(msg)
+			if err != nil {
+				mw.logger.Debugw("Middleware dropped message", "direction", mw.direction, "err", err)
+				continue
+			}
+			select {
+			case mw.outputChan <- msg:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// apply runs msg through the chain in registration order, stopping at the
+// first middleware that rejects it.
+func (mw *middlewareWorker) apply(msg string) (string, error) {
+	for _, m := range mw.chain {
+		var err error
+		if msg, err = m(msg); err != nil {
+			return "", err
+		}
+	}
+	return msg, nil
+}
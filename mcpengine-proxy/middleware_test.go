@@ -0,0 +1,128 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"go.uber.org/zap"
+)
+
+// ===== Middleware Tests =====
+
+func TestMiddlewareWorker_RewritesAndDrops(t *testing.T) {
+	inputChan := make(chan string, 3)
+	outputChan := make(chan string, 3)
+	inputChan <- "keep-1"
+	inputChan <- "drop-me"
+	inputChan <- "keep-2"
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	chain := []MessageMiddleware{
+		func(msg string) (string, error) {
+			if strings.HasPrefix(msg, "drop") {
+				return "", fmt.Errorf("blocked by policy")
+			}
+			return msg, nil
+		},
+		func(msg string) (string, error) {
+			return strings.ToUpper(msg), nil
+		},
+	}
+	worker := newMiddlewareWorker("outbound", chain, inputChan, outputChan, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := worker.Run(ctx, cancel); err != nil {
+		t.Fatalf("Middleware worker returned error: %v", err)
+	}
+
+	// The output channel is closed on input close, so downstream workers
+	// terminate too.
+	var got []string
+	for msg := range outputChan {
+		got = append(got, msg)
+	}
+	expected := []string{"KEEP-1", "KEEP-2"}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d: %v", len(expected), len(got), got)
+	}
+	for i, want := range expected {
+		if got[i] != want {
+			t.Errorf("Message %d: expected %q, got %q", i, want, got[i])
+		}
+	}
+}
+
+// TestMCPEngine_Middleware runs a full engine and verifies both directions:
+// outbound middleware annotates requests before they reach the server, and
+// inbound middleware rewrites responses before they reach the client.
+func TestMCPEngine_Middleware(t *testing.T) {
+	requests := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- string(body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc": "2.0", "id": 1, "result": "plain"}`)
+	}))
+	defer ts.Close()
+
+	input := mcpenginetest.NewBlockReader(strings.NewReader(
+		`{"jsonrpc": "2.0", "id": 1, "method": "ping"}` + "\n"))
+	outputReader, outputWriter := io.Pipe()
+
+	engine, err := New(Config{
+		Endpoint: ts.URL,
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+		Input:    input,
+		Output:   outputWriter,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	engine.OnOutbound(func(msg string) (string, error) {
+		return strings.Replace(msg, `"method": "ping"`, `"method": "ping", "annotated": true`, 1), nil
+	})
+	engine.OnInbound(func(msg string) (string, error) {
+		return strings.Replace(msg, "plain", "rewritten", 1), nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	select {
+	case request := <-requests:
+		if !strings.Contains(request, `"annotated": true`) {
+			t.Errorf("Expected the outbound middleware's annotation, got %q", request)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server did not receive the request")
+	}
+
+	lines := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		if scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, "rewritten") {
+			t.Errorf("Expected the inbound middleware's rewrite, got %q", line)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No response arrived on the output writer")
+	}
+}
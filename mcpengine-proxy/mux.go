@@ -0,0 +1,250 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Multiplexer lets several downstream clients share a single authenticated
+// upstream session. Request ids are namespaced so concurrent clients cannot
+// collide, responses are fanned back to the client that issued the request
+// with their original ids restored, and notifications and server-initiated
+// requests are broadcast to everyone. Client replies to server-initiated
+// requests pass through with their ids untouched, since the server chose
+// those ids. This is what lets multiple IDE windows reuse one login and
+// connection in daemon mode.
+type Multiplexer struct {
+	upstreamIn  chan string // Requests rewritten and forwarded upstream.
+	upstreamOut chan string // Responses and notifications from upstream.
+	logger      Logger
+
+	// sendMu lets CloseUpstream wait out in-flight Sends before closing the
+	// upstream channel, so late senders get an error instead of a panic.
+	sendMu         sync.RWMutex
+	upstreamClosed bool
+
+	mu           sync.Mutex
+	clients      map[int]*MuxClient
+	nextClientID int
+	nextReqID    int
+	// routes maps the raw bytes of each rewritten upstream id back to the
+	// issuing client and the id the client originally used (kept raw to
+	// preserve its type).
+	routes map[string]muxRoute
+}
+
+type muxRoute struct {
+	clientID   int
+	originalID json.RawMessage
+}
+
+// MuxClient is one downstream client attached to a Multiplexer.
+type MuxClient struct {
+	id         int
+	outputChan chan string
+	mux        *Multiplexer
+}
+
+// NewMultiplexer constructs a Multiplexer over the given upstream channels.
+// upstreamIn is typically the HTTP sender's input channel; upstreamOut
+// carries everything the upstream produces.
+func NewMultiplexer(upstreamIn, upstreamOut chan string, logger Logger) *Multiplexer {
+	return &Multiplexer{
+		upstreamIn:  upstreamIn,
+		upstreamOut: upstreamOut,
+		logger:      logger,
+		clients:     make(map[int]*MuxClient),
+		nextReqID:   1,
+		routes:      make(map[string]muxRoute),
+	}
+}
+
+// Attach registers a new downstream client and returns its handle.
+func (m *Multiplexer) Attach() *MuxClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextClientID++
+	client := &MuxClient{
+		id:         m.nextClientID,
+		outputChan: make(chan string, 1_000),
+		mux:        m,
+	}
+	m.clients[client.id] = client
+	m.logger.Infow("Client attached", "client-id", client.id)
+	return client
+}
+
+// Detach removes a client. Its output channel is closed and any responses
+// still in flight for it are dropped.
+func (m *Multiplexer) Detach(client *MuxClient) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.clients[client.id]; !ok {
+		return
+	}
+	delete(m.clients, client.id)
+	for upstreamID, route := range m.routes {
+		if route.clientID == client.id {
+			delete(m.routes, upstreamID)
+		}
+	}
+	close(client.outputChan)
+	m.logger.Infow("Client detached", "client-id", client.id)
+}
+
+// Output returns the channel carrying this client's responses and all
+// broadcast notifications.
+func (c *MuxClient) Output() <-chan string {
+	return c.outputChan
+}
+
+// Send forwards msg upstream on behalf of this client, rewriting its request
+// id into the shared namespace. Notifications pass through unchanged. Send
+// fails once the upstream has been closed.
+func (c *MuxClient) Send(ctx context.Context, msg string) error {
+	rewritten, err := c.mux.rewriteRequest(c.id, msg)
+	if err != nil {
+		return err
+	}
+	c.mux.sendMu.RLock()
+	defer c.mux.sendMu.RUnlock()
+	if c.mux.upstreamClosed {
+		return fmt.Errorf("mux: upstream is closed")
+	}
+	select {
+	case c.mux.upstreamIn <- rewritten:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CloseUpstream closes the upstream input channel, letting the rest of the
+// pipeline drain and shut down. Subsequent Sends from any client fail.
+func (m *Multiplexer) CloseUpstream() {
+	m.sendMu.Lock()
+	defer m.sendMu.Unlock()
+	if m.upstreamClosed {
+		return
+	}
+	m.upstreamClosed = true
+	close(m.upstreamIn)
+}
+
+// rewriteRequest assigns msg a unique upstream id and records the route back
+// to the client. Notifications (no id) and the client's replies to
+// server-initiated requests (id without a method) are left untouched. The
+// message is reassembled from raw fields so everything but the id keeps its
+// exact bytes.
+func (m *Multiplexer) rewriteRequest(clientID int, msg string) (string, error) {
+	originalID := getMessageID(msg, m.logger)
+	if originalID == nil || getMessageMethod(msg) == "" {
+		return msg, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(msg), &fields); err != nil {
+		return "", fmt.Errorf("failed to parse request: %w", err)
+	}
+
+	m.mu.Lock()
+	upstreamID := json.RawMessage(strconv.Itoa(m.nextReqID))
+	m.nextReqID++
+	m.routes[string(upstreamID)] = muxRoute{clientID: clientID, originalID: originalID}
+	m.mu.Unlock()
+
+	fields["id"] = upstreamID
+	rewritten, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to rewrite request: %w", err)
+	}
+	return string(rewritten), nil
+}
+
+// Run routes upstream messages to the right client until the upstream
+// channel closes or the context is canceled. It implements the worker
+// interface so it can run alongside the engine's other workers.
+func (m *Multiplexer) Run(ctx context.Context, cancel context.CancelFunc) error {
+	m.logger.Debug("Starting multiplexer")
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Multiplexer canceled")
+			return ctx.Err()
+		case msg, ok := <-m.upstreamOut:
+			if !ok {
+				m.logger.Info("Upstream channel closed, terminating multiplexer")
+				return nil
+			}
+			m.route(msg)
+		}
+	}
+}
+
+// route delivers one upstream message: responses go to their issuing client
+// with the original id restored; notifications and server-initiated requests
+// (which carry a method) are broadcast so any client may answer.
+func (m *Multiplexer) route(msg string) {
+	upstreamID := getMessageID(msg, m.logger)
+	if upstreamID == nil || getMessageMethod(msg) != "" {
+		m.broadcast(msg)
+		return
+	}
+
+	m.mu.Lock()
+	route, known := m.routes[string(upstreamID)]
+	if known {
+		delete(m.routes, string(upstreamID))
+	}
+	m.mu.Unlock()
+
+	if !known {
+		m.logger.Warnw("No client for upstream response, dropping", "id", string(upstreamID))
+		return
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(msg), &fields); err != nil {
+		m.logger.Errorf("Failed to parse upstream response: %v", err)
+		return
+	}
+	fields["id"] = route.originalID
+	restored, err := json.Marshal(fields)
+	if err != nil {
+		m.logger.Errorf("Failed to rewrite response: %v", err)
+		return
+	}
+
+	// Deliver under the lock so Detach cannot close the channel out from
+	// under the send, and without blocking so one stalled client cannot
+	// wedge routing for everyone — the same discipline as broadcast.
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	client := m.clients[route.clientID]
+	if client == nil {
+		m.logger.Warnw("Client detached before its response arrived, dropping", "id", string(upstreamID))
+		return
+	}
+	select {
+	case client.outputChan <- string(restored):
+	default:
+		m.logger.Warnw("Client output full, dropping response", "client-id", client.id, "id", string(upstreamID))
+	}
+}
+
+// broadcast copies msg to every attached client.
+func (m *Multiplexer) broadcast(msg string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, client := range m.clients {
+		select {
+		case client.outputChan <- msg:
+		default:
+			m.logger.Warnw("Client output full, dropping broadcast", "client-id", client.id)
+		}
+	}
+}
@@ -0,0 +1,339 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Multiplexer Tests =====
+
+func TestMultiplexer_NamespacesRequestIDs(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+	client1 := mux.Attach()
+	client2 := mux.Attach()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// Both clients use id 1; upstream must see two distinct ids.
+	if err := client1.Send(ctx, `{"jsonrpc": "2.0", "id": 1, "method": "a"}`); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	if err := client2.Send(ctx, `{"jsonrpc": "2.0", "id": 1, "method": "b"}`); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	ids := map[float64]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-upstreamIn:
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(msg), &data); err != nil {
+				t.Fatalf("Failed to parse upstream message: %v", err)
+			}
+			ids[data["id"].(float64)] = true
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for upstream message")
+		}
+	}
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 distinct upstream ids, got %v", ids)
+	}
+}
+
+func TestMultiplexer_RoutesResponsesToIssuingClient(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+	client1 := mux.Attach()
+	client2 := mux.Attach()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mux.Run(ctx, cancel)
+
+	// client2 sends a request with a string id; the response must come back
+	// only to client2 with the original id restored.
+	if err := client2.Send(ctx, `{"jsonrpc": "2.0", "id": "req-abc", "method": "test"}`); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	var upstreamID float64
+	select {
+	case msg := <-upstreamIn:
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(msg), &data); err != nil {
+			t.Fatalf("Failed to parse upstream message: %v", err)
+		}
+		upstreamID = data["id"].(float64)
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for upstream message")
+	}
+
+	response, _ := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      upstreamID,
+		"result":  "ok",
+	})
+	upstreamOut <- string(response)
+
+	select {
+	case msg := <-client2.Output():
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(msg), &data); err != nil {
+			t.Fatalf("Failed to parse routed response: %v", err)
+		}
+		if data["id"] != "req-abc" {
+			t.Errorf("Expected original id %q restored, got %v", "req-abc", data["id"])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for routed response")
+	}
+
+	select {
+	case msg := <-client1.Output():
+		t.Errorf("client1 unexpectedly received: %q", msg)
+	default:
+		// Expected - response went only to client2.
+	}
+}
+
+func TestMultiplexer_BroadcastsNotifications(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+	client1 := mux.Attach()
+	client2 := mux.Attach()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mux.Run(ctx, cancel)
+
+	notification := `{"jsonrpc": "2.0", "method": "notifications/message", "params": {"level": "info"}}`
+	upstreamOut <- notification
+
+	for i, client := range []*MuxClient{client1, client2} {
+		select {
+		case msg := <-client.Output():
+			if msg != notification {
+				t.Errorf("Client %d: expected %q, got %q", i+1, notification, msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Client %d did not receive broadcast", i+1)
+		}
+	}
+}
+
+func TestMultiplexer_DetachDropsPendingRoutes(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+	client := mux.Attach()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mux.Run(ctx, cancel)
+
+	if err := client.Send(ctx, `{"jsonrpc": "2.0", "id": 7, "method": "test"}`); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+	<-upstreamIn
+
+	mux.Detach(client)
+
+	// The response for the detached client should be dropped, not panic.
+	upstreamOut <- `{"jsonrpc": "2.0", "id": 1, "result": "late"}`
+	time.Sleep(100 * time.Millisecond)
+
+	// The client's channel is closed after detach.
+	if _, ok := <-client.Output(); ok {
+		t.Error("Expected closed output channel after detach")
+	}
+}
+
+func TestMultiplexer_DetachDuringRouteDoesNotPanic(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mux.Run(ctx, cancel)
+
+	// Race Detach against delivery of the client's in-flight response many
+	// times; a send on the closed output channel would panic the process.
+	for i := 0; i < 200; i++ {
+		client := mux.Attach()
+		if err := client.Send(ctx, `{"jsonrpc": "2.0", "id": 1, "method": "test"}`); err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+
+		var upstreamID float64
+		select {
+		case msg := <-upstreamIn:
+			var data map[string]interface{}
+			if err := json.Unmarshal([]byte(msg), &data); err != nil {
+				t.Fatalf("Failed to parse upstream message: %v", err)
+			}
+			upstreamID = data["id"].(float64)
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for upstream message")
+		}
+
+		done := make(chan struct{})
+		go func() {
+			mux.Detach(client)
+			close(done)
+		}()
+		response, _ := json.Marshal(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      upstreamID,
+			"result":  "ok",
+		})
+		upstreamOut <- string(response)
+		<-done
+	}
+}
+
+func TestMultiplexer_FullClientDoesNotBlockRouting(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+	stalled := mux.Attach()
+	healthy := mux.Attach()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mux.Run(ctx, cancel)
+
+	// Wedge the first client by filling its buffer without draining it.
+	for i := 0; i < cap(stalled.outputChan); i++ {
+		stalled.outputChan <- "backlog"
+	}
+
+	for _, client := range []*MuxClient{stalled, healthy} {
+		if err := client.Send(ctx, `{"jsonrpc": "2.0", "id": 1, "method": "test"}`); err != nil {
+			t.Fatalf("Send returned error: %v", err)
+		}
+		<-upstreamIn
+	}
+
+	// The stalled client's response is dropped rather than blocking routing,
+	// so the healthy client's response must still arrive.
+	upstreamOut <- `{"jsonrpc": "2.0", "id": 1, "result": "dropped"}`
+	upstreamOut <- `{"jsonrpc": "2.0", "id": 2, "result": "delivered"}`
+
+	select {
+	case msg := <-healthy.Output():
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(msg), &data); err != nil {
+			t.Fatalf("Failed to parse routed response: %v", err)
+		}
+		if data["result"] != "delivered" {
+			t.Errorf("Expected the healthy client's response, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Routing wedged behind the stalled client")
+	}
+}
+
+func TestMultiplexer_BroadcastsServerRequests(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+	client1 := mux.Attach()
+	client2 := mux.Attach()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go mux.Run(ctx, cancel)
+
+	// A server-initiated request carries an id the mux never assigned; it
+	// must be broadcast, not dropped as an unroutable response.
+	serverReq := `{"jsonrpc": "2.0", "id": "srv-1", "method": "sampling/createMessage"}`
+	upstreamOut <- serverReq
+
+	for i, client := range []*MuxClient{client1, client2} {
+		select {
+		case msg := <-client.Output():
+			if msg != serverReq {
+				t.Errorf("Client %d: expected %q, got %q", i+1, serverReq, msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Client %d did not receive the server request", i+1)
+		}
+	}
+}
+
+func TestMultiplexer_PassesServerRequestRepliesUnchanged(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+	client := mux.Attach()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// The server chose this id, so the reply must keep it verbatim.
+	reply := `{"jsonrpc":"2.0","id":"srv-1","result":{}}`
+	if err := client.Send(ctx, reply); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	select {
+	case msg := <-upstreamIn:
+		if msg != reply {
+			t.Errorf("Expected reply unchanged, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for upstream reply")
+	}
+}
+
+func TestMultiplexer_PassesNotificationsUpstreamUnchanged(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+	client := mux.Attach()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	notification := `{"jsonrpc":"2.0","method":"notifications/initialized"}`
+	if err := client.Send(ctx, notification); err != nil {
+		t.Fatalf("Send returned error: %v", err)
+	}
+
+	select {
+	case msg := <-upstreamIn:
+		if msg != notification {
+			t.Errorf("Expected notification unchanged, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for upstream notification")
+	}
+}
@@ -0,0 +1,63 @@
+package mcpengine
+
+import (
+	"encoding/json"
+)
+
+// notifierName is the logger name reported in emitted MCP notifications.
+const notifierName = "mcpengine"
+
+// Notifier surfaces significant proxy events (auth required, delivery
+// failures, dropped messages) to the connected client as MCP
+// notifications/message log entries, in addition to the file logs. Host
+// applications can then show the user what the proxy is doing instead of
+// the information being buried in a log file.
+//
+// A nil Notifier is valid and drops every event, so call sites don't need
+// to guard.
+type Notifier struct {
+	outputChan chan string
+	logger     Logger
+}
+
+// NewNotifier creates a Notifier that writes notifications to outputChan
+// (the same channel the stdout proxy drains).
+func NewNotifier(outputChan chan string, logger Logger) *Notifier {
+	return &Notifier{
+		outputChan: outputChan,
+		logger:     logger,
+	}
+}
+
+// Notify emits one notifications/message entry with the given MCP logging
+// level ("debug", "info", "warning", "error"), a human-readable message,
+// and optional structured details. Events are dropped rather than blocking
+// if the output channel is full.
+func (n *Notifier) Notify(level, message string, details map[string]interface{}) {
+	if n == nil {
+		return
+	}
+	data := map[string]interface{}{"message": message}
+	for k, v := range details {
+		data[k] = v
+	}
+	notification := map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "notifications/message",
+		"params": map[string]interface{}{
+			"level":  level,
+			"logger": notifierName,
+			"data":   data,
+		},
+	}
+	encoded, err := json.Marshal(notification)
+	if err != nil {
+		n.logger.Errorf("Failed to marshal notification: %v", err)
+		return
+	}
+	select {
+	case n.outputChan <- string(encoded):
+	default:
+		n.logger.Warnw("Output channel full, dropping notification", "message", message)
+	}
+}
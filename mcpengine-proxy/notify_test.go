@@ -0,0 +1,108 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Notifier Tests =====
+
+func TestNotifier_EmitsLoggingNotification(t *testing.T) {
+	outputChan := make(chan string, 1)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	notifier := NewNotifier(outputChan, logger)
+	notifier.Notify("warning", "something happened", map[string]interface{}{"status": 429})
+
+	select {
+	case msg := <-outputChan:
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(msg), &data); err != nil {
+			t.Fatalf("Notification is not valid JSON: %v", err)
+		}
+		if data["method"] != "notifications/message" {
+			t.Errorf("Expected method notifications/message, got %v", data["method"])
+		}
+		params := data["params"].(map[string]interface{})
+		if params["level"] != "warning" {
+			t.Errorf("Expected level warning, got %v", params["level"])
+		}
+		details := params["data"].(map[string]interface{})
+		if details["message"] != "something happened" {
+			t.Errorf("Expected message in data, got %v", details["message"])
+		}
+		if details["status"] != float64(429) {
+			t.Errorf("Expected status 429 in data, got %v", details["status"])
+		}
+	default:
+		t.Fatal("Expected a notification on the output channel")
+	}
+}
+
+func TestNotifier_NilIsSafe(t *testing.T) {
+	var notifier *Notifier
+	// Must not panic.
+	notifier.Notify("info", "ignored", nil)
+}
+
+func TestNotifier_DropsWhenChannelFull(t *testing.T) {
+	outputChan := make(chan string) // Unbuffered and never drained.
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	notifier := NewNotifier(outputChan, logger)
+	done := make(chan struct{})
+	go func() {
+		notifier.Notify("info", "dropped", nil)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		// Success - Notify did not block.
+	case <-time.After(time.Second):
+		t.Fatal("Notify blocked on a full channel")
+	}
+}
+
+func TestHTTPPostSender_NotifiesOnAuthChallenge(t *testing.T) {
+	// A 401 without a WWW-Authenticate header fails the auth flow, but the
+	// client should still see a notification that auth was required.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer ts.Close()
+
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 10)
+	endpointChan <- "/mcp"
+	inputChan <- `{"id": 1, "method": "test"}`
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewHTTPPostSender(&http.Client{}, ts.URL, endpointChan, inputChan, outputChan, NewAuthManager(nil, logger), logger)
+	sender.SetNotifier(NewNotifier(outputChan, logger))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	sender.Run(ctx, cancel)
+
+	select {
+	case msg := <-outputChan:
+		var data map[string]interface{}
+		if err := json.Unmarshal([]byte(msg), &data); err != nil {
+			t.Fatalf("Notification is not valid JSON: %v", err)
+		}
+		if data["method"] != "notifications/message" {
+			t.Errorf("Expected notifications/message, got %v", data["method"])
+		}
+	default:
+		t.Fatal("Expected an auth notification on the output channel")
+	}
+}
@@ -0,0 +1,125 @@
+package mcpengine
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Retry pacing while the endpoint is unreachable, mirroring the SSE
+// worker's reconnect backoff.
+const (
+	offlineInitialRetryDelay = 1 * time.Second
+	offlineMaxRetryDelay     = 30 * time.Second
+)
+
+// offlineQueue holds outgoing messages while the endpoint is unreachable:
+// a bounded in-memory FIFO that spills overflow to a file so a long outage
+// on a flaky network doesn't grow the process or lose requests. Messages
+// keep their order across the memory/disk boundary.
+type offlineQueue struct {
+	limit     int
+	spillPath string
+	logger    Logger
+
+	mu      sync.Mutex
+	mem     []string
+	spilled int // Lines currently in the spill file.
+}
+
+// newOfflineQueue builds a queue keeping at most limit messages in memory.
+// spillPath receives the overflow; when empty, overflow is dropped.
+func newOfflineQueue(limit int, spillPath string, logger Logger) *offlineQueue {
+	return &offlineQueue{
+		limit:     limit,
+		spillPath: spillPath,
+		logger:    logger,
+	}
+}
+
+// Push enqueues one message, reporting false when the queue is full and has
+// nowhere to spill.
+func (oq *offlineQueue) Push(msg string) bool {
+	oq.mu.Lock()
+	defer oq.mu.Unlock()
+	// Once anything has spilled, later messages must spill too or they
+	// would overtake the ones on disk.
+	if len(oq.mem) < oq.limit && oq.spilled == 0 {
+		oq.mem = append(oq.mem, msg)
+		return true
+	}
+	if oq.spillPath == "" {
+		return false
+	}
+	file, err := os.OpenFile(oq.spillPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		oq.logger.Errorw("Failed to open spill file", "path", oq.spillPath, "err", err)
+		return false
+	}
+	defer file.Close()
+	if _, err := fmt.Fprintf(file, "%s\n", msg); err != nil {
+		oq.logger.Errorw("Failed to spill message", "path", oq.spillPath, "err", err)
+		return false
+	}
+	oq.spilled++
+	return true
+}
+
+// Peek returns the oldest buffered message without removing it, reloading
+// from the spill file when memory has drained.
+func (oq *offlineQueue) Peek() (string, bool) {
+	oq.mu.Lock()
+	defer oq.mu.Unlock()
+	if len(oq.mem) == 0 && oq.spilled > 0 {
+		oq.reload()
+	}
+	if len(oq.mem) == 0 {
+		return "", false
+	}
+	return oq.mem[0], true
+}
+
+// Drop removes the oldest buffered message, after a successful delivery.
+func (oq *offlineQueue) Drop() {
+	oq.mu.Lock()
+	defer oq.mu.Unlock()
+	if len(oq.mem) > 0 {
+		oq.mem = oq.mem[1:]
+	}
+}
+
+// Len reports how many messages are buffered, in memory and on disk.
+func (oq *offlineQueue) Len() int {
+	oq.mu.Lock()
+	defer oq.mu.Unlock()
+	return len(oq.mem) + oq.spilled
+}
+
+// reload moves the spill file's contents back into memory and truncates it.
+// Called with the lock held, and only when memory is empty.
+func (oq *offlineQueue) reload() {
+	file, err := os.Open(oq.spillPath)
+	if err != nil {
+		oq.logger.Errorw("Failed to reload spill file", "path", oq.spillPath, "err", err)
+		return
+	}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), defaultMaxMessageSize)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			oq.mem = append(oq.mem, line)
+		}
+	}
+	err = scanner.Err()
+	file.Close()
+	if err != nil {
+		oq.logger.Errorw("Failed to read spill file", "path", oq.spillPath, "err", err)
+		return
+	}
+	if err := os.Truncate(oq.spillPath, 0); err != nil {
+		oq.logger.Errorw("Failed to truncate spill file", "path", oq.spillPath, "err", err)
+	}
+	oq.spilled = 0
+}
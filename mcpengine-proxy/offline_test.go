@@ -0,0 +1,148 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Offline Buffer Tests =====
+
+func TestOfflineQueue_FIFOWithinMemory(t *testing.T) {
+	queue := newOfflineQueue(5, "", NewZapLogger(zap.NewNop().Sugar()))
+	for i := 0; i < 3; i++ {
+		if !queue.Push(fmt.Sprintf("msg-%d", i)) {
+			t.Fatalf("Push %d rejected below the limit", i)
+		}
+	}
+	if queue.Len() != 3 {
+		t.Errorf("Expected 3 buffered messages, got %d", queue.Len())
+	}
+	for i := 0; i < 3; i++ {
+		msg, ok := queue.Peek()
+		if !ok || msg != fmt.Sprintf("msg-%d", i) {
+			t.Errorf("Expected msg-%d, got %q (ok=%v)", i, msg, ok)
+		}
+		queue.Drop()
+	}
+	if _, ok := queue.Peek(); ok {
+		t.Error("Expected the queue drained")
+	}
+}
+
+func TestOfflineQueue_DropsOverflowWithoutSpill(t *testing.T) {
+	queue := newOfflineQueue(2, "", NewZapLogger(zap.NewNop().Sugar()))
+	queue.Push("a")
+	queue.Push("b")
+	if queue.Push("c") {
+		t.Error("Expected the overflow push rejected without a spill file")
+	}
+	if queue.Len() != 2 {
+		t.Errorf("Expected 2 buffered messages, got %d", queue.Len())
+	}
+}
+
+func TestOfflineQueue_SpillsAndPreservesOrder(t *testing.T) {
+	spill := filepath.Join(t.TempDir(), "spill.jsonl")
+	queue := newOfflineQueue(2, spill, NewZapLogger(zap.NewNop().Sugar()))
+	for i := 0; i < 6; i++ {
+		if !queue.Push(fmt.Sprintf("msg-%d", i)) {
+			t.Fatalf("Push %d rejected despite the spill file", i)
+		}
+	}
+	if queue.Len() != 6 {
+		t.Errorf("Expected 6 buffered messages, got %d", queue.Len())
+	}
+	// Draining crosses the memory/disk boundary in order.
+	for i := 0; i < 6; i++ {
+		msg, ok := queue.Peek()
+		if !ok || msg != fmt.Sprintf("msg-%d", i) {
+			t.Fatalf("Expected msg-%d, got %q (ok=%v)", i, msg, ok)
+		}
+		queue.Drop()
+	}
+	// A message pushed after the spill reloaded still comes last.
+	queue.Push("late")
+	if msg, ok := queue.Peek(); !ok || msg != "late" {
+		t.Errorf("Expected the late message, got %q (ok=%v)", msg, ok)
+	}
+}
+
+func TestHTTPPostSender_BuffersWhileOfflineAndRecovers(t *testing.T) {
+	// Reserve a port with nothing listening, so POSTs fail with a
+	// connection error until the server comes up on it.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve a port: %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 10)
+	outputChan := make(chan string, 10)
+
+	sender := NewHTTPPostSender(&http.Client{Timeout: 2 * time.Second}, "http://"+addr, endpointChan, inputChan, outputChan, NewAuthManager(nil, logger), logger)
+	sender.SetOfflineQueue(newOfflineQueue(10, "", logger))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	endpointChan <- "/mcp"
+	inputChan <- `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+	inputChan <- `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+
+	// Nothing is failed back to the client while buffering.
+	select {
+	case msg := <-outputChan:
+		t.Fatalf("Expected no delivery error while buffering, got %s", msg)
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// Bring the server up on the reserved port and collect what arrives.
+	var mu sync.Mutex
+	var received []string
+	listener, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to start the server: %v", err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make([]byte, r.ContentLength)
+		r.Body.Read(body)
+		mu.Lock()
+		received = append(received, string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		mu.Lock()
+		count := len(received)
+		mu.Unlock()
+		if count >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Buffered messages never delivered (got %d)", count)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !strings.Contains(received[0], `"id":1`) || !strings.Contains(received[1], `"id":2`) {
+		t.Errorf("Messages delivered out of order: %v", received)
+	}
+}
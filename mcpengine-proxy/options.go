@@ -0,0 +1,103 @@
+package mcpengine
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Option configures an engine built with NewEngine.
+type Option func(*Config)
+
+// NewEngine is a functional-options alternative to New for embedders: only
+// the endpoint is required, and omitted settings fall back to safe
+// defaults — the SSE transport on /sse and a no-op logger, so a bare
+// NewEngine(url) can't panic on a nil logger. Option combinations go
+// through the same Validate as a hand-built Config.
+func NewEngine(endpoint string, opts ...Option) (*MCPEngine, error) {
+	cfg := Config{
+		Endpoint:  endpoint,
+		Transport: TransportSSE,
+		SSEPath:   "/sse",
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = NewZapLogger(zap.NewNop().Sugar())
+	}
+	return New(cfg)
+}
+
+// WithLogger sets the engine's logger.
+func WithLogger(logger Logger) Option {
+	return func(cfg *Config) { cfg.Logger = logger }
+}
+
+// WithTransport selects the wire protocol explicitly. Switching away from
+// SSE drops the default SSE path, so only the paths for the chosen
+// transport need supplying.
+func WithTransport(transport TransportMode) Option {
+	return func(cfg *Config) {
+		cfg.Transport = transport
+		if transport != TransportSSE && cfg.SSEPath == "/sse" {
+			cfg.SSEPath = ""
+		}
+	}
+}
+
+// WithSSEPath sets the path for the SSE subscription.
+func WithSSEPath(path string) Option {
+	return func(cfg *Config) { cfg.SSEPath = path }
+}
+
+// WithMCPPath sets the POST path for the http and streamable-http
+// transports.
+func WithMCPPath(path string) Option {
+	return func(cfg *Config) { cfg.MCPPath = path }
+}
+
+// WithAuth supplies a custom Authenticator in place of the default OIDC
+// AuthManager.
+func WithAuth(auth Authenticator) Option {
+	return func(cfg *Config) { cfg.Authenticator = auth }
+}
+
+// WithAuthConfig configures the default OIDC AuthManager.
+func WithAuthConfig(authCfg *AuthConfig) Option {
+	return func(cfg *Config) { cfg.AuthConfig = authCfg }
+}
+
+// WithHTTPClient supplies the HTTP client for all outbound connections,
+// overriding the proxy- and TLS-derived default.
+func WithHTTPClient(client *http.Client) Option {
+	return func(cfg *Config) { cfg.HTTPClient = client }
+}
+
+// WithHeaders attaches static HTTP headers to every outbound request.
+func WithHeaders(headers map[string]string) Option {
+	return func(cfg *Config) { cfg.Headers = headers }
+}
+
+// WithInput sets where client JSON-RPC messages are read from.
+func WithInput(input io.Reader) Option {
+	return func(cfg *Config) { cfg.Input = input }
+}
+
+// WithOutput sets where responses and notifications are written.
+func WithOutput(output io.Writer) Option {
+	return func(cfg *Config) { cfg.Output = output }
+}
+
+// WithStartupTimeout bounds how long Start waits for the POST endpoint.
+func WithStartupTimeout(timeout time.Duration) Option {
+	return func(cfg *Config) { cfg.StartupTimeout = timeout }
+}
+
+// WithRequestTimeout bounds how long each JSON-RPC request may wait for a
+// response.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(cfg *Config) { cfg.RequestTimeout = timeout }
+}
@@ -0,0 +1,69 @@
+package mcpengine
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Functional Options Tests =====
+
+func TestNewEngine_Defaults(t *testing.T) {
+	engine, err := NewEngine("http://localhost:8000")
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	if engine.transport != TransportSSE {
+		t.Errorf("Expected the SSE transport by default, got %q", engine.transport)
+	}
+	if engine.logger == nil {
+		t.Error("Expected a default logger")
+	}
+}
+
+func TestNewEngine_Options(t *testing.T) {
+	client := &http.Client{Timeout: time.Second}
+	auth := NewStaticTokenAuthenticator("token", NewZapLogger(zap.NewNop().Sugar()))
+	engine, err := NewEngine("http://localhost:8000",
+		WithTransport(TransportStreamableHTTP),
+		WithMCPPath("/mcp"),
+		WithHTTPClient(client),
+		WithAuth(auth),
+		WithRequestTimeout(time.Minute),
+	)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	if engine.transport != TransportStreamableHTTP {
+		t.Errorf("Expected the streamable-http transport, got %q", engine.transport)
+	}
+	if engine.mcpPath != "/mcp" {
+		t.Errorf("Expected the configured MCP path, got %q", engine.mcpPath)
+	}
+	if engine.httpClient != client {
+		t.Error("Expected the supplied HTTP client to be used")
+	}
+	if engine.auth != Authenticator(auth) {
+		t.Error("Expected the supplied Authenticator to be used")
+	}
+	if engine.requestTimeout != time.Minute {
+		t.Errorf("Expected the configured request timeout, got %v", engine.requestTimeout)
+	}
+}
+
+func TestNewEngine_InvalidCombinations(t *testing.T) {
+	if _, err := NewEngine(""); err == nil {
+		t.Error("Expected an error for an empty endpoint")
+	}
+	// An SSE path makes no sense on the http transport; the usual Config
+	// validation applies to option-built engines too.
+	if _, err := NewEngine("http://localhost:8000",
+		WithTransport(TransportHTTP),
+		WithMCPPath("/mcp"),
+		WithSSEPath("/sse"),
+	); err == nil {
+		t.Error("Expected an error combining an SSE path with the http transport")
+	}
+}
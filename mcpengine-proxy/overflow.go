@@ -0,0 +1,122 @@
+package mcpengine
+
+import (
+	"context"
+)
+
+// OverflowPolicy selects what happens when an internal message channel
+// fills up because its consumer has fallen behind.
+type OverflowPolicy string
+
+const (
+	// OverflowBlock applies backpressure: producers wait for room. This
+	// is the default and matches the engine's historical behavior.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest discards the oldest buffered message, with a
+	// warning, so the newest traffic keeps flowing.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowError rejects the newest message instead, answering it
+	// with a JSON-RPC error where a response channel exists.
+	OverflowError OverflowPolicy = "error"
+)
+
+// valid reports whether p names a known policy; empty selects the default.
+func (p OverflowPolicy) valid() bool {
+	switch p {
+	case "", OverflowBlock, OverflowDropOldest, OverflowError:
+		return true
+	}
+	return false
+}
+
+// spliced reports whether the policy needs an overflowWorker between the
+// producers and the consumer; blocking is what a full channel does anyway.
+func (p OverflowPolicy) spliced() bool {
+	return p == OverflowDropOldest || p == OverflowError
+}
+
+// overflowWorker relays messages from in to out through a bounded queue,
+// applying the overflow policy once the queue is full instead of blocking
+// the producers. Under OverflowError, rejected requests are answered on
+// errChan; when errChan is nil (the client-facing channel, where a
+// congested client cannot receive an error either) they are logged and
+// discarded.
+type overflowWorker struct {
+	name    string
+	in      chan string
+	out     chan string
+	limit   int
+	policy  OverflowPolicy
+	errChan chan string
+	logger  Logger
+}
+
+// newOverflowWorker constructs a relay enforcing policy with at most limit
+// queued messages.
+func newOverflowWorker(name string, in, out chan string, limit int, policy OverflowPolicy, errChan chan string, logger Logger) *overflowWorker {
+	return &overflowWorker{
+		name:    name,
+		in:      in,
+		out:     out,
+		limit:   limit,
+		policy:  policy,
+		errChan: errChan,
+		logger:  logger,
+	}
+}
+
+// Run relays until the context is cancelled or in closes, draining the
+// queue and propagating the close downstream.
+func (ow *overflowWorker) Run(ctx context.Context, cancel context.CancelFunc) error {
+	var queue []string
+	in := ow.in
+	for {
+		if in == nil && len(queue) == 0 {
+			ow.logger.Infof("Input closed, terminating %s overflow relay", ow.name)
+			close(ow.out)
+			return nil
+		}
+		// Only offer the head for sending while something is queued; a
+		// nil channel keeps the send case dormant.
+		var out chan string
+		var head string
+		if len(queue) > 0 {
+			out = ow.out
+			head = queue[0]
+		}
+		select {
+		case <-ctx.Done():
+			ow.logger.Infof("Overflow relay %s canceled", ow.name)
+			return ctx.Err()
+		case out <- head:
+			queue = queue[1:]
+		case msg, ok := <-in:
+			if !ok {
+				in = nil
+				continue
+			}
+			if len(queue) < ow.limit {
+				queue = append(queue, msg)
+				continue
+			}
+			switch ow.policy {
+			case OverflowDropOldest:
+				ow.logger.Warnw("Channel full; dropping oldest message", "channel", ow.name, "limit", ow.limit)
+				queue = append(queue[1:], msg)
+			default: // OverflowError.
+				ow.logger.Errorw("Channel full; rejecting message", "channel", ow.name, "limit", ow.limit)
+				if ow.errChan == nil {
+					continue
+				}
+				if id := getMessageID(msg, ow.logger); id != nil {
+					select {
+					case ow.errChan <- jsonRPCErrorString(id, overloadedErrorCode,
+						"proxy overloaded; message rejected",
+						map[string]interface{}{"limit": ow.limit}, ow.logger):
+					default:
+					}
+				}
+			}
+		}
+	}
+}
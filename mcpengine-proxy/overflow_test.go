@@ -0,0 +1,132 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Overflow Policy Tests =====
+
+func TestOverflowWorker_RelaysAndPropagatesClose(t *testing.T) {
+	in := make(chan string)
+	out := make(chan string, 10)
+	relay := newOverflowWorker("test", in, out, 5, OverflowDropOldest, nil, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go relay.Run(ctx, cancel)
+
+	for i := 0; i < 3; i++ {
+		in <- fmt.Sprintf("msg-%d", i)
+	}
+	close(in)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case msg := <-out:
+			if msg != fmt.Sprintf("msg-%d", i) {
+				t.Errorf("Expected msg-%d, got %q", i, msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Message %d never relayed", i)
+		}
+	}
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Error("Expected the output channel closed, got a message")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Output channel never closed after input closed")
+	}
+}
+
+func TestOverflowWorker_DropsOldestWhenFull(t *testing.T) {
+	in := make(chan string)
+	out := make(chan string) // Unbuffered and unread: the relay's queue fills.
+	relay := newOverflowWorker("test", in, out, 2, OverflowDropOldest, nil, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go relay.Run(ctx, cancel)
+
+	for i := 0; i < 4; i++ {
+		in <- fmt.Sprintf("msg-%d", i)
+	}
+	// The queue held msg-0/msg-1; msg-2 and msg-3 displaced them.
+	for i := 2; i < 4; i++ {
+		select {
+		case msg := <-out:
+			if msg != fmt.Sprintf("msg-%d", i) {
+				t.Errorf("Expected msg-%d, got %q", i, msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Message %d never relayed", i)
+		}
+	}
+}
+
+func TestOverflowWorker_RejectsWithErrorWhenFull(t *testing.T) {
+	in := make(chan string)
+	out := make(chan string) // Unbuffered and unread: the relay's queue fills.
+	errChan := make(chan string, 4)
+	relay := newOverflowWorker("test", in, out, 1, OverflowError, errChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go relay.Run(ctx, cancel)
+
+	in <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	in <- `{"jsonrpc": "2.0", "id": 2, "method": "ping"}`
+
+	select {
+	case msg := <-errChan:
+		if !strings.Contains(msg, fmt.Sprintf("%d", overloadedErrorCode)) || !strings.Contains(msg, `"id":2`) {
+			t.Errorf("Expected an overloaded error for id 2, got %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Rejected request never answered with an error")
+	}
+	// The queued message still goes out untouched.
+	select {
+	case msg := <-out:
+		if !strings.Contains(msg, `"id": 1`) {
+			t.Errorf("Expected the first message relayed, got %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Queued message never relayed")
+	}
+}
+
+func TestOverflowPolicy_ConfigValidation(t *testing.T) {
+	base := Config{
+		Endpoint: "http://localhost:8080",
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+	}
+
+	cfg := base
+	cfg.InputOverflowPolicy = "drop-newest"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an unknown InputOverflowPolicy rejected")
+	}
+
+	cfg = base
+	cfg.ChannelBufferSize = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative ChannelBufferSize rejected")
+	}
+
+	cfg = base
+	cfg.ChannelBufferSize = 50
+	cfg.InputOverflowPolicy = OverflowError
+	cfg.OutputOverflowPolicy = OverflowDropOldest
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid overflow config, got %v", err)
+	}
+}
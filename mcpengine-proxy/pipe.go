@@ -0,0 +1,121 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// npipeScheme prefixes endpoints that name a Windows named pipe, e.g.
+// "npipe:////./pipe/mcp" for \\.\pipe\mcp (the same convention the docker
+// CLI uses).
+const npipeScheme = "npipe://"
+
+// parseNpipeEndpoint translates an npipe:// endpoint into the \\.\pipe\...
+// path the OS expects.
+func parseNpipeEndpoint(endpoint string) (string, error) {
+	if !strings.HasPrefix(endpoint, npipeScheme) {
+		return "", fmt.Errorf("pipe: endpoint %q must start with %s", endpoint, npipeScheme)
+	}
+	path := strings.TrimPrefix(endpoint, npipeScheme)
+	path = strings.ReplaceAll(path, "/", `\`)
+	if !strings.HasPrefix(path, `\\.\pipe\`) || len(path) <= len(`\\.\pipe\`) {
+		return "", fmt.Errorf("pipe: endpoint %q must name a pipe like %s//./pipe/<name>", endpoint, npipeScheme)
+	}
+	return path, nil
+}
+
+// PipeBridge connects to an existing named pipe and bridges JSON-RPC
+// messages over it, for local servers that expose a pipe instead of stdio
+// or HTTP. Only available on Windows, where Unix sockets aren't; dialing
+// uses overlapped I/O so concurrent reads and writes don't block each
+// other.
+type PipeBridge struct {
+	path           string
+	inputChan      chan string // Messages written to the pipe.
+	outputChan     chan string // Messages read from the pipe.
+	maxMessageSize int
+	logger         Logger
+}
+
+// NewPipeBridge constructs a PipeBridge for the given \\.\pipe\... path.
+func NewPipeBridge(path string, inputChan, outputChan chan string, logger Logger) *PipeBridge {
+	return &PipeBridge{
+		path:       path,
+		inputChan:  inputChan,
+		outputChan: outputChan,
+		logger:     logger,
+	}
+}
+
+// SetMaxMessageSize caps how large a single line from the pipe may be.
+// Zero keeps the default (defaultMaxMessageSize). Must be called before
+// Run.
+func (pb *PipeBridge) SetMaxMessageSize(n int) {
+	pb.maxMessageSize = n
+}
+
+// Run dials the pipe and pumps messages in both directions until the
+// context is cancelled, the input channel closes, or the pipe breaks.
+func (pb *PipeBridge) Run(ctx context.Context, cancel context.CancelFunc) error {
+	conn, err := dialNamedPipe(ctx, pb.path)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("pipe: failed to dial %s: %w", pb.path, err)
+	}
+	defer conn.Close()
+	pb.logger.Infow("Connected to named pipe", "path", pb.path)
+
+	maxSize := pb.maxMessageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxMessageSize
+	}
+	initialSize := bufio.MaxScanTokenSize
+	if maxSize < initialSize {
+		initialSize = maxSize
+	}
+
+	readErrChan := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		scanner.Buffer(make([]byte, 0, initialSize), maxSize)
+		for scanner.Scan() {
+			line := scanner.Text()
+			pb.logger.Debugw("Read from pipe", "line", line)
+			select {
+			case pb.outputChan <- line:
+			case <-ctx.Done():
+				readErrChan <- ctx.Err()
+				return
+			}
+		}
+		readErrChan <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErrChan:
+			cancel()
+			if err != nil && ctx.Err() == nil {
+				pb.logger.Errorw("Lost the pipe", "err", err)
+				return err
+			}
+			pb.logger.Info("Pipe closed")
+			return nil
+		case msg, ok := <-pb.inputChan:
+			if !ok {
+				pb.logger.Info("Client input closed; closing pipe")
+				cancel()
+				return nil
+			}
+			if _, err := conn.Write([]byte(msg + "\n")); err != nil {
+				pb.logger.Errorw("Failed to write to pipe", "err", err)
+				cancel()
+				return err
+			}
+		}
+	}
+}
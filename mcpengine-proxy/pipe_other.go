@@ -0,0 +1,15 @@
+//go:build !windows
+
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+)
+
+// dialNamedPipe is the non-Windows stub; npipe endpoints only exist there.
+func dialNamedPipe(ctx context.Context, path string) (net.Conn, error) {
+	return nil, fmt.Errorf("named pipes are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,85 @@
+package mcpengine
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// ===== Named Pipe Tests =====
+
+func TestParseNpipeEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		expected string
+		wantErr  bool
+	}{
+		{
+			name:     "docker-style endpoint",
+			endpoint: "npipe:////./pipe/mcp",
+			expected: `\\.\pipe\mcp`,
+		},
+		{
+			name:     "nested name",
+			endpoint: "npipe:////./pipe/vendor/server",
+			expected: `\\.\pipe\vendor\server`,
+		},
+		{
+			name:     "wrong scheme",
+			endpoint: "http://localhost:8000",
+			wantErr:  true,
+		},
+		{
+			name:     "missing pipe name",
+			endpoint: "npipe:////./pipe/",
+			wantErr:  true,
+		},
+		{
+			name:     "not a pipe path",
+			endpoint: "npipe:///tmp/socket",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNpipeEndpoint(tt.endpoint)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConfigValidate_Pipe(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	if err := (Config{Endpoint: "npipe:////./pipe/mcp", Logger: logger}).Validate(); err != nil {
+		t.Errorf("Expected an npipe endpoint to validate, got %v", err)
+	}
+	if err := (Config{Endpoint: "npipe:///broken", Logger: logger}).Validate(); err == nil {
+		t.Error("Expected an error for a malformed npipe endpoint")
+	}
+	if err := (Config{Endpoint: "npipe:////./pipe/mcp", SSEPath: "/sse", Logger: logger}).Validate(); err == nil {
+		t.Error("Expected an error combining a pipe endpoint with an SSE path")
+	}
+}
+
+func TestDialNamedPipe_Unsupported(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("pipes are supported on Windows")
+	}
+	if _, err := dialNamedPipe(context.Background(), `\\.\pipe\mcp`); err == nil {
+		t.Error("Expected an error on a platform without named pipes")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package mcpengine
+
+import (
+	"context"
+	"net"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// dialNamedPipe connects to a Windows named pipe. go-winio handles the
+// overlapped I/O so reads and writes can proceed concurrently.
+func dialNamedPipe(ctx context.Context, path string) (net.Conn, error) {
+	return winio.DialPipeContext(ctx, path)
+}
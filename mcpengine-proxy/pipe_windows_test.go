@@ -0,0 +1,112 @@
+//go:build windows
+
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"github.com/Microsoft/go-winio"
+
+	"go.uber.org/zap"
+)
+
+// ===== Named Pipe Tests (Windows) =====
+
+// servePipeEcho listens on the given pipe and echoes lines back, standing
+// in for a local server that exposes a pipe.
+func servePipeEcho(t *testing.T, path string) net.Listener {
+	t.Helper()
+	listener, err := winio.ListenPipe(path, nil)
+	if err != nil {
+		t.Fatalf("Failed to listen on pipe: %v", err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					fmt.Fprintf(conn, "%s\n", scanner.Text())
+				}
+			}(conn)
+		}
+	}()
+	return listener
+}
+
+func TestPipeBridge_Echo(t *testing.T) {
+	path := fmt.Sprintf(`\\.\pipe\mcpengine-test-%d`, time.Now().UnixNano())
+	listener := servePipeEcho(t, path)
+	defer listener.Close()
+
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	bridge := NewPipeBridge(path, inputChan, outputChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bridge.Run(ctx, cancel)
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	inputChan <- request
+	select {
+	case got := <-outputChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back through the pipe")
+	}
+}
+
+func TestPipeTransport_Engine(t *testing.T) {
+	path := fmt.Sprintf(`\\.\pipe\mcpengine-test-%d`, time.Now().UnixNano())
+	listener := servePipeEcho(t, path)
+	defer listener.Close()
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	input := mcpenginetest.NewBlockReader(strings.NewReader(request + "\n"))
+	outputReader, outputWriter := io.Pipe()
+	engine, err := New(Config{
+		Endpoint: "npipe:////./pipe/" + strings.TrimPrefix(path, `\\.\pipe\`),
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+		Input:    input,
+		Output:   outputWriter,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	lineChan := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		if scanner.Scan() {
+			lineChan <- scanner.Text()
+		}
+	}()
+	select {
+	case got := <-lineChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back through the engine")
+	}
+}
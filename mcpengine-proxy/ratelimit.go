@@ -0,0 +1,201 @@
+package mcpengine
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimitAction selects what happens to a call that exceeds its bucket.
+type RateLimitAction string
+
+const (
+	// RateLimitWait delays the call until a token is available, queueing
+	// traffic behind the limit. This is the default.
+	RateLimitWait RateLimitAction = "wait"
+	// RateLimitError rejects the call immediately with a JSON-RPC error.
+	RateLimitError RateLimitAction = "error"
+)
+
+// valid reports whether a names a known action; empty selects the default.
+func (a RateLimitAction) valid() bool {
+	switch a {
+	case "", RateLimitWait, RateLimitError:
+		return true
+	}
+	return false
+}
+
+// MethodRateLimit is a per-method token bucket override.
+type MethodRateLimit struct {
+	// RequestsPerSecond refills this method's bucket.
+	RequestsPerSecond float64
+	// Burst is the bucket's capacity. Zero defaults to RequestsPerSecond
+	// rounded up, with a floor of one.
+	Burst int
+}
+
+// RateLimitConfig throttles outgoing requests with token buckets, so a
+// runaway agent loop can't hammer a metered upstream API.
+type RateLimitConfig struct {
+	// RequestsPerSecond refills the global bucket, shared by every method
+	// without an override. Zero leaves such methods unthrottled.
+	RequestsPerSecond float64
+	// Burst is the global bucket's capacity. Zero defaults to
+	// RequestsPerSecond rounded up, with a floor of one.
+	Burst int
+	// PerMethod sets stricter or looser buckets for individual JSON-RPC
+	// methods, e.g. "tools/call".
+	PerMethod map[string]MethodRateLimit
+	// OnLimit selects the over-limit behavior; empty means RateLimitWait.
+	OnLimit RateLimitAction
+}
+
+// validate rejects configurations that would throttle nothing or everything.
+func (rc *RateLimitConfig) validate() error {
+	if rc == nil {
+		return nil
+	}
+	if rc.RequestsPerSecond < 0 {
+		return fmt.Errorf("RateLimit.RequestsPerSecond must not be negative, got %v", rc.RequestsPerSecond)
+	}
+	if rc.Burst < 0 {
+		return fmt.Errorf("RateLimit.Burst must not be negative, got %d", rc.Burst)
+	}
+	if rc.RequestsPerSecond == 0 && len(rc.PerMethod) == 0 {
+		return fmt.Errorf("RateLimit requires RequestsPerSecond or PerMethod limits")
+	}
+	for method, limit := range rc.PerMethod {
+		if limit.RequestsPerSecond <= 0 {
+			return fmt.Errorf("RateLimit.PerMethod[%q].RequestsPerSecond must be positive, got %v", method, limit.RequestsPerSecond)
+		}
+		if limit.Burst < 0 {
+			return fmt.Errorf("RateLimit.PerMethod[%q].Burst must not be negative, got %d", method, limit.Burst)
+		}
+	}
+	if !rc.OnLimit.valid() {
+		return fmt.Errorf("unknown RateLimit.OnLimit %q", rc.OnLimit)
+	}
+	return nil
+}
+
+// tokenBucket is a standard token bucket: capacity burst, refilled at rate
+// tokens per second.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	capacity := float64(burst)
+	if capacity <= 0 {
+		capacity = math.Max(math.Ceil(rate), 1)
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  capacity,
+		tokens: capacity,
+		last:   time.Now(),
+	}
+}
+
+// refill credits tokens for the time elapsed. Called with the lock held.
+func (tb *tokenBucket) refill(now time.Time) {
+	tb.tokens = math.Min(tb.burst, tb.tokens+tb.rate*now.Sub(tb.last).Seconds())
+	tb.last = now
+}
+
+// allow takes a token if one is available, without waiting.
+func (tb *tokenBucket) allow() bool {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill(time.Now())
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// reserve consumes the next token unconditionally, returning how long the
+// caller must wait before acting on it.
+func (tb *tokenBucket) reserve() time.Duration {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.refill(time.Now())
+	tb.tokens--
+	if tb.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-tb.tokens / tb.rate * float64(time.Second))
+}
+
+// rateLimiter enforces the configured buckets as outbound middleware.
+// Responses and notifications without a method pass through untouched.
+type rateLimiter struct {
+	global    *tokenBucket
+	perMethod map[string]*tokenBucket
+	action    RateLimitAction
+	errChan   chan string
+	logger    Logger
+}
+
+// newRateLimiter builds the buckets from cfg. Over-limit errors under
+// RateLimitError are answered on errChan.
+func newRateLimiter(cfg RateLimitConfig, errChan chan string, logger Logger) *rateLimiter {
+	rl := &rateLimiter{
+		action:  cfg.OnLimit,
+		errChan: errChan,
+		logger:  logger,
+	}
+	if cfg.RequestsPerSecond > 0 {
+		rl.global = newTokenBucket(cfg.RequestsPerSecond, cfg.Burst)
+	}
+	if len(cfg.PerMethod) > 0 {
+		rl.perMethod = make(map[string]*tokenBucket, len(cfg.PerMethod))
+		for method, limit := range cfg.PerMethod {
+			rl.perMethod[method] = newTokenBucket(limit.RequestsPerSecond, limit.Burst)
+		}
+	}
+	return rl
+}
+
+// outbound is the middleware: it throttles client calls per their method's
+// bucket, delaying or rejecting over-limit traffic per the action.
+func (rl *rateLimiter) outbound(msg string) (string, error) {
+	method := getMessageMethod(msg)
+	if method == "" {
+		return msg, nil
+	}
+	bucket := rl.global
+	if b, ok := rl.perMethod[method]; ok {
+		bucket = b
+	}
+	if bucket == nil {
+		return msg, nil
+	}
+	if rl.action == RateLimitError {
+		if !bucket.allow() {
+			rl.logger.Warnw("Rate limit exceeded; rejecting request", "method", method)
+			if id := getMessageID(msg, rl.logger); id != nil {
+				select {
+				case rl.errChan <- jsonRPCErrorString(id, rateLimitedErrorCode,
+					"rate limit exceeded",
+					map[string]interface{}{"method": method}, rl.logger):
+				default:
+				}
+			}
+			return "", fmt.Errorf("rate limit exceeded for %s", method)
+		}
+		return msg, nil
+	}
+	if delay := bucket.reserve(); delay > 0 {
+		rl.logger.Debugw("Rate limit reached; delaying request", "method", method, "delay", delay)
+		time.Sleep(delay)
+	}
+	return msg, nil
+}
@@ -0,0 +1,121 @@
+package mcpengine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Rate Limit Tests =====
+
+func TestTokenBucket_AllowAndRefill(t *testing.T) {
+	bucket := newTokenBucket(10, 2)
+	if !bucket.allow() || !bucket.allow() {
+		t.Fatal("Expected the full burst available")
+	}
+	if bucket.allow() {
+		t.Error("Expected the bucket drained")
+	}
+	time.Sleep(150 * time.Millisecond) // Refills at 10/s.
+	if !bucket.allow() {
+		t.Error("Expected a token after refill")
+	}
+}
+
+func TestRateLimiter_WaitDelaysOverLimitCalls(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{
+		RequestsPerSecond: 5,
+		Burst:             1,
+	}, nil, NewZapLogger(zap.NewNop().Sugar()))
+
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		if _, err := limiter.outbound(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call"}`); err != nil {
+			t.Fatalf("Expected the call queued, got %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected the second call delayed ~200ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimiter_ErrorRejectsOverLimitCalls(t *testing.T) {
+	errChan := make(chan string, 2)
+	limiter := newRateLimiter(RateLimitConfig{
+		RequestsPerSecond: 1,
+		Burst:             1,
+		OnLimit:           RateLimitError,
+	}, errChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	if _, err := limiter.outbound(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call"}`); err != nil {
+		t.Fatalf("Expected the first call allowed, got %v", err)
+	}
+	if _, err := limiter.outbound(`{"jsonrpc": "2.0", "id": 2, "method": "tools/call"}`); err == nil {
+		t.Fatal("Expected the second call rejected")
+	}
+	select {
+	case msg := <-errChan:
+		if !strings.Contains(msg, "-32007") || !strings.Contains(msg, `"id":2`) {
+			t.Errorf("Expected a rate-limit error for id 2, got %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Rejected call never answered with an error")
+	}
+}
+
+func TestRateLimiter_PerMethodOverridesGlobal(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{
+		RequestsPerSecond: 1,
+		Burst:             1,
+		OnLimit:           RateLimitError,
+		PerMethod: map[string]MethodRateLimit{
+			"ping": {RequestsPerSecond: 100, Burst: 100},
+		},
+	}, make(chan string, 8), NewZapLogger(zap.NewNop().Sugar()))
+
+	// Pings ride their own generous bucket.
+	for i := 0; i < 10; i++ {
+		if _, err := limiter.outbound(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`); err != nil {
+			t.Fatalf("Expected pings under their own limit, got %v", err)
+		}
+	}
+	// Responses pass through without consuming tokens.
+	if _, err := limiter.outbound(`{"jsonrpc": "2.0", "id": 3, "result": {}}`); err != nil {
+		t.Fatalf("Expected responses untouched, got %v", err)
+	}
+	// The global bucket still has its single token.
+	if _, err := limiter.outbound(`{"jsonrpc": "2.0", "id": 2, "method": "tools/call"}`); err != nil {
+		t.Fatalf("Expected the global bucket untouched by pings, got %v", err)
+	}
+}
+
+func TestRateLimit_ConfigValidation(t *testing.T) {
+	base := Config{
+		Endpoint: "http://localhost:8080",
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+	}
+
+	cfg := base
+	cfg.RateLimit = &RateLimitConfig{}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an empty RateLimit rejected")
+	}
+
+	cfg = base
+	cfg.RateLimit = &RateLimitConfig{RequestsPerSecond: 5, OnLimit: "drop"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an unknown OnLimit rejected")
+	}
+
+	cfg = base
+	cfg.RateLimit = &RateLimitConfig{
+		PerMethod: map[string]MethodRateLimit{"tools/call": {RequestsPerSecond: 2}},
+		OnLimit:   RateLimitError,
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid per-method-only config, got %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package mcpengine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// redactedPlaceholder replaces masked secrets in log output.
+const redactedPlaceholder = "[REDACTED]"
+
+// bearerPattern matches bearer credentials wherever they appear — an
+// Authorization header echoed in a debug line, or a token pasted into a
+// message — keeping the scheme and masking the credential itself.
+var bearerPattern = regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`)
+
+// defaultRedactedFields are the JSON fields masked out of the box: the
+// credentials the proxy itself handles plus the usual suspects.
+var defaultRedactedFields = []string{
+	"access_token",
+	"refresh_token",
+	"client_secret",
+	"api_key",
+	"password",
+	"secret",
+}
+
+// Redactor masks secrets in strings before they reach a log sink: bearer
+// tokens and the values of a set of JSON fields, matched textually so raw
+// message payloads don't need parsing. Fields may be given as dotted paths
+// ("params.password"); only the final segment is matched, since the raw text
+// carries no nesting context.
+type Redactor struct {
+	fieldPatterns []*regexp.Regexp
+}
+
+// NewRedactor builds a Redactor masking the default fields plus any extras.
+func NewRedactor(extraFields ...string) *Redactor {
+	fields := append([]string{}, defaultRedactedFields...)
+	fields = append(fields, extraFields...)
+	patterns := make([]*regexp.Regexp, 0, len(fields))
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if i := strings.LastIndex(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field == "" || seen[field] {
+			continue
+		}
+		seen[field] = true
+		// Masks "field": "value", "field": 123 and Field=value styles.
+		patterns = append(patterns, regexp.MustCompile(
+			`(?i)("`+regexp.QuoteMeta(field)+`"\s*:\s*)("(?:[^"\\]|\\.)*"|[^,}\s]+)`))
+	}
+	return &Redactor{fieldPatterns: patterns}
+}
+
+// Redact returns s with all recognized secrets masked.
+func (r *Redactor) Redact(s string) string {
+	s = bearerPattern.ReplaceAllString(s, "${1}"+redactedPlaceholder)
+	for _, pattern := range r.fieldPatterns {
+		s = pattern.ReplaceAllString(s, `${1}"`+redactedPlaceholder+`"`)
+	}
+	return s
+}
+
+// redactArgs masks every string argument, leaving other types alone; the
+// secrets the proxy handles all travel as strings.
+func (r *Redactor) redactArgs(args []interface{}) []interface{} {
+	redacted := make([]interface{}, len(args))
+	for i, arg := range args {
+		if s, ok := arg.(string); ok {
+			redacted[i] = r.Redact(s)
+		} else {
+			redacted[i] = arg
+		}
+	}
+	return redacted
+}
+
+// redactingLogger wraps a Logger so messages and values pass through the
+// Redactor before hitting any sink.
+type redactingLogger struct {
+	inner    Logger
+	redactor *Redactor
+}
+
+// NewRedactingLogger layers secret redaction over logger. Debug logging
+// prints full JSON-RPC messages, so without this layer a -debug session
+// would persist bearer tokens and client secrets to the log file.
+func NewRedactingLogger(logger Logger, redactor *Redactor) Logger {
+	return redactingLogger{inner: logger, redactor: redactor}
+}
+
+func (r redactingLogger) Debug(args ...interface{}) { r.inner.Debug(r.redactor.redactArgs(args)...) }
+func (r redactingLogger) Info(args ...interface{})  { r.inner.Info(r.redactor.redactArgs(args)...) }
+func (r redactingLogger) Warn(args ...interface{})  { r.inner.Warn(r.redactor.redactArgs(args)...) }
+func (r redactingLogger) Error(args ...interface{}) { r.inner.Error(r.redactor.redactArgs(args)...) }
+
+// The printf variants format first so secrets interpolated by the template
+// are masked too.
+
+func (r redactingLogger) Debugf(template string, args ...interface{}) {
+	r.inner.Debug(r.redactor.Redact(fmt.Sprintf(template, args...)))
+}
+func (r redactingLogger) Infof(template string, args ...interface{}) {
+	r.inner.Info(r.redactor.Redact(fmt.Sprintf(template, args...)))
+}
+func (r redactingLogger) Warnf(template string, args ...interface{}) {
+	r.inner.Warn(r.redactor.Redact(fmt.Sprintf(template, args...)))
+}
+func (r redactingLogger) Errorf(template string, args ...interface{}) {
+	r.inner.Error(r.redactor.Redact(fmt.Sprintf(template, args...)))
+}
+
+func (r redactingLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	r.inner.Debugw(r.redactor.Redact(msg), r.redactor.redactArgs(keysAndValues)...)
+}
+func (r redactingLogger) Infow(msg string, keysAndValues ...interface{}) {
+	r.inner.Infow(r.redactor.Redact(msg), r.redactor.redactArgs(keysAndValues)...)
+}
+func (r redactingLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	r.inner.Warnw(r.redactor.Redact(msg), r.redactor.redactArgs(keysAndValues)...)
+}
+func (r redactingLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	r.inner.Errorw(r.redactor.Redact(msg), r.redactor.redactArgs(keysAndValues)...)
+}
+
+func (r redactingLogger) With(keysAndValues ...interface{}) Logger {
+	return redactingLogger{
+		inner:    r.inner.With(r.redactor.redactArgs(keysAndValues)...),
+		redactor: r.redactor,
+	}
+}
@@ -0,0 +1,156 @@
+package mcpengine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/zalando/go-keyring"
+)
+
+// ClientCredentials are OAuth client credentials obtained through dynamic
+// client registration (RFC 7591).
+type ClientCredentials struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// ClientStore persists dynamically registered client credentials so the
+// proxy re-uses one registration across restarts instead of creating a new
+// client on every launch. LoadClient returns (nil, nil) when nothing has
+// been stored yet.
+type ClientStore interface {
+	LoadClient() (*ClientCredentials, error)
+	SaveClient(creds *ClientCredentials) error
+}
+
+// KeychainClientStore stores registered client credentials in the OS
+// credential store alongside KeychainTokenStore's tokens, under a separate
+// entry per upstream server.
+type KeychainClientStore struct {
+	service string
+	account string
+}
+
+// NewKeychainClientStore creates a store keyed by account, typically the
+// upstream server's host.
+func NewKeychainClientStore(account string) *KeychainClientStore {
+	return &KeychainClientStore{
+		service: keychainService,
+		account: account + "/client",
+	}
+}
+
+// LoadClient retrieves the stored credentials, or (nil, nil) if none exist.
+func (s *KeychainClientStore) LoadClient() (*ClientCredentials, error) {
+	data, err := keyring.Get(s.service, s.account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client credentials from keychain: %w", err)
+	}
+	var creds ClientCredentials
+	if err := json.Unmarshal([]byte(data), &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse stored client credentials: %w", err)
+	}
+	return &creds, nil
+}
+
+// DeleteClient removes the stored credentials. Deleting credentials that
+// don't exist is not an error.
+func (s *KeychainClientStore) DeleteClient() error {
+	err := keyring.Delete(s.service, s.account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete client credentials from keychain: %w", err)
+	}
+	return nil
+}
+
+// SaveClient writes the credentials to the credential store.
+func (s *KeychainClientStore) SaveClient(creds *ClientCredentials) error {
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to serialize client credentials: %w", err)
+	}
+	if err := keyring.Set(s.service, s.account, string(data)); err != nil {
+		return fmt.Errorf("failed to write client credentials to keychain: %w", err)
+	}
+	return nil
+}
+
+// clientRegistrationRequest is the RFC 7591 registration request body. The
+// proxy registers as a public client: PKCE secures the code exchange, so no
+// client secret is required at the token endpoint.
+type clientRegistrationRequest struct {
+	ClientName              string   `json:"client_name"`
+	RedirectURIs            []string `json:"redirect_uris"`
+	GrantTypes              []string `json:"grant_types"`
+	ResponseTypes           []string `json:"response_types"`
+	TokenEndpointAuthMethod string   `json:"token_endpoint_auth_method"`
+}
+
+// registerClient performs dynamic client registration (RFC 7591) against
+// the authorization server's registration endpoint and adopts the returned
+// credentials, persisting them when a ClientStore is configured.
+func (a *AuthManager) registerClient(ctx context.Context) error {
+	if a.oidcConfig.RegistrationEndpoint == "" {
+		return fmt.Errorf("authorization server does not advertise a registration endpoint; configure a ClientID")
+	}
+
+	regReq := clientRegistrationRequest{
+		ClientName:              clientName,
+		RedirectURIs:            []string{a.redirectURL},
+		GrantTypes:              []string{"authorization_code", "refresh_token"},
+		ResponseTypes:           []string{"code"},
+		TokenEndpointAuthMethod: "none",
+	}
+	body, err := json.Marshal(regReq)
+	if err != nil {
+		return fmt.Errorf("failed to serialize registration request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.oidcConfig.RegistrationEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create registration request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("registration request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registration failed with status %s", resp.Status)
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read registration response: %w", err)
+	}
+	var creds ClientCredentials
+	if err := json.Unmarshal(respBody, &creds); err != nil {
+		return fmt.Errorf("failed to parse registration response: %w", err)
+	}
+	if creds.ClientID == "" {
+		return fmt.Errorf("registration response contains no client_id")
+	}
+
+	a.logger.Infow("Registered OAuth client dynamically", "client-id", creds.ClientID)
+	a.clientID = creds.ClientID
+	a.clientSecret = creds.ClientSecret
+	if a.clientStore != nil {
+		if err := a.clientStore.SaveClient(&creds); err != nil {
+			a.logger.Warnf("Failed to persist registered client: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+	"go.uber.org/zap"
+)
+
+// ===== Dynamic Client Registration Tests =====
+
+func TestKeychainClientStore_SaveAndLoad(t *testing.T) {
+	keyring.MockInit()
+
+	store := NewKeychainClientStore("server.example.com")
+	loaded, err := store.LoadClient()
+	if err != nil {
+		t.Fatalf("LoadClient failed: %v", err)
+	}
+	if loaded != nil {
+		t.Fatalf("Expected no stored credentials, got %+v", loaded)
+	}
+
+	creds := &ClientCredentials{ClientID: "dyn-client", ClientSecret: "dyn-secret"}
+	if err := store.SaveClient(creds); err != nil {
+		t.Fatalf("SaveClient failed: %v", err)
+	}
+	loaded, err = store.LoadClient()
+	if err != nil {
+		t.Fatalf("LoadClient failed: %v", err)
+	}
+	if loaded == nil || loaded.ClientID != "dyn-client" || loaded.ClientSecret != "dyn-secret" {
+		t.Errorf("Expected stored credentials back, got %+v", loaded)
+	}
+}
+
+func TestRegisterClient(t *testing.T) {
+	var registration clientRegistrationRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/register" {
+			http.NotFound(w, r)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &registration); err != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"client_id": "registered-client", "client_secret": ""}`))
+	}))
+	defer ts.Close()
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := NewAuthManager(nil, logger)
+	auth.oidcConfig = OpenIDConfiguration{RegistrationEndpoint: ts.URL + "/register"}
+
+	if err := auth.registerClient(context.Background()); err != nil {
+		t.Fatalf("registerClient failed: %v", err)
+	}
+	if auth.clientID != "registered-client" {
+		t.Errorf("Expected clientID %q, got %q", "registered-client", auth.clientID)
+	}
+	if registration.ClientName != clientName {
+		t.Errorf("Expected client_name %q, got %q", clientName, registration.ClientName)
+	}
+	if len(registration.RedirectURIs) != 1 || registration.RedirectURIs[0] != auth.redirectURL {
+		t.Errorf("Expected redirect URI %q, got %v", auth.redirectURL, registration.RedirectURIs)
+	}
+	if registration.TokenEndpointAuthMethod != "none" {
+		t.Errorf("Expected a public client registration, got auth method %q", registration.TokenEndpointAuthMethod)
+	}
+}
+
+func TestRegisterClient_PersistsCredentials(t *testing.T) {
+	keyring.MockInit()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"client_id": "registered-client"}`))
+	}))
+	defer ts.Close()
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	store := NewKeychainClientStore("server.example.com")
+	auth := NewAuthManager(&AuthConfig{ClientStore: store}, logger)
+	auth.oidcConfig = OpenIDConfiguration{RegistrationEndpoint: ts.URL}
+
+	if err := auth.registerClient(context.Background()); err != nil {
+		t.Fatalf("registerClient failed: %v", err)
+	}
+
+	// A new AuthManager picks the registration up from the store.
+	fresh := NewAuthManager(&AuthConfig{ClientStore: store}, logger)
+	if fresh.clientID != "registered-client" {
+		t.Errorf("Expected stored clientID to be loaded, got %q", fresh.clientID)
+	}
+}
+
+func TestRegisterClient_Errors(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	t.Run("no registration endpoint", func(t *testing.T) {
+		auth := NewAuthManager(nil, logger)
+		if err := auth.registerClient(context.Background()); err == nil {
+			t.Error("Expected an error when the AS has no registration endpoint")
+		}
+	})
+
+	t.Run("registration rejected", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "nope", http.StatusForbidden)
+		}))
+		defer ts.Close()
+
+		auth := NewAuthManager(nil, logger)
+		auth.oidcConfig = OpenIDConfiguration{RegistrationEndpoint: ts.URL}
+		if err := auth.registerClient(context.Background()); err == nil {
+			t.Error("Expected an error when registration is rejected")
+		}
+	})
+
+	t.Run("missing client_id", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		}))
+		defer ts.Close()
+
+		auth := NewAuthManager(nil, logger)
+		auth.oidcConfig = OpenIDConfiguration{RegistrationEndpoint: ts.URL}
+		if err := auth.registerClient(context.Background()); err == nil {
+			t.Error("Expected an error for a response without client_id")
+		}
+	})
+}
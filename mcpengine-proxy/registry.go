@@ -0,0 +1,150 @@
+package mcpengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Registry fetches server definitions from a remote index, so new
+// official MCP services can be installed without shipping a new binary.
+// The index is a YAML document of the same shape as the servers file
+// (name to flag options), published alongside a <url>.sha256 sidecar whose
+// first field is the hex digest of the index. Each successful fetch is
+// cached locally, and the cache answers when the registry is unreachable.
+type Registry struct {
+	// URL of the index document.
+	URL string
+	// HTTPClient performs the fetches. Nil uses a short-timeout default.
+	HTTPClient *http.Client
+	// CachePath is where verified indexes are kept. Empty derives a path
+	// under the user cache directory from the URL.
+	CachePath string
+}
+
+// registryMaxIndexSize bounds the index document, which is a listing, not
+// a payload.
+const registryMaxIndexSize = 1 << 20
+
+// NewRegistry builds a registry for the given index URL with the default
+// cache location.
+func NewRegistry(url string) *Registry {
+	return &Registry{URL: url}
+}
+
+// cachePath resolves where this registry's verified index is cached.
+func (r *Registry) cachePath() (string, error) {
+	if r.CachePath != "" {
+		return r.CachePath, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("registry: failed to locate the cache directory: %w", err)
+	}
+	digest := sha256.Sum256([]byte(r.URL))
+	name := fmt.Sprintf("registry-%s.yaml", hex.EncodeToString(digest[:8]))
+	return filepath.Join(dir, "mcpengine", name), nil
+}
+
+// fetchBytes retrieves one document, bounded by the index size cap.
+func (r *Registry) fetchBytes(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("registry: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("registry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry: %s returned %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, registryMaxIndexSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("registry: %w", err)
+	}
+	if len(data) > registryMaxIndexSize {
+		return nil, fmt.Errorf("registry: index at %s exceeds %d bytes", url, registryMaxIndexSize)
+	}
+	return data, nil
+}
+
+// fetchVerified downloads the index and its checksum sidecar and verifies
+// them against each other.
+func (r *Registry) fetchVerified(ctx context.Context, client *http.Client) ([]byte, error) {
+	index, err := r.fetchBytes(ctx, client, r.URL)
+	if err != nil {
+		return nil, err
+	}
+	sidecar, err := r.fetchBytes(ctx, client, r.URL+".sha256")
+	if err != nil {
+		return nil, fmt.Errorf("registry: missing checksum: %w", err)
+	}
+	// The sidecar follows sha256sum's format: the digest is the first
+	// whitespace-separated field.
+	want := strings.Fields(string(sidecar))
+	if len(want) == 0 {
+		return nil, fmt.Errorf("registry: empty checksum at %s.sha256", r.URL)
+	}
+	digest := sha256.Sum256(index)
+	if got := hex.EncodeToString(digest[:]); !strings.EqualFold(got, want[0]) {
+		return nil, fmt.Errorf("registry: checksum mismatch for %s: index is %s, sidecar says %s", r.URL, got, want[0])
+	}
+	return index, nil
+}
+
+// parseIndex turns the raw index into server definitions, reusing the
+// servers-file format.
+func parseIndex(data []byte) (map[string]ServerOptions, error) {
+	servers, err := parseServerOptions(data)
+	if err != nil {
+		return nil, fmt.Errorf("registry: invalid index: %w", err)
+	}
+	return servers, nil
+}
+
+// Fetch retrieves and verifies the registry index, caching it on success.
+// When the registry is unreachable, a previously cached index answers
+// instead; fromCache reports which happened.
+func (r *Registry) Fetch(ctx context.Context) (servers map[string]ServerOptions, fromCache bool, err error) {
+	client := r.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	index, fetchErr := r.fetchVerified(ctx, client)
+	if fetchErr == nil {
+		servers, err := parseIndex(index)
+		if err != nil {
+			return nil, false, err
+		}
+		if path, err := r.cachePath(); err == nil {
+			if err := os.MkdirAll(filepath.Dir(path), 0o700); err == nil {
+				os.WriteFile(path, index, 0o600)
+			}
+		}
+		return servers, false, nil
+	}
+
+	path, err := r.cachePath()
+	if err != nil {
+		return nil, false, fetchErr
+	}
+	cached, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fetchErr
+	}
+	servers, err = parseIndex(cached)
+	if err != nil {
+		return nil, false, fetchErr
+	}
+	return servers, true, nil
+}
@@ -0,0 +1,130 @@
+package mcpengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ===== Registry Tests =====
+
+const registryTestIndex = `acme:
+  host: https://mcp.acme.example.com
+  mode: streamable
+`
+
+// serveRegistry serves an index and its checksum sidecar; a wrong digest
+// can be injected to test verification.
+func serveRegistry(t *testing.T, index, digest string) *httptest.Server {
+	t.Helper()
+	if digest == "" {
+		sum := sha256.Sum256([]byte(index))
+		digest = hex.EncodeToString(sum[:])
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			fmt.Fprint(w, index)
+		case "/index.yaml.sha256":
+			fmt.Fprintf(w, "%s  index.yaml\n", digest)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRegistry_Fetch(t *testing.T) {
+	ts := serveRegistry(t, registryTestIndex, "")
+	registry := &Registry{
+		URL:       ts.URL + "/index.yaml",
+		CachePath: filepath.Join(t.TempDir(), "cache.yaml"),
+	}
+	servers, fromCache, err := registry.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to fetch: %v", err)
+	}
+	if fromCache {
+		t.Error("Expected a live fetch, not the cache")
+	}
+	if servers["acme"]["host"] != "https://mcp.acme.example.com" {
+		t.Errorf("Unexpected index contents: %v", servers)
+	}
+}
+
+func TestRegistry_ChecksumMismatch(t *testing.T) {
+	ts := serveRegistry(t, registryTestIndex, strings.Repeat("0", 64))
+	registry := &Registry{
+		URL:       ts.URL + "/index.yaml",
+		CachePath: filepath.Join(t.TempDir(), "cache.yaml"),
+	}
+	if _, _, err := registry.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+}
+
+func TestRegistry_MissingChecksum(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/index.yaml" {
+			fmt.Fprint(w, registryTestIndex)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+	registry := &Registry{
+		URL:       ts.URL + "/index.yaml",
+		CachePath: filepath.Join(t.TempDir(), "cache.yaml"),
+	}
+	if _, _, err := registry.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Expected a missing-checksum error, got %v", err)
+	}
+}
+
+func TestRegistry_CacheFallback(t *testing.T) {
+	ts := serveRegistry(t, registryTestIndex, "")
+	registry := &Registry{
+		URL:       ts.URL + "/index.yaml",
+		CachePath: filepath.Join(t.TempDir(), "cache.yaml"),
+	}
+	if _, _, err := registry.Fetch(context.Background()); err != nil {
+		t.Fatalf("Failed the priming fetch: %v", err)
+	}
+
+	// With the registry down, the verified cache answers.
+	ts.Close()
+	servers, fromCache, err := registry.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the cache to answer, got %v", err)
+	}
+	if !fromCache {
+		t.Error("Expected the result to come from the cache")
+	}
+	if servers["acme"]["mode"] != "streamable" {
+		t.Errorf("Unexpected cached contents: %v", servers)
+	}
+}
+
+func TestInstallServerFrom_Registry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.yaml")
+	source := map[string]ServerOptions{
+		"acme": {"host": "https://mcp.acme.example.com"},
+	}
+	if err := InstallServerFrom(path, "acme", source, false); err != nil {
+		t.Fatalf("Failed to install: %v", err)
+	}
+	servers, err := LoadServers(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if servers["acme"]["host"] != "https://mcp.acme.example.com" {
+		t.Errorf("Unexpected installed entry: %v", servers["acme"])
+	}
+}
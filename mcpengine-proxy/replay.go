@@ -0,0 +1,107 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// replayErrorCode is returned for requests with no recorded response.
+const replayErrorCode = -32001
+
+// Replayer serves recorded server responses instead of contacting the
+// network. The recording is a session captured via MirrorWriters: one JSON
+// message per line, as the server sent them. Responses are matched to
+// incoming requests by their JSON-RPC id, so a recorded session can be
+// replayed deterministically to reproduce an issue or drive a regression
+// test. Requests with no recorded response receive a JSON-RPC error rather
+// than hanging the client.
+type Replayer struct {
+	inputChan  chan string
+	outputChan chan string
+	logger     Logger
+
+	// responses is keyed by the raw JSON id bytes so string and numeric
+	// ids both match exactly.
+	responses map[string]string
+}
+
+// NewReplayer parses the recording and constructs a Replayer. It fails if
+// the recording contains a line that is not valid JSON.
+func NewReplayer(recording io.Reader, inputChan, outputChan chan string, logger Logger) (*Replayer, error) {
+	responses := make(map[string]string)
+	scanner := bufio.NewScanner(recording)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), defaultMaxMessageSize)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		var msg map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			return nil, fmt.Errorf("invalid recording: line %d is not JSON: %w", lineNo, err)
+		}
+		id := getMessageID(line, logger)
+		if id == nil {
+			// Server notifications carry no id and can't be matched to a
+			// request; they are dropped on replay.
+			logger.Debugw("Skipping recorded message without id", "line", lineNo)
+			continue
+		}
+		if _, exists := responses[string(id)]; exists {
+			logger.Warnw("Recording has multiple responses for id; keeping the first", "id", string(id))
+			continue
+		}
+		responses[string(id)] = line
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	logger.Infow("Loaded recording", "responses", len(responses))
+	return &Replayer{
+		inputChan:  inputChan,
+		outputChan: outputChan,
+		logger:     logger,
+		responses:  responses,
+	}, nil
+}
+
+// Run answers each incoming request from the recording. It stops when the
+// input channel closes or the context is cancelled.
+func (rp *Replayer) Run(ctx context.Context, cancel context.CancelFunc) error {
+	rp.logger.Debug("Starting Replayer")
+	for {
+		select {
+		case <-ctx.Done():
+			rp.logger.Info("Replayer canceled")
+			return ctx.Err()
+		case msg, ok := <-rp.inputChan:
+			if !ok {
+				rp.logger.Info("Input channel closed, terminating Replayer")
+				return nil
+			}
+			id := getMessageID(msg, rp.logger)
+			if id == nil {
+				// Client notifications expect no response.
+				rp.logger.Debugw("Ignoring notification in replay mode", "msg", msg)
+				continue
+			}
+			response, found := rp.responses[string(id)]
+			if !found {
+				rp.logger.Warnw("No recorded response for request", "id", string(id))
+				response = jsonRPCErrorString(id, replayErrorCode,
+					"no recorded response for this request", nil, rp.logger)
+			}
+			select {
+			case rp.outputChan <- response:
+			case <-ctx.Done():
+				rp.logger.Info("Replayer canceled")
+				return ctx.Err()
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package mcpengine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Replayer Tests =====
+
+func runReplayer(t *testing.T, recording string, inputs []string) chan string {
+	t.Helper()
+	inputChan := make(chan string, len(inputs))
+	outputChan := make(chan string, 10)
+	for _, msg := range inputs {
+		inputChan <- msg
+	}
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	replayer, err := NewReplayer(strings.NewReader(recording), inputChan, outputChan, logger)
+	if err != nil {
+		t.Fatalf("NewReplayer failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	if err := replayer.Run(ctx, cancel); err != nil {
+		t.Fatalf("Replayer Run returned error: %v", err)
+	}
+	return outputChan
+}
+
+func TestReplayer_MatchesByID(t *testing.T) {
+	recording := `{"jsonrpc": "2.0", "id": 1, "result": "first"}
+{"jsonrpc": "2.0", "id": 2, "result": "second"}
+`
+	// Requests arrive in the opposite order of the recording; matching is
+	// by id, not by position.
+	outputChan := runReplayer(t, recording, []string{
+		`{"jsonrpc": "2.0", "id": 2, "method": "tools/list"}`,
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`,
+	})
+
+	expected := []string{
+		`{"jsonrpc": "2.0", "id": 2, "result": "second"}`,
+		`{"jsonrpc": "2.0", "id": 1, "result": "first"}`,
+	}
+	for i, want := range expected {
+		select {
+		case got := <-outputChan:
+			if got != want {
+				t.Errorf("Response %d: expected %q, got %q", i, want, got)
+			}
+		default:
+			t.Fatalf("Expected response %d on the output channel", i)
+		}
+	}
+}
+
+func TestReplayer_UnrecordedRequest(t *testing.T) {
+	recording := `{"jsonrpc": "2.0", "id": 1, "result": "only"}
+`
+	outputChan := runReplayer(t, recording, []string{
+		`{"jsonrpc": "2.0", "id": 42, "method": "tools/call"}`,
+	})
+
+	select {
+	case got := <-outputChan:
+		if !strings.Contains(got, `"id":42`) {
+			t.Errorf("Error response should carry the request id, got %q", got)
+		}
+		if !strings.Contains(got, "no recorded response") {
+			t.Errorf("Expected a replay error, got %q", got)
+		}
+	default:
+		t.Fatal("Expected an error response for the unrecorded request")
+	}
+}
+
+func TestReplayer_IgnoresNotifications(t *testing.T) {
+	recording := `{"jsonrpc": "2.0", "method": "notifications/progress"}
+{"jsonrpc": "2.0", "id": 1, "result": "ok"}
+`
+	outputChan := runReplayer(t, recording, []string{
+		`{"jsonrpc": "2.0", "method": "notifications/initialized"}`,
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`,
+	})
+
+	// Only the request gets a response; both notifications are dropped.
+	select {
+	case got := <-outputChan:
+		if !strings.Contains(got, `"result": "ok"`) {
+			t.Errorf("Expected the recorded response, got %q", got)
+		}
+	default:
+		t.Fatal("Expected a response for the request")
+	}
+	select {
+	case got := <-outputChan:
+		t.Errorf("Unexpected extra output: %q", got)
+	default:
+	}
+}
+
+func TestNewReplayer_InvalidRecording(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	_, err := NewReplayer(strings.NewReader("not json\n"), make(chan string), make(chan string), logger)
+	if err == nil {
+		t.Error("Expected an error for a malformed recording")
+	}
+}
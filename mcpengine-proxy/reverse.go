@@ -0,0 +1,239 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReverseConfig describes reverse mode: a local stdio MCP server (spawned
+// subprocess) exposed as an SSE + POST endpoint so remote MCP clients can
+// share it. The wire format is the same one the engine consumes in forward
+// mode — an endpoint event on the SSE stream naming the POST path, then
+// message events for everything the server emits.
+//   - Addr:        Listen address, e.g. "127.0.0.1:8900". Required. Port 0
+//     picks a free port; Addr() reports the bound address.
+//   - Command:     The stdio MCP server to spawn and serve. Required.
+//   - CommandEnv:  KEY=VALUE entries appended to the child's environment.
+//   - SSEPath:     Path of the SSE subscription (default "/sse").
+//   - MCPPath:     Path POSTs are accepted on (default "/mcp").
+//   - BearerToken: When set, both paths require "Authorization: Bearer
+//     <token>".
+//   - MaxMessageSize: Caps a POSTed message's size in bytes. Zero uses the
+//     default of 10 MiB.
+type ReverseConfig struct {
+	Addr           string
+	Command        []string
+	CommandEnv     []string
+	SSEPath        string
+	MCPPath        string
+	BearerToken    string
+	MaxMessageSize int
+}
+
+// ReverseServer bridges a spawned stdio MCP server to HTTP: SSE out, POST
+// in. Every message the child emits is broadcast to all connected SSE
+// subscribers.
+type ReverseServer struct {
+	cfg    ReverseConfig
+	logger Logger
+
+	toChild   chan string
+	fromChild chan string
+
+	mu          sync.Mutex
+	boundAddr   string
+	subscribers map[chan string]struct{}
+}
+
+// NewReverseServer constructs a ReverseServer from the given config.
+func NewReverseServer(cfg ReverseConfig, logger Logger) (*ReverseServer, error) {
+	if cfg.Addr == "" {
+		return nil, fmt.Errorf("reverse: Addr must be set")
+	}
+	if len(cfg.Command) == 0 {
+		return nil, fmt.Errorf("reverse: Command must be set to the stdio server to spawn")
+	}
+	if cfg.SSEPath == "" {
+		cfg.SSEPath = "/sse"
+	}
+	if cfg.MCPPath == "" {
+		cfg.MCPPath = "/mcp"
+	}
+	return &ReverseServer{
+		cfg:         cfg,
+		logger:      logger,
+		toChild:     make(chan string, 1_000),
+		fromChild:   make(chan string, 1_000),
+		subscribers: make(map[chan string]struct{}),
+	}, nil
+}
+
+// Addr returns the listener's bound address, which differs from the
+// configured one when port 0 was requested. Empty until the listener is up.
+func (rs *ReverseServer) Addr() string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.boundAddr
+}
+
+// Run spawns the child and serves until the context is cancelled or the
+// child exits.
+func (rs *ReverseServer) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	bridge := NewStdioBridge(rs.cfg.Command, rs.toChild, rs.fromChild, rs.logger.With("worker", "stdio"))
+	bridge.SetEnv(rs.cfg.CommandEnv)
+	bridge.SetMaxMessageSize(rs.cfg.MaxMessageSize)
+	bridgeErrChan := make(chan error, 1)
+	go func() {
+		bridgeErrChan <- bridge.Run(ctx, cancel)
+	}()
+	go rs.broadcast(ctx)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(rs.cfg.SSEPath, rs.withAuth(rs.handleSSE))
+	mux.HandleFunc(rs.cfg.MCPPath, rs.withAuth(rs.handlePost))
+
+	listener, err := net.Listen("tcp", rs.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("reverse: listener on %s: %w", rs.cfg.Addr, err)
+	}
+	rs.mu.Lock()
+	rs.boundAddr = listener.Addr().String()
+	rs.mu.Unlock()
+	rs.logger.Infow("Reverse mode listening",
+		"addr", listener.Addr().String(), "sse_path", rs.cfg.SSEPath, "mcp_path", rs.cfg.MCPPath)
+
+	server := &http.Server{Handler: mux}
+	serveErrChan := make(chan error, 1)
+	go func() {
+		serveErrChan <- server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second)
+		defer shutdownCancel()
+		server.Shutdown(shutdownCtx)
+		return ctx.Err()
+	case err := <-serveErrChan:
+		return err
+	}
+}
+
+// broadcast fans the child's output out to every connected subscriber. A
+// subscriber that can't keep up loses messages rather than stalling the
+// rest.
+func (rs *ReverseServer) broadcast(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-rs.fromChild:
+			if !ok {
+				return
+			}
+			rs.mu.Lock()
+			for sub := range rs.subscribers {
+				select {
+				case sub <- msg:
+				default:
+					rs.logger.Warn("Dropping message for a slow subscriber")
+				}
+			}
+			rs.mu.Unlock()
+		}
+	}
+}
+
+// withAuth enforces the bearer token, when one is configured.
+func (rs *ReverseServer) withAuth(next http.HandlerFunc) http.HandlerFunc {
+	if rs.cfg.BearerToken == "" {
+		return next
+	}
+	expected := "Bearer " + rs.cfg.BearerToken
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != expected {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleSSE streams the endpoint event followed by everything the child
+// emits, in the same shape the forward-mode engine consumes.
+func (rs *ReverseServer) handleSSE(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	sub := make(chan string, 64)
+	rs.mu.Lock()
+	rs.subscribers[sub] = struct{}{}
+	rs.mu.Unlock()
+	defer func() {
+		rs.mu.Lock()
+		delete(rs.subscribers, sub)
+		rs.mu.Unlock()
+	}()
+	rs.logger.Infow("Subscriber connected", "remote", r.RemoteAddr)
+
+	fmt.Fprintf(w, "event: endpoint\ndata: %s\n\n", rs.cfg.MCPPath)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			rs.logger.Infow("Subscriber disconnected", "remote", r.RemoteAddr)
+			return
+		case msg := <-sub:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg)
+			flusher.Flush()
+		}
+	}
+}
+
+// handlePost accepts a JSON-RPC message and hands it to the child.
+func (rs *ReverseServer) handlePost(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	maxSize := rs.cfg.MaxMessageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxMessageSize
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(maxSize)+1))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxSize {
+		http.Error(w, "message too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	msg := strings.TrimRight(string(body), "\n")
+	if msg == "" {
+		http.Error(w, "empty message", http.StatusBadRequest)
+		return
+	}
+	select {
+	case rs.toChild <- msg:
+		w.WriteHeader(http.StatusAccepted)
+	case <-r.Context().Done():
+	}
+}
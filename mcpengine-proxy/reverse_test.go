@@ -0,0 +1,142 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Reverse Mode Tests =====
+
+// startReverse runs a ReverseServer around `cat` and returns its base URL.
+func startReverse(t *testing.T, cfg ReverseConfig) string {
+	t.Helper()
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:0"
+	}
+	if len(cfg.Command) == 0 {
+		cfg.Command = []string{"cat"}
+	}
+	server, err := NewReverseServer(cfg, NewZapLogger(zap.NewNop().Sugar()))
+	if err != nil {
+		t.Fatalf("Failed to create reverse server: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go server.Run(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if addr := server.Addr(); addr != "" {
+			return "http://" + addr
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Reverse server never came up")
+	return ""
+}
+
+func TestReverseServer_EndToEnd(t *testing.T) {
+	base := startReverse(t, ReverseConfig{})
+
+	resp, err := http.Get(base + "/sse")
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %q", ct)
+	}
+
+	events := make(chan string, 10)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+				events <- strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}()
+
+	// The first event names the POST path.
+	select {
+	case got := <-events:
+		if got != "/mcp" {
+			t.Fatalf("Expected the endpoint event, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No endpoint event arrived")
+	}
+
+	// A POSTed message comes back through the stream (the child is cat).
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	postResp, err := http.Post(base+"/mcp", "application/json", strings.NewReader(request))
+	if err != nil {
+		t.Fatalf("Failed to post: %v", err)
+	}
+	postResp.Body.Close()
+	if postResp.StatusCode != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", postResp.StatusCode)
+	}
+	select {
+	case got := <-events:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("The message never came back through the stream")
+	}
+}
+
+func TestReverseServer_BearerAuth(t *testing.T) {
+	base := startReverse(t, ReverseConfig{BearerToken: "secret"})
+
+	resp, err := http.Post(base+"/mcp", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatalf("Failed to post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without the token, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, base+"/mcp", strings.NewReader(`{"id": 1}`))
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to post with token: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 with the token, got %d", resp.StatusCode)
+	}
+}
+
+func TestReverseServer_Validation(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	if _, err := NewReverseServer(ReverseConfig{Command: []string{"cat"}}, logger); err == nil {
+		t.Error("Expected an error for a missing address")
+	}
+	if _, err := NewReverseServer(ReverseConfig{Addr: "127.0.0.1:0"}, logger); err == nil {
+		t.Error("Expected an error for a missing command")
+	}
+}
+
+func TestReverseServer_OversizedMessage(t *testing.T) {
+	base := startReverse(t, ReverseConfig{MaxMessageSize: 64})
+	big := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "params": %q}`, strings.Repeat("x", 128))
+	resp, err := http.Post(base+"/mcp", "application/json", strings.NewReader(big))
+	if err != nil {
+		t.Fatalf("Failed to post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for an oversized message, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,163 @@
+package mcpengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultManifestURL is where releases publish the update manifest.
+const DefaultManifestURL = "https://github.com/featureform/mcp-engine/releases/latest/download/manifest.json"
+
+// Version identifies this build. Releases override it at link time with
+// -ldflags "-X mcpengine.Version=v1.2.3"; development builds stay "dev".
+var Version = "dev"
+
+// UpdateAsset names one downloadable binary and its expected digest.
+type UpdateAsset struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// UpdateManifest is the release document a self-update consults: the
+// latest version and one asset per platform, keyed "<goos>-<goarch>".
+type UpdateManifest struct {
+	Version string                 `json:"version"`
+	Assets  map[string]UpdateAsset `json:"assets"`
+}
+
+// Updater checks a release endpoint and swaps the running binary for the
+// latest one, verifying the manifest's checksum before anything replaces
+// the executable.
+type Updater struct {
+	// ManifestURL is the release document's location.
+	ManifestURL string
+	// CurrentVersion is the running build; defaults to Version.
+	CurrentVersion string
+	// TargetPath is the binary to replace; defaults to the running
+	// executable.
+	TargetPath string
+	// HTTPClient performs the downloads. Nil uses a default with a
+	// generous timeout, since assets can be large.
+	HTTPClient *http.Client
+}
+
+// updateMaxAssetSize bounds a downloaded binary.
+const updateMaxAssetSize = 512 << 20
+
+// client resolves the HTTP client to download with.
+func (u *Updater) client() *http.Client {
+	if u.HTTPClient != nil {
+		return u.HTTPClient
+	}
+	return &http.Client{Timeout: 5 * time.Minute}
+}
+
+// Check fetches the manifest and reports whether it names a version other
+// than the running one. Development builds ("dev") never consider
+// themselves current.
+func (u *Updater) Check(ctx context.Context) (*UpdateManifest, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.ManifestURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("update: %w", err)
+	}
+	resp, err := u.client().Do(req)
+	if err != nil {
+		return nil, false, fmt.Errorf("update: failed to fetch the manifest: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("update: %s returned %s", u.ManifestURL, resp.Status)
+	}
+	var manifest UpdateManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, false, fmt.Errorf("update: invalid manifest: %w", err)
+	}
+	if manifest.Version == "" {
+		return nil, false, fmt.Errorf("update: manifest names no version")
+	}
+	current := u.CurrentVersion
+	if current == "" {
+		current = Version
+	}
+	return &manifest, manifest.Version != current, nil
+}
+
+// platformKey identifies this build's asset in the manifest.
+func platformKey() string {
+	return runtime.GOOS + "-" + runtime.GOARCH
+}
+
+// Apply downloads this platform's asset, verifies its checksum, and swaps
+// it into place atomically: the new binary lands in the target directory
+// under a temporary name and renames over the old one, so a crash mid-way
+// never leaves a half-written executable.
+func (u *Updater) Apply(ctx context.Context, manifest *UpdateManifest) error {
+	asset, ok := manifest.Assets[platformKey()]
+	if !ok {
+		return fmt.Errorf("update: version %s has no asset for %s", manifest.Version, platformKey())
+	}
+	if asset.SHA256 == "" {
+		return fmt.Errorf("update: the %s asset carries no checksum; refusing to install it", platformKey())
+	}
+
+	target := u.TargetPath
+	if target == "" {
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("update: failed to locate the running binary: %w", err)
+		}
+		target = executable
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.URL, nil)
+	if err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+	resp, err := u.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("update: download failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("update: %s returned %s", asset.URL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, updateMaxAssetSize))
+	if err != nil {
+		return fmt.Errorf("update: download failed: %w", err)
+	}
+
+	digest := sha256.Sum256(body)
+	if got := hex.EncodeToString(digest[:]); !strings.EqualFold(got, asset.SHA256) {
+		return fmt.Errorf("update: checksum mismatch for %s: downloaded %s, manifest says %s", asset.URL, got, asset.SHA256)
+	}
+
+	// Stage next to the target so the rename stays on one filesystem.
+	staged := target + ".new"
+	if err := os.WriteFile(staged, body, 0o755); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+	if runtime.GOOS == "windows" {
+		// Windows won't rename over a running executable; move it aside
+		// first. The leftover .old file is cleaned up by the next update.
+		old := target + ".old"
+		os.Remove(old)
+		if err := os.Rename(target, old); err != nil {
+			os.Remove(staged)
+			return fmt.Errorf("update: failed to move the old binary aside: %w", err)
+		}
+	}
+	if err := os.Rename(staged, target); err != nil {
+		os.Remove(staged)
+		return fmt.Errorf("update: failed to install the new binary: %w", err)
+	}
+	return nil
+}
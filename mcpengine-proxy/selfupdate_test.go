@@ -0,0 +1,139 @@
+package mcpengine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// ===== Self-Update Tests =====
+
+// serveRelease serves a release manifest at /manifest.json and the binary
+// at /bin, advertising the given digest for this platform's asset.
+func serveRelease(t *testing.T, version string, binary []byte, digest string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var ts *httptest.Server
+	mux.HandleFunc("/manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		manifest := UpdateManifest{
+			Version: version,
+			Assets: map[string]UpdateAsset{
+				platformKey(): {URL: ts.URL + "/bin", SHA256: digest},
+			},
+		}
+		json.NewEncoder(w).Encode(manifest)
+	})
+	mux.HandleFunc("/bin", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(binary)
+	})
+	ts = httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestUpdater_CheckAndApply(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho v2\n")
+	digest := sha256.Sum256(binary)
+	ts := serveRelease(t, "v2.0.0", binary, hex.EncodeToString(digest[:]))
+
+	target := filepath.Join(t.TempDir(), "mcpengine")
+	if err := os.WriteFile(target, []byte("old"), 0o755); err != nil {
+		t.Fatalf("Failed to write target: %v", err)
+	}
+	updater := &Updater{
+		ManifestURL:    ts.URL + "/manifest.json",
+		CurrentVersion: "v1.0.0",
+		TargetPath:     target,
+	}
+
+	manifest, available, err := updater.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if !available || manifest.Version != "v2.0.0" {
+		t.Fatalf("Expected v2.0.0 to be available, got %v/%v", available, manifest)
+	}
+	if err := updater.Apply(context.Background(), manifest); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+
+	installed, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Failed to read target: %v", err)
+	}
+	if string(installed) != string(binary) {
+		t.Error("Expected the target to hold the new binary")
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(target)
+		if err != nil {
+			t.Fatalf("Failed to stat target: %v", err)
+		}
+		if info.Mode().Perm() != 0o755 {
+			t.Errorf("Expected mode 0755, got %v", info.Mode().Perm())
+		}
+	}
+	if _, err := os.Stat(target + ".new"); !os.IsNotExist(err) {
+		t.Error("Expected the staging file to be gone")
+	}
+}
+
+func TestUpdater_CheckUpToDate(t *testing.T) {
+	ts := serveRelease(t, "v1.0.0", []byte("bin"), "unused")
+	updater := &Updater{ManifestURL: ts.URL + "/manifest.json", CurrentVersion: "v1.0.0"}
+	_, available, err := updater.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if available {
+		t.Error("Expected no update for the current version")
+	}
+}
+
+func TestUpdater_ChecksumMismatch(t *testing.T) {
+	ts := serveRelease(t, "v2.0.0", []byte("tampered"), "deadbeef")
+
+	target := filepath.Join(t.TempDir(), "mcpengine")
+	if err := os.WriteFile(target, []byte("old"), 0o755); err != nil {
+		t.Fatalf("Failed to write target: %v", err)
+	}
+	updater := &Updater{
+		ManifestURL:    ts.URL + "/manifest.json",
+		CurrentVersion: "v1.0.0",
+		TargetPath:     target,
+	}
+	manifest, _, err := updater.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+	if err := updater.Apply(context.Background(), manifest); err == nil {
+		t.Fatal("Expected a checksum mismatch error")
+	}
+	// The original binary must survive a failed update untouched.
+	installed, err := os.ReadFile(target)
+	if err != nil || string(installed) != "old" {
+		t.Errorf("Expected the target to be untouched, got %q, %v", installed, err)
+	}
+}
+
+func TestUpdater_MissingAssetAndChecksum(t *testing.T) {
+	updater := &Updater{TargetPath: filepath.Join(t.TempDir(), "mcpengine")}
+	err := updater.Apply(context.Background(), &UpdateManifest{Version: "v2.0.0"})
+	if err == nil {
+		t.Error("Expected an error for a manifest without this platform's asset")
+	}
+	err = updater.Apply(context.Background(), &UpdateManifest{
+		Version: "v2.0.0",
+		Assets:  map[string]UpdateAsset{platformKey(): {URL: "http://example.com/bin"}},
+	})
+	if err == nil {
+		t.Error("Expected an error for an asset without a checksum")
+	}
+}
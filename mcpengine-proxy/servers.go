@@ -0,0 +1,320 @@
+package mcpengine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ServerOptions is one named server definition: flag names to values, the
+// same keys the -config file accepts. Keeping the two formats identical
+// means an installed server is nothing more than a saved set of flags, and
+// anything expressible on the command line is expressible in a definition.
+type ServerOptions map[string]string
+
+// ServersPath returns the location of the local servers file,
+// <user config dir>/mcpengine/servers.yaml.
+func ServersPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("servers: failed to locate the config directory: %w", err)
+	}
+	return filepath.Join(dir, "mcpengine", "servers.yaml"), nil
+}
+
+// LoadServers reads the named server definitions from path. A missing file
+// is not an error; it yields an empty map, as before anything has been
+// installed.
+func LoadServers(path string) (map[string]ServerOptions, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]ServerOptions{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("servers: %w", err)
+	}
+	servers, err := parseServerOptions(data)
+	if err != nil {
+		return nil, fmt.Errorf("servers: invalid YAML in %s: %w", path, err)
+	}
+	return servers, nil
+}
+
+// parseServerOptions parses a name-to-options YAML document. Values may
+// be YAML numbers or booleans; they are carried as strings since that is
+// what flag.Set consumes. Nested blocks (the profiles section) flatten to
+// dot-joined keys, so "profiles: {staging: {host: x}}" becomes
+// "profiles.staging.host: x".
+func parseServerOptions(data []byte) (map[string]ServerOptions, error) {
+	var raw map[string]map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	servers := make(map[string]ServerOptions, len(raw))
+	for name, options := range raw {
+		opts := make(ServerOptions, len(options))
+		flattenOptions("", options, opts)
+		servers[name] = opts
+	}
+	return servers, nil
+}
+
+// flattenOptions stringifies a (possibly nested) options block into into,
+// dot-joining nested keys under prefix.
+func flattenOptions(prefix string, block map[string]interface{}, into ServerOptions) {
+	for key, value := range block {
+		full := key
+		if prefix != "" {
+			full = prefix + "." + key
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenOptions(full, nested, into)
+			continue
+		}
+		into[full] = fmt.Sprint(value)
+	}
+}
+
+// profilePrefix introduces per-environment overrides inside a definition:
+// keys under profiles.<name>. overlay the base options when that profile
+// is selected with -profile or MCPENGINE_PROFILE.
+const profilePrefix = "profiles."
+
+// Profiles lists the profile names a definition declares, sorted.
+func (o ServerOptions) Profiles() []string {
+	seen := map[string]bool{}
+	for key := range o {
+		if !strings.HasPrefix(key, profilePrefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(key, profilePrefix)
+		if name, _, ok := strings.Cut(rest, "."); ok {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the effective options for a profile: the base keys with
+// the profile's overrides applied, and all profile blocks stripped. An
+// empty profile selects the base options alone; naming a profile the
+// definition doesn't declare is an error.
+func (o ServerOptions) Resolve(profile string) (ServerOptions, error) {
+	resolved := make(ServerOptions, len(o))
+	for key, value := range o {
+		if strings.HasPrefix(key, profilePrefix) {
+			continue
+		}
+		resolved[key] = value
+	}
+	if profile == "" {
+		return resolved, nil
+	}
+	overridePrefix := profilePrefix + profile + "."
+	found := false
+	for key, value := range o {
+		if !strings.HasPrefix(key, overridePrefix) {
+			continue
+		}
+		resolved[strings.TrimPrefix(key, overridePrefix)] = value
+		found = true
+	}
+	if !found {
+		available := o.Profiles()
+		if len(available) == 0 {
+			return nil, fmt.Errorf("servers: no profile %q (the definition declares none)", profile)
+		}
+		return nil, fmt.Errorf("servers: no profile %q (available: %s)", profile, strings.Join(available, ", "))
+	}
+	return resolved, nil
+}
+
+// SaveServers writes the definitions to path, creating the directory if
+// needed. The file is world-unreadable since definitions may carry keys or
+// tokens.
+func SaveServers(path string, servers map[string]ServerOptions) error {
+	data, err := yaml.Marshal(servers)
+	if err != nil {
+		return fmt.Errorf("servers: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("servers: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("servers: %w", err)
+	}
+	return nil
+}
+
+// BundledServers returns the service definitions shipped with the binary,
+// for official MCP services users commonly proxy. Installing one copies it
+// into the local servers file where it can be edited.
+func BundledServers() map[string]ServerOptions {
+	return map[string]ServerOptions{
+		"github": {
+			"host": "https://api.githubcopilot.com",
+			"mode": "streamable",
+		},
+		"sentry": {
+			"host": "https://mcp.sentry.dev",
+			"mode": "sse",
+		},
+		"linear": {
+			"host": "https://mcp.linear.app",
+			"mode": "sse",
+		},
+	}
+}
+
+// ServerSummary is the display form of one server definition, as shown by
+// the list command.
+type ServerSummary struct {
+	Name      string
+	Source    string // "local", "installed", or "bundled".
+	Transport string
+	Endpoint  string
+	AuthMode  string
+	// TokenCached reports whether the OS keychain holds a token for the
+	// server's host.
+	TokenCached bool
+	// Profiles are the environment profiles the definition declares.
+	Profiles []string
+}
+
+// SummarizeServer derives the display fields from a definition, mirroring
+// how the CLI itself would interpret the flags.
+func SummarizeServer(name, source string, opts ServerOptions) ServerSummary {
+	summary := ServerSummary{
+		Name:     name,
+		Source:   source,
+		Endpoint: opts["host"],
+		Profiles: opts.Profiles(),
+	}
+
+	switch {
+	case opts["docker_image"] != "":
+		summary.Transport = string(TransportDocker)
+		summary.Endpoint = opts["docker_image"]
+	case opts["grpc_target"] != "":
+		summary.Transport = string(TransportGRPC)
+		summary.Endpoint = opts["grpc_target"]
+	case opts["exec"] != "":
+		summary.Transport = string(TransportStdio)
+		summary.Endpoint = opts["exec"]
+	case strings.HasPrefix(opts["host"], npipeScheme):
+		summary.Transport = string(TransportPipe)
+	case strings.HasPrefix(opts["host"], tcpScheme), strings.HasPrefix(opts["host"], tcpsScheme):
+		summary.Transport = string(TransportTCP)
+	case opts["mode"] == "streamable":
+		summary.Transport = string(TransportStreamableHTTP)
+	case opts["mode"] != "":
+		summary.Transport = opts["mode"]
+	default:
+		summary.Transport = string(TransportSSE)
+	}
+
+	switch {
+	case opts["bearer_token"] != "" || opts["bearer_token_file"] != "":
+		summary.AuthMode = "bearer"
+	case opts["api_key"] != "":
+		summary.AuthMode = "api-key"
+	case opts["credential_helper"] != "":
+		summary.AuthMode = "helper"
+	default:
+		summary.AuthMode = "oauth"
+	}
+
+	if host := opts["host"]; host != "" {
+		if token, err := NewKeychainTokenStore(host).Load(); err == nil && token != nil {
+			summary.TokenCached = true
+		}
+	}
+	return summary
+}
+
+// ListServers merges the local definitions over the bundled ones and
+// returns summaries sorted by name. A name present in both sources is
+// reported once, as "installed".
+func ListServers(path string) ([]ServerSummary, error) {
+	local, err := LoadServers(path)
+	if err != nil {
+		return nil, err
+	}
+	bundled := BundledServers()
+
+	summaries := make([]ServerSummary, 0, len(local)+len(bundled))
+	for name, opts := range local {
+		source := "local"
+		if _, ok := bundled[name]; ok {
+			source = "installed"
+		}
+		summaries = append(summaries, SummarizeServer(name, source, opts))
+	}
+	for name, opts := range bundled {
+		if _, ok := local[name]; ok {
+			continue
+		}
+		summaries = append(summaries, SummarizeServer(name, "bundled", opts))
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Name < summaries[j].Name })
+	return summaries, nil
+}
+
+// UninstallServer removes the named entry from the servers file at path
+// and returns its options, so callers can clean up associated state (a
+// cached token keyed by the entry's host, for example).
+func UninstallServer(path, name string) (ServerOptions, error) {
+	servers, err := LoadServers(path)
+	if err != nil {
+		return nil, err
+	}
+	opts, ok := servers[name]
+	if !ok {
+		return nil, fmt.Errorf("servers: %q is not installed", name)
+	}
+	delete(servers, name)
+	if err := SaveServers(path, servers); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// InstallServer copies the named bundled definition into the servers file
+// at path. Installing over an existing entry requires force, so local
+// edits aren't silently overwritten.
+func InstallServer(path, name string, force bool) error {
+	return InstallServerFrom(path, name, BundledServers(), force)
+}
+
+// InstallServerFrom copies the named definition from the given source set
+// (bundled definitions, a fetched registry index, or both merged) into the
+// servers file at path.
+func InstallServerFrom(path, name string, source map[string]ServerOptions, force bool) error {
+	opts, ok := source[name]
+	if !ok {
+		names := make([]string, 0, len(source))
+		for sourceName := range source {
+			names = append(names, sourceName)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("servers: no server named %q (available: %s)", name, strings.Join(names, ", "))
+	}
+	servers, err := LoadServers(path)
+	if err != nil {
+		return err
+	}
+	if _, exists := servers[name]; exists && !force {
+		return fmt.Errorf("servers: %q is already installed; use -force to overwrite it", name)
+	}
+	servers[name] = opts
+	return SaveServers(path, servers)
+}
@@ -0,0 +1,259 @@
+package mcpengine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ===== Server Definition Tests =====
+
+func TestLoadServers_MissingFile(t *testing.T) {
+	servers, err := LoadServers(filepath.Join(t.TempDir(), "servers.yaml"))
+	if err != nil {
+		t.Fatalf("A missing file should not be an error, got %v", err)
+	}
+	if len(servers) != 0 {
+		t.Errorf("Expected no servers, got %d", len(servers))
+	}
+}
+
+func TestSaveLoadServers_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "servers.yaml")
+	want := map[string]ServerOptions{
+		"staging": {
+			"host":      "https://staging.example.com",
+			"mode":      "streamable",
+			"client_id": "staging-client",
+		},
+	}
+	if err := SaveServers(path, want); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	got, err := LoadServers(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if len(got) != 1 || got["staging"]["host"] != want["staging"]["host"] ||
+		got["staging"]["client_id"] != want["staging"]["client_id"] {
+		t.Errorf("Round trip mismatch: got %v", got)
+	}
+}
+
+func TestLoadServers_NonStringValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.yaml")
+	content := "local:\n  host: http://localhost:8000\n  debug: true\n  auth_port: 8181\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	servers, err := LoadServers(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	// Flag values are strings; YAML scalars must arrive stringified.
+	if servers["local"]["debug"] != "true" || servers["local"]["auth_port"] != "8181" {
+		t.Errorf("Expected stringified values, got %v", servers["local"])
+	}
+}
+
+func TestServerOptions_Profiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.yaml")
+	content := `myserver:
+  host: https://prod.example.com
+  mode: streamable
+  client_id: prod-client
+  profiles:
+    staging:
+      host: https://staging.example.com
+      client_id: staging-client
+    dev:
+      host: http://localhost:8000
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	servers, err := LoadServers(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	opts := servers["myserver"]
+
+	if got := opts.Profiles(); len(got) != 2 || got[0] != "dev" || got[1] != "staging" {
+		t.Errorf("Expected [dev staging], got %v", got)
+	}
+
+	base, err := opts.Resolve("")
+	if err != nil {
+		t.Fatalf("Failed to resolve base: %v", err)
+	}
+	if base["host"] != "https://prod.example.com" || base["client_id"] != "prod-client" {
+		t.Errorf("Unexpected base options: %v", base)
+	}
+	if _, ok := base["profiles.staging.host"]; ok {
+		t.Error("Expected profile keys to be stripped from the resolved options")
+	}
+
+	staging, err := opts.Resolve("staging")
+	if err != nil {
+		t.Fatalf("Failed to resolve staging: %v", err)
+	}
+	// Overridden keys come from the profile; the rest stay from the base.
+	if staging["host"] != "https://staging.example.com" || staging["client_id"] != "staging-client" {
+		t.Errorf("Unexpected staging options: %v", staging)
+	}
+	if staging["mode"] != "streamable" {
+		t.Errorf("Expected the base mode to survive, got %q", staging["mode"])
+	}
+
+	if _, err := opts.Resolve("prod"); err == nil {
+		t.Error("Expected an error for an undeclared profile")
+	}
+}
+
+func TestSummarizeServer(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          ServerOptions
+		wantTransport string
+		wantEndpoint  string
+		wantAuth      string
+	}{
+		{
+			name:          "DefaultsToSSEAndOAuth",
+			opts:          ServerOptions{"host": "https://mcp.example.com"},
+			wantTransport: "sse",
+			wantEndpoint:  "https://mcp.example.com",
+			wantAuth:      "oauth",
+		},
+		{
+			name:          "StreamableWithBearer",
+			opts:          ServerOptions{"host": "https://mcp.example.com", "mode": "streamable", "bearer_token": "tok"},
+			wantTransport: "streamable-http",
+			wantEndpoint:  "https://mcp.example.com",
+			wantAuth:      "bearer",
+		},
+		{
+			name:          "StdioWithAPIKey",
+			opts:          ServerOptions{"exec": "mcp-server --flag", "api_key": "key"},
+			wantTransport: "stdio",
+			wantEndpoint:  "mcp-server --flag",
+			wantAuth:      "api-key",
+		},
+		{
+			name:          "Docker",
+			opts:          ServerOptions{"docker_image": "example/mcp:latest"},
+			wantTransport: "docker",
+			wantEndpoint:  "example/mcp:latest",
+			wantAuth:      "oauth",
+		},
+		{
+			name:          "TCPWithHelper",
+			opts:          ServerOptions{"host": "tcp://device.lan:9000", "credential_helper": "pass show mcp"},
+			wantTransport: "tcp",
+			wantEndpoint:  "tcp://device.lan:9000",
+			wantAuth:      "helper",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			summary := SummarizeServer("test", "local", test.opts)
+			if summary.Transport != test.wantTransport {
+				t.Errorf("Expected transport %q, got %q", test.wantTransport, summary.Transport)
+			}
+			if summary.Endpoint != test.wantEndpoint {
+				t.Errorf("Expected endpoint %q, got %q", test.wantEndpoint, summary.Endpoint)
+			}
+			if summary.AuthMode != test.wantAuth {
+				t.Errorf("Expected auth mode %q, got %q", test.wantAuth, summary.AuthMode)
+			}
+		})
+	}
+}
+
+func TestListServers_MergesSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.yaml")
+	local := map[string]ServerOptions{
+		"github": {"host": "https://github.example.com", "mode": "streamable"},
+		"mine":   {"host": "http://localhost:9000", "mode": "http"},
+	}
+	if err := SaveServers(path, local); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	summaries, err := ListServers(path)
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	sources := make(map[string]string)
+	endpoints := make(map[string]string)
+	for _, summary := range summaries {
+		sources[summary.Name] = summary.Source
+		endpoints[summary.Name] = summary.Endpoint
+	}
+	// The installed copy shadows the bundled definition.
+	if sources["github"] != "installed" || endpoints["github"] != "https://github.example.com" {
+		t.Errorf("Expected the local github entry to win, got %s/%s", sources["github"], endpoints["github"])
+	}
+	if sources["mine"] != "local" {
+		t.Errorf("Expected mine to be local, got %s", sources["mine"])
+	}
+	if sources["sentry"] != "bundled" {
+		t.Errorf("Expected sentry to be bundled, got %s", sources["sentry"])
+	}
+}
+
+func TestUninstallServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.yaml")
+	if _, err := UninstallServer(path, "github"); err == nil {
+		t.Error("Expected an error uninstalling from an empty file")
+	}
+	if err := InstallServer(path, "github", false); err != nil {
+		t.Fatalf("Failed to install: %v", err)
+	}
+	opts, err := UninstallServer(path, "github")
+	if err != nil {
+		t.Fatalf("Failed to uninstall: %v", err)
+	}
+	// The removed options come back so callers can purge related state.
+	if opts["host"] == "" {
+		t.Error("Expected the removed entry's options")
+	}
+	servers, err := LoadServers(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if _, ok := servers["github"]; ok {
+		t.Error("Expected the entry to be gone")
+	}
+}
+
+func TestInstallServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.yaml")
+	if err := InstallServer(path, "no-such-server", false); err == nil {
+		t.Error("Expected an error for an unknown bundled server")
+	}
+	if err := InstallServer(path, "github", false); err != nil {
+		t.Fatalf("Failed to install: %v", err)
+	}
+	if err := InstallServer(path, "github", false); err == nil {
+		t.Error("Expected an error installing over an existing entry")
+	}
+	if err := InstallServer(path, "github", true); err != nil {
+		t.Errorf("Expected -force to overwrite, got %v", err)
+	}
+	servers, err := LoadServers(path)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if servers["github"]["host"] == "" {
+		t.Error("Expected the installed entry to carry the bundled host")
+	}
+}
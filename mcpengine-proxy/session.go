@@ -0,0 +1,121 @@
+package mcpengine
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// defaultSessionMaxAge bounds how old a persisted session may be before it
+// is considered stale and a restart negotiates a fresh one.
+const defaultSessionMaxAge = 5 * time.Minute
+
+// sessionRecord is the persisted shape of a negotiated session: the SSE
+// message endpoint (with its session_id) or the Streamable HTTP session
+// header, plus when it was last seen alive.
+type sessionRecord struct {
+	Endpoint  string    `json:"endpoint,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	SavedAt   time.Time `json:"saved_at"`
+}
+
+// SessionStore persists the negotiated upstream session to a local file so
+// a quick proxy restart can resume it — where the server still honors the
+// session — instead of forcing a full re-initialization. Stale records past
+// maxAge are ignored, since servers reap idle sessions.
+//
+// A nil SessionStore is valid and persists nothing, so call sites don't
+// need to guard.
+type SessionStore struct {
+	path   string
+	maxAge time.Duration
+	logger Logger
+	mu     sync.Mutex
+}
+
+// NewSessionStore creates a store backed by the file at path. A maxAge of
+// zero keeps the default (defaultSessionMaxAge).
+func NewSessionStore(path string, maxAge time.Duration, logger Logger) *SessionStore {
+	if maxAge <= 0 {
+		maxAge = defaultSessionMaxAge
+	}
+	return &SessionStore{
+		path:   path,
+		maxAge: maxAge,
+		logger: logger,
+	}
+}
+
+// SaveEndpoint persists the SSE message endpoint as the current session.
+func (ss *SessionStore) SaveEndpoint(endpoint string) {
+	if ss == nil {
+		return
+	}
+	ss.save(sessionRecord{Endpoint: endpoint})
+}
+
+// SaveSessionID persists the Streamable HTTP session id as the current
+// session.
+func (ss *SessionStore) SaveSessionID(id string) {
+	if ss == nil {
+		return
+	}
+	ss.save(sessionRecord{SessionID: id})
+}
+
+// Load returns the persisted session, or nil when none has been stored,
+// the record is older than maxAge, or the file cannot be read.
+func (ss *SessionStore) Load() *sessionRecord {
+	if ss == nil {
+		return nil
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	data, err := os.ReadFile(ss.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		ss.logger.Warnw("Failed to read session file", "path", ss.path, "err", err)
+		return nil
+	}
+	var rec sessionRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		ss.logger.Warnw("Failed to parse session file", "path", ss.path, "err", err)
+		return nil
+	}
+	if age := time.Since(rec.SavedAt); age > ss.maxAge {
+		ss.logger.Debugw("Ignoring stale persisted session", "age", age, "max-age", ss.maxAge)
+		return nil
+	}
+	return &rec
+}
+
+// Clear removes the persisted session, after the server has stopped
+// recognizing it. Clearing a session that does not exist is not an error.
+func (ss *SessionStore) Clear() {
+	if ss == nil {
+		return
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if err := os.Remove(ss.path); err != nil && !os.IsNotExist(err) {
+		ss.logger.Warnw("Failed to remove session file", "path", ss.path, "err", err)
+	}
+}
+
+// save stamps and writes the record; persistence is best-effort.
+func (ss *SessionStore) save(rec sessionRecord) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	rec.SavedAt = time.Now()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		ss.logger.Errorw("Failed to serialize session", "err", err)
+		return
+	}
+	if err := os.WriteFile(ss.path, data, 0o600); err != nil {
+		ss.logger.Warnw("Failed to write session file", "path", ss.path, "err", err)
+	}
+}
@@ -0,0 +1,131 @@
+package mcpengine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Session Persistence Tests =====
+
+func TestSessionStore_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	store := NewSessionStore(path, 0, NewZapLogger(zap.NewNop().Sugar()))
+
+	if rec := store.Load(); rec != nil {
+		t.Errorf("Expected no session before saving, got %+v", rec)
+	}
+
+	store.SaveEndpoint("/messages/?session_id=abc")
+	rec := store.Load()
+	if rec == nil || rec.Endpoint != "/messages/?session_id=abc" {
+		t.Fatalf("Expected the saved endpoint back, got %+v", rec)
+	}
+
+	store.SaveSessionID("sess-123")
+	rec = store.Load()
+	if rec == nil || rec.SessionID != "sess-123" {
+		t.Fatalf("Expected the saved session id back, got %+v", rec)
+	}
+	// Saving a session id replaces the endpoint record outright.
+	if rec.Endpoint != "" {
+		t.Errorf("Expected the endpoint cleared by the new record, got %q", rec.Endpoint)
+	}
+
+	store.Clear()
+	if rec := store.Load(); rec != nil {
+		t.Errorf("Expected no session after Clear, got %+v", rec)
+	}
+}
+
+func TestSessionStore_IgnoresStaleRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	store := NewSessionStore(path, 50*time.Millisecond, NewZapLogger(zap.NewNop().Sugar()))
+	store.SaveSessionID("sess-123")
+	time.Sleep(100 * time.Millisecond)
+	if rec := store.Load(); rec != nil {
+		t.Errorf("Expected the stale session ignored, got %+v", rec)
+	}
+}
+
+func TestSessionStore_NilIsSafe(t *testing.T) {
+	var store *SessionStore
+	store.SaveEndpoint("/messages/?session_id=abc")
+	store.SaveSessionID("sess-123")
+	store.Clear()
+	if rec := store.Load(); rec != nil {
+		t.Errorf("Expected nil from a nil store, got %+v", rec)
+	}
+}
+
+func TestStreamableHTTPSender_ResumesPersistedSession(t *testing.T) {
+	headerChan := make(chan string, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerChan <- r.Header.Get(sessionIDHeader)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	store := NewSessionStore(filepath.Join(t.TempDir(), "session.json"), 0, logger)
+	store.SaveSessionID("sess-123")
+
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 2)
+	endpointChan <- "/mcp"
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+
+	sender := NewStreamableHTTPSender(&http.Client{Timeout: 2 * time.Second}, ts.URL, endpointChan, inputChan, outputChan, NewAuthManager(nil, logger), logger)
+	sender.SetSessionStore(store)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	select {
+	case session := <-headerChan:
+		if session != "sess-123" {
+			t.Errorf("Expected the persisted session echoed on the first POST, got %q", session)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No POST arrived")
+	}
+}
+
+func TestStreamableHTTPSender_PersistsAssignedSession(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(sessionIDHeader, "assigned-456")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	store := NewSessionStore(filepath.Join(t.TempDir(), "session.json"), 0, logger)
+
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 2)
+	endpointChan <- "/mcp"
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`
+	close(inputChan)
+
+	sender := NewStreamableHTTPSender(&http.Client{Timeout: 2 * time.Second}, ts.URL, endpointChan, inputChan, outputChan, NewAuthManager(nil, logger), logger)
+	sender.SetSessionStore(store)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sender.Run(ctx, cancel); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	rec := store.Load()
+	if rec == nil || rec.SessionID != "assigned-456" {
+		t.Errorf("Expected the assigned session persisted, got %+v", rec)
+	}
+}
@@ -0,0 +1,69 @@
+package mcpengine
+
+import (
+	"fmt"
+)
+
+// sizeLimiter enforces hard per-direction message size limits as
+// middleware. Unlike MaxMessageSize — the scanner cap, where an oversized
+// line kills the reader and can't name the request — these limits apply to
+// fully-read messages, so an oversized request is answered with a JSON-RPC
+// error carrying its own id and the session carries on, and an oversized
+// response is replaced by an error with diagnostics instead of a
+// multi-megabyte line.
+type sizeLimiter struct {
+	maxRequest  int
+	maxResponse int
+	errChan     chan string
+	logger      Logger
+}
+
+// newSizeLimiter constructs a limiter; a zero limit disables that
+// direction. Rejected requests are answered on errChan.
+func newSizeLimiter(maxRequest, maxResponse int, errChan chan string, logger Logger) *sizeLimiter {
+	return &sizeLimiter{
+		maxRequest:  maxRequest,
+		maxResponse: maxResponse,
+		errChan:     errChan,
+		logger:      logger,
+	}
+}
+
+// outbound drops requests over the limit, answering them with an error
+// that references the limit and the actual size.
+func (sl *sizeLimiter) outbound(msg string) (string, error) {
+	if sl.maxRequest <= 0 || len(msg) <= sl.maxRequest {
+		return msg, nil
+	}
+	sl.logger.Warnw("Rejecting oversized request",
+		"bytes", len(msg), "max_bytes", sl.maxRequest)
+	if id := getMessageID(msg, sl.logger); id != nil {
+		select {
+		case sl.errChan <- jsonRPCErrorString(id, messageTooLargeErrorCode,
+			fmt.Sprintf("request of %d bytes exceeds the maximum size of %d bytes", len(msg), sl.maxRequest),
+			map[string]interface{}{"max_bytes": sl.maxRequest, "actual_bytes": len(msg)}, sl.logger):
+		default:
+		}
+	}
+	return "", fmt.Errorf("request exceeds the maximum size of %d bytes", sl.maxRequest)
+}
+
+// inbound replaces responses over the limit with an error on the same id,
+// so the client learns what happened and which request it concerns.
+func (sl *sizeLimiter) inbound(msg string) (string, error) {
+	if sl.maxResponse <= 0 || len(msg) <= sl.maxResponse {
+		return msg, nil
+	}
+	id := getMessageID(msg, sl.logger)
+	method := getMessageMethod(msg)
+	sl.logger.Warnw("Rejecting oversized server message",
+		"bytes", len(msg), "max_bytes", sl.maxResponse, "id", string(id), "method", method)
+	if id == nil || method != "" {
+		// An oversized notification or server request has no requester to
+		// answer; it is dropped with the diagnostics above.
+		return "", fmt.Errorf("server message exceeds the maximum size of %d bytes", sl.maxResponse)
+	}
+	return jsonRPCErrorString(id, messageTooLargeErrorCode,
+		fmt.Sprintf("response of %d bytes exceeds the maximum size of %d bytes", len(msg), sl.maxResponse),
+		map[string]interface{}{"max_bytes": sl.maxResponse, "actual_bytes": len(msg)}, sl.logger), nil
+}
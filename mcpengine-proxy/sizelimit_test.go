@@ -0,0 +1,89 @@
+package mcpengine
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Size Limit Tests =====
+
+func TestSizeLimiter_RejectsOversizedRequests(t *testing.T) {
+	errChan := make(chan string, 1)
+	limits := newSizeLimiter(64, 0, errChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	small := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	if msg, err := limits.outbound(small); err != nil || msg != small {
+		t.Errorf("Expected the small request untouched, got %q (%v)", msg, err)
+	}
+
+	big := `{"jsonrpc": "2.0", "id": 2, "method": "tools/call", "params": {"blob": "` +
+		strings.Repeat("x", 100) + `"}}`
+	if _, err := limits.outbound(big); err == nil {
+		t.Fatal("Expected the oversized request rejected")
+	}
+	select {
+	case errMsg := <-errChan:
+		if !strings.Contains(errMsg, `"id":2`) || !strings.Contains(errMsg, "-32005") {
+			t.Errorf("Expected a too-large error for id 2, got %q", errMsg)
+		}
+		if !strings.Contains(errMsg, "64") {
+			t.Errorf("Expected the error to reference the limit, got %q", errMsg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Rejected request never answered with an error")
+	}
+}
+
+func TestSizeLimiter_ReplacesOversizedResponses(t *testing.T) {
+	limits := newSizeLimiter(0, 64, nil, NewZapLogger(zap.NewNop().Sugar()))
+
+	big := `{"jsonrpc": "2.0", "id": 7, "result": {"blob": "` +
+		strings.Repeat("x", 100) + `"}}`
+	msg, err := limits.inbound(big)
+	if err != nil {
+		t.Fatalf("Expected a replacement error message, got drop: %v", err)
+	}
+	if !strings.Contains(msg, `"id":7`) || !strings.Contains(msg, "-32005") {
+		t.Errorf("Expected a too-large error on the response id, got %q", msg)
+	}
+	if !strings.Contains(msg, "max_bytes") {
+		t.Errorf("Expected diagnostics in the error data, got %q", msg)
+	}
+
+	// An oversized notification has no requester to answer; it is dropped.
+	notification := `{"jsonrpc": "2.0", "method": "notifications/message", "params": {"blob": "` +
+		strings.Repeat("x", 100) + `"}}`
+	if _, err := limits.inbound(notification); err == nil {
+		t.Error("Expected the oversized notification dropped")
+	}
+}
+
+func TestSizeLimits_ConfigValidation(t *testing.T) {
+	base := Config{
+		Endpoint: "http://localhost:8080",
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+	}
+
+	cfg := base
+	cfg.MaxRequestSize = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative MaxRequestSize rejected")
+	}
+
+	cfg = base
+	cfg.MaxResponseSize = -1
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative MaxResponseSize rejected")
+	}
+
+	cfg = base
+	cfg.MaxRequestSize = 1 << 20
+	cfg.MaxResponseSize = 1 << 20
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid limits accepted, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// SocketServer accepts additional stdio sessions over a local Unix socket,
+// attaching each connection to the engine's Multiplexer. Several MCP
+// clients on one machine can then share a single authenticated upstream
+// connection and token instead of each spawning its own proxy and auth
+// flow. Connections speak the same newline-delimited JSON-RPC as stdin.
+type SocketServer struct {
+	path           string
+	mux            *Multiplexer
+	maxMessageSize int
+	logger         Logger
+}
+
+// NewSocketServer constructs a SocketServer listening on the given socket
+// path.
+func NewSocketServer(path string, mux *Multiplexer, logger Logger) *SocketServer {
+	return &SocketServer{
+		path:   path,
+		mux:    mux,
+		logger: logger,
+	}
+}
+
+// SetMaxMessageSize caps how large a single line from a connection may be.
+// Zero keeps the default (defaultMaxMessageSize). Must be called before
+// Run.
+func (ss *SocketServer) SetMaxMessageSize(n int) {
+	ss.maxMessageSize = n
+}
+
+// Run listens on the socket and serves connections until the context is
+// cancelled. A stale socket file from a previous run is removed before
+// binding, and the socket is restricted to the owning user since the shared
+// session carries their credentials.
+func (ss *SocketServer) Run(ctx context.Context, cancel context.CancelFunc) error {
+	// A leftover socket from an unclean shutdown would make Listen fail.
+	_ = os.Remove(ss.path)
+	listener, err := net.Listen("unix", ss.path)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("socket: failed to listen on %s: %w", ss.path, err)
+	}
+	defer os.Remove(ss.path)
+	defer listener.Close()
+	if err := os.Chmod(ss.path, 0o600); err != nil {
+		ss.logger.Warnw("Failed to restrict socket permissions", "path", ss.path, "err", err)
+	}
+	ss.logger.Infow("Sharing connection over socket", "path", ss.path)
+
+	// Closing the listener is what unblocks Accept on cancellation.
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("socket: accept failed: %w", err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ss.serve(ctx, conn)
+		}()
+	}
+}
+
+// serve runs one connection as a mux client until the peer hangs up or the
+// context is cancelled.
+func (ss *SocketServer) serve(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	client := ss.mux.Attach()
+	defer ss.mux.Detach(client)
+
+	// Closing the connection unblocks the scanner below; detaching closes
+	// the client's output and stops the writer.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+	go func() {
+		for msg := range client.Output() {
+			if _, err := conn.Write([]byte(msg + "\n")); err != nil {
+				ss.logger.Warnw("Failed to write to socket client", "err", err)
+				return
+			}
+		}
+	}()
+
+	maxSize := ss.maxMessageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxMessageSize
+	}
+	initialSize := bufio.MaxScanTokenSize
+	if maxSize < initialSize {
+		initialSize = maxSize
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, initialSize), maxSize)
+	for scanner.Scan() {
+		if err := client.Send(ctx, scanner.Text()); err != nil {
+			ss.logger.Warnw("Dropping socket client", "err", err)
+			return
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		ss.logger.Warnw("Socket client read failed", "err", err)
+	}
+}
+
+// muxClientBridge adapts the primary stdio session to a mux client: lines
+// from the file reader go upstream through the multiplexer, and routed
+// messages come back out on the stdout proxy's channel. When stdin closes,
+// the bridge closes the upstream so the engine drains and shuts down the
+// same way it does without sharing.
+type muxClientBridge struct {
+	mux    *Multiplexer
+	client *MuxClient
+	in     chan string // Lines from the client (file reader).
+	out    chan string // Messages for the client (stdout proxy).
+	logger Logger
+}
+
+func newMuxClientBridge(mux *Multiplexer, client *MuxClient, in, out chan string, logger Logger) *muxClientBridge {
+	return &muxClientBridge{
+		mux:    mux,
+		client: client,
+		in:     in,
+		out:    out,
+		logger: logger,
+	}
+}
+
+// Run pumps both directions until the context is cancelled. After stdin
+// closes, routed messages keep flowing out so in-flight responses still
+// reach the client.
+func (mb *muxClientBridge) Run(ctx context.Context, cancel context.CancelFunc) error {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-mb.client.Output():
+				if !ok {
+					return
+				}
+				select {
+				case mb.out <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	in := mb.in
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-in:
+			if !ok {
+				mb.logger.Info("Client input closed; closing upstream")
+				mb.mux.CloseUpstream()
+				in = nil
+				continue
+			}
+			if err := mb.client.Send(ctx, msg); err != nil {
+				return err
+			}
+		}
+	}
+}
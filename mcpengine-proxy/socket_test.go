@@ -0,0 +1,194 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Socket Server Tests =====
+
+// startShareSocket runs a multiplexer and socket server over the given
+// upstream channels, returning the socket path.
+func startShareSocket(t *testing.T, upstreamIn, upstreamOut chan string) (string, *Multiplexer) {
+	t.Helper()
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+	path := filepath.Join(t.TempDir(), "share.sock")
+	server := NewSocketServer(path, mux, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go mux.Run(ctx, cancel)
+	go server.Run(ctx, cancel)
+
+	// Wait for the listener to come up.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return path, mux
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Socket server never started listening")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSocketServer_SharesUpstreamAcrossClients(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	path, _ := startShareSocket(t, upstreamIn, upstreamOut)
+
+	conn1, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to dial socket: %v", err)
+	}
+	defer conn1.Close()
+	conn2, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to dial socket: %v", err)
+	}
+	defer conn2.Close()
+
+	// Both clients use id 1; the upstream must see two distinct ids.
+	if _, err := conn1.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "method": "a"}` + "\n")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if _, err := conn2.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "method": "b"}` + "\n")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+
+	upstreamIDs := map[string]string{} // method -> rewritten id
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-upstreamIn:
+			var data struct {
+				ID     json.RawMessage `json:"id"`
+				Method string          `json:"method"`
+			}
+			if err := json.Unmarshal([]byte(msg), &data); err != nil {
+				t.Fatalf("Failed to parse upstream message: %v", err)
+			}
+			upstreamIDs[data.Method] = string(data.ID)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timed out waiting for upstream message")
+		}
+	}
+	if len(upstreamIDs) != 2 || upstreamIDs["a"] == upstreamIDs["b"] {
+		t.Fatalf("Expected 2 distinct upstream ids, got %v", upstreamIDs)
+	}
+
+	// Answer client 2's request; only conn2 should get it, original id
+	// restored.
+	upstreamOut <- `{"jsonrpc": "2.0", "id": ` + upstreamIDs["b"] + `, "result": {}}`
+
+	conn2.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := bufio.NewReader(conn2).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	if !strings.Contains(line, `"id":1`) {
+		t.Errorf("Expected the original id restored, got %q", line)
+	}
+
+	conn1.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	if extra, err := bufio.NewReader(conn1).ReadString('\n'); err == nil {
+		t.Errorf("conn1 unexpectedly received: %q", extra)
+	}
+}
+
+func TestSocketServer_RemovesStaleSocketFile(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	mux := NewMultiplexer(make(chan string, 1), make(chan string, 1), logger)
+	path := filepath.Join(t.TempDir(), "share.sock")
+
+	// A stale socket from a previous unclean shutdown must not prevent
+	// binding.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to create stale socket: %v", err)
+	}
+	stale.Close()
+	if _, err := os.Create(path); err != nil {
+		t.Fatalf("Failed to recreate stale socket file: %v", err)
+	}
+
+	server := NewSocketServer(path, mux, logger)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	errChan := make(chan error, 1)
+	go func() { errChan <- server.Run(ctx, cancel) }()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return
+		}
+		select {
+		case err := <-errChan:
+			t.Fatalf("Server exited: %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Server never bound over the stale socket")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMuxClientBridge_ClosesUpstreamOnEOF(t *testing.T) {
+	upstreamIn := make(chan string, 10)
+	upstreamOut := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	mux := NewMultiplexer(upstreamIn, upstreamOut, logger)
+
+	in := make(chan string, 10)
+	out := make(chan string, 10)
+	bridge := newMuxClientBridge(mux, mux.Attach(), in, out, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go mux.Run(ctx, cancel)
+	go bridge.Run(ctx, cancel)
+
+	// A request goes upstream; the routed response comes back out.
+	in <- `{"jsonrpc": "2.0", "id": 5, "method": "tools/list"}`
+	var upstream string
+	select {
+	case upstream = <-upstreamIn:
+	case <-time.After(time.Second):
+		t.Fatal("Request never reached the upstream")
+	}
+	upstreamOut <- strings.Replace(upstream, `"method":"tools/list"`, `"result":{}`, 1)
+	select {
+	case msg := <-out:
+		if !strings.Contains(msg, `"id":5`) {
+			t.Errorf("Expected the original id restored, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Response never reached the client")
+	}
+
+	// Closing the client's input closes the upstream, like stdin EOF does
+	// without sharing.
+	close(in)
+	select {
+	case _, ok := <-upstreamIn:
+		if ok {
+			t.Error("Expected the upstream input closed, got a message")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Upstream input was not closed after client EOF")
+	}
+}
@@ -3,22 +3,32 @@ package mcpengine
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/r3labs/sse/v2"
 	"go.uber.org/zap"
+	"golang.org/x/oauth2"
 )
 
 // ===== SSE Worker Tests =====
 
-// fakeSSEClient implements the sseClient interface for testing
+// fakeSSEClient implements the sseClient interface for testing. It supports
+// repeated subscriptions so reconnection behavior can be exercised; each
+// successful subscription sends on IsSubscribed.
 type fakeSSEClient struct {
 	Events       chan *sse.Event
 	IsSubscribed chan struct{}
 	SubscribeErr error
 }
 
+func newFakeSSEClient() *fakeSSEClient {
+	return &fakeSSEClient{
+		IsSubscribed: make(chan struct{}, 10),
+	}
+}
+
 func (fc *fakeSSEClient) SubscribeChan(stream string, msgChan chan *sse.Event) error {
 	if fc.SubscribeErr != nil {
 		return fc.SubscribeErr
@@ -27,19 +37,17 @@ func (fc *fakeSSEClient) SubscribeChan(stream string, msgChan chan *sse.Event) e
 		return fmt.Errorf("unexpected stream: %s", stream)
 	}
 	fc.Events = msgChan
-	close(fc.IsSubscribed)
+	fc.IsSubscribed <- struct{}{}
 	return nil
 }
 
 func TestSSEWorker_PassesEndpointAndMessages(t *testing.T) {
 	// Create a fake SSE client
-	fakeClient := &fakeSSEClient{
-		IsSubscribed: make(chan struct{}),
-	}
+	fakeClient := newFakeSSEClient()
 
 	endpointChan := make(chan string, 1)
 	outputChan := make(chan string, 10)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	worker := NewSSEWorker(fakeClient, endpointChan, outputChan, logger)
 
@@ -96,9 +104,22 @@ func TestSSEWorker_EndpointDetection(t *testing.T) {
 	// Test various endpoint detection patterns
 	testCases := []struct {
 		name             string
+		event            string
 		message          string
 		shouldBeEndpoint bool
 	}{
+		{
+			name:             "labeled endpoint event",
+			event:            "endpoint",
+			message:          "/custom/path/12345",
+			shouldBeEndpoint: true,
+		},
+		{
+			name:             "labeled message event mentioning session_id",
+			event:            "message",
+			message:          `{"jsonrpc": "2.0", "id": 1, "result": {"text": "your session_id is abc"}}`,
+			shouldBeEndpoint: false,
+		},
 		{
 			name:             "messages path format",
 			message:          "/messages/12345",
@@ -119,17 +140,20 @@ func TestSSEWorker_EndpointDetection(t *testing.T) {
 			message:          "path is /messages/12345",
 			shouldBeEndpoint: false,
 		},
+		{
+			name:             "unlabeled JSON mentioning session_id",
+			message:          `{"jsonrpc": "2.0", "method": "notify", "params": {"session_id": "abc"}}`,
+			shouldBeEndpoint: false,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			fakeClient := &fakeSSEClient{
-				IsSubscribed: make(chan struct{}),
-			}
+			fakeClient := newFakeSSEClient()
 
 			endpointChan := make(chan string, 1)
 			outputChan := make(chan string, 1)
-			logger := zap.NewNop().Sugar()
+			logger := NewZapLogger(zap.NewNop().Sugar())
 
 			worker := NewSSEWorker(fakeClient, endpointChan, outputChan, logger)
 
@@ -140,7 +164,7 @@ func TestSSEWorker_EndpointDetection(t *testing.T) {
 			<-fakeClient.IsSubscribed
 
 			// Send the test message
-			fakeClient.Events <- &sse.Event{Data: []byte(tc.message)}
+			fakeClient.Events <- &sse.Event{Event: []byte(tc.event), Data: []byte(tc.message)}
 
 			// Allow time for processing
 			time.Sleep(100 * time.Millisecond)
@@ -188,13 +212,11 @@ func TestSSEWorker_EndpointDetection(t *testing.T) {
 
 func TestSSEWorker_SkipsSubsequentEndpoints(t *testing.T) {
 	// Test that worker only forwards the first endpoint message
-	fakeClient := &fakeSSEClient{
-		IsSubscribed: make(chan struct{}),
-	}
+	fakeClient := newFakeSSEClient()
 
 	endpointChan := make(chan string, 1)
 	outputChan := make(chan string, 10)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	worker := NewSSEWorker(fakeClient, endpointChan, outputChan, logger)
 
@@ -256,13 +278,11 @@ func TestSSEWorker_SkipsSubsequentEndpoints(t *testing.T) {
 
 func TestSSEWorker_Cancellation(t *testing.T) {
 	// Test that the worker respects context cancellation
-	fakeClient := &fakeSSEClient{
-		IsSubscribed: make(chan struct{}),
-	}
+	fakeClient := newFakeSSEClient()
 
 	endpointChan := make(chan string)
 	outputChan := make(chan string)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	worker := NewSSEWorker(fakeClient, endpointChan, outputChan, logger)
 
@@ -292,54 +312,128 @@ func TestSSEWorker_Cancellation(t *testing.T) {
 	}
 }
 
-func TestSSEWorker_EventChannelClosure(t *testing.T) {
-	// Test that the worker handles the SSE event channel being closed
-	fakeClient := &fakeSSEClient{
-		IsSubscribed: make(chan struct{}),
-	}
+func TestSSEWorker_ReconnectsAfterChannelClosure(t *testing.T) {
+	// When the SSE event channel closes, the worker must resubscribe after a
+	// backoff instead of exiting, and forward the fresh session's endpoint.
+	fakeClient := newFakeSSEClient()
 
-	endpointChan := make(chan string)
-	outputChan := make(chan string)
-	logger := zap.NewNop().Sugar()
+	endpointChan := make(chan string, 2)
+	outputChan := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	worker := NewSSEWorker(fakeClient, endpointChan, outputChan, logger)
 
 	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
-	// Run the worker in a goroutine and capture the result
 	errCh := make(chan error, 1)
 	go func() {
 		errCh <- worker.Run(ctx, cancel)
 	}()
 
-	// Wait for subscription
+	// First subscription delivers an endpoint, then drops.
 	<-fakeClient.IsSubscribed
-
-	// Close the event channel to simulate the SSE connection closing
+	fakeClient.Events <- &sse.Event{Data: []byte("/messages/first?session_id=abc")}
+	<-endpointChan
 	close(fakeClient.Events)
 
-	// Check that the worker exits without error
+	// The worker should resubscribe (after the initial 1s backoff).
+	select {
+	case <-fakeClient.IsSubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SSEWorker did not resubscribe after channel closure")
+	}
+
+	// The new session's endpoint must be forwarded again.
+	newEndpoint := "/messages/second?session_id=def"
+	fakeClient.Events <- &sse.Event{Data: []byte(newEndpoint)}
+	select {
+	case ep := <-endpointChan:
+		if ep != newEndpoint {
+			t.Errorf("Expected endpoint %q after reconnect, got %q", newEndpoint, ep)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("New endpoint was not forwarded after reconnect")
+	}
+
+	// Regular traffic flows again on the new stream.
+	fakeClient.Events <- &sse.Event{Data: []byte("post-reconnect message")}
+	select {
+	case msg := <-outputChan:
+		if msg != "post-reconnect message" {
+			t.Errorf("Expected message after reconnect, got %q", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Message was not forwarded after reconnect")
+	}
+
+	// The worker keeps running throughout.
 	select {
 	case err := <-errCh:
-		if err != nil {
-			t.Errorf("Expected nil error, got: %v", err)
+		t.Fatalf("Worker exited unexpectedly: %v", err)
+	default:
+	}
+}
+
+func TestSSEWorker_NotifiesOnReconnect(t *testing.T) {
+	// With a notifier attached, a dropped stream surfaces as a warning
+	// notification and a successful reconnect as an info notification.
+	fakeClient := newFakeSSEClient()
+
+	endpointChan := make(chan string, 2)
+	outputChan := make(chan string, 10)
+	notifyChan := make(chan string, 10)
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	worker := NewSSEWorker(fakeClient, endpointChan, outputChan, logger)
+	worker.SetNotifier(NewNotifier(notifyChan, logger))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go worker.Run(ctx, cancel)
+
+	<-fakeClient.IsSubscribed
+	fakeClient.Events <- &sse.Event{Data: []byte("/messages/first?session_id=abc")}
+	<-endpointChan
+
+	// No notifications while the stream is healthy.
+	select {
+	case msg := <-notifyChan:
+		t.Fatalf("Unexpected notification before the drop: %s", msg)
+	default:
+	}
+
+	close(fakeClient.Events)
+	select {
+	case msg := <-notifyChan:
+		if !strings.Contains(msg, `"notifications/message"`) || !strings.Contains(msg, `"warning"`) {
+			t.Errorf("Expected a warning notification, got %s", msg)
 		}
-	case <-time.After(500 * time.Millisecond):
-		t.Fatal("SSEWorker did not exit after event channel closed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("No notification after the stream dropped")
+	}
+
+	<-fakeClient.IsSubscribed
+	fakeClient.Events <- &sse.Event{Data: []byte("/messages/second?session_id=def")}
+	select {
+	case msg := <-notifyChan:
+		if !strings.Contains(msg, `"info"`) || !strings.Contains(msg, "Reconnected") {
+			t.Errorf("Expected a reconnected notification, got %s", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("No notification after reconnecting")
 	}
 }
 
 func TestSSEWorker_SubscribeError(t *testing.T) {
 	// Test handling of subscription errors
 	subscribeErr := fmt.Errorf("subscription failed")
-	fakeClient := &fakeSSEClient{
-		IsSubscribed: make(chan struct{}),
-		SubscribeErr: subscribeErr,
-	}
+	fakeClient := newFakeSSEClient()
+	fakeClient.SubscribeErr = subscribeErr
 
 	endpointChan := make(chan string)
 	outputChan := make(chan string)
-	logger := zap.NewNop().Sugar()
+	logger := NewZapLogger(zap.NewNop().Sugar())
 
 	worker := NewSSEWorker(fakeClient, endpointChan, outputChan, logger)
 
@@ -355,3 +449,217 @@ func TestSSEWorker_SubscribeError(t *testing.T) {
 		t.Errorf("Expected DeadlineExceeded error, got: %v", err)
 	}
 }
+
+func TestNew_SSESubscriptionCustomization(t *testing.T) {
+	t.Setenv("TEST_SSE_KEY", "secret-key")
+	engine, err := New(Config{
+		UseSSE:   true,
+		Endpoint: "http://localhost:8080",
+		SSEPath:  "/sse",
+		SSEQueryParams: map[string]string{
+			"api_key": "${TEST_SSE_KEY}",
+			"tenant":  "acme",
+		},
+		SSEHeaders: map[string]string{
+			"X-Stream-Token": "${TEST_SSE_KEY}",
+		},
+		Headers: map[string]string{"X-Shared": "both"},
+		Logger:  NewZapLogger(zap.NewNop().Sugar()),
+	})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+	client, ok := engine.sseClient.(*sse.Client)
+	if !ok {
+		t.Fatalf("Expected an *sse.Client, got %T", engine.sseClient)
+	}
+	if !strings.HasPrefix(client.URL, "http://localhost:8080/sse?") {
+		t.Errorf("Expected the query string appended to the SSE URL, got %q", client.URL)
+	}
+	for _, want := range []string{"api_key=secret-key", "tenant=acme"} {
+		if !strings.Contains(client.URL, want) {
+			t.Errorf("Expected %q in the SSE URL, got %q", want, client.URL)
+		}
+	}
+	if client.Headers["X-Stream-Token"] != "secret-key" {
+		t.Errorf("Expected the expanded SSE header, got %q", client.Headers["X-Stream-Token"])
+	}
+	if client.Headers["X-Shared"] != "both" {
+		t.Errorf("Expected the shared header on the subscription, got %q", client.Headers["X-Shared"])
+	}
+}
+
+func TestSSESubscription_ConfigValidation(t *testing.T) {
+	cfg := Config{
+		Endpoint:       "http://localhost:8080",
+		MCPPath:        "/mcp",
+		Logger:         NewZapLogger(zap.NewNop().Sugar()),
+		SSEQueryParams: map[string]string{"api_key": "abc"},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for SSEQueryParams on the http transport")
+	}
+	cfg.SSEQueryParams = nil
+	cfg.SSEHeaders = map[string]string{"X-Stream-Token": "abc"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for SSEHeaders on the http transport")
+	}
+}
+
+func TestSSEWorker_ResubscribesWhenTokenArrives(t *testing.T) {
+	fakeClient := newFakeSSEClient()
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := NewAuthManager(nil, logger)
+
+	worker := NewSSEWorker(fakeClient, make(chan string, 1), make(chan string, 1), logger)
+	worker.SetAuthenticator(auth)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go worker.Run(ctx, cancel)
+	<-fakeClient.IsSubscribed
+
+	// The auth flow completes while the unauthenticated stream is up; the
+	// worker should drop it and subscribe again with the token.
+	auth.setToken(&oauth2.Token{AccessToken: "fresh-token"})
+
+	select {
+	case <-fakeClient.IsSubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SSEWorker did not re-subscribe after the token arrived")
+	}
+	if worker.subscribedToken != "fresh-token" {
+		t.Errorf("Expected the new subscription to carry the token, got %q", worker.subscribedToken)
+	}
+}
+
+func TestSSEWorker_KeepsAuthenticatedStream(t *testing.T) {
+	fakeClient := newFakeSSEClient()
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := NewAuthManager(nil, logger)
+	auth.setToken(&oauth2.Token{AccessToken: "existing-token"})
+
+	worker := NewSSEWorker(fakeClient, make(chan string, 1), make(chan string, 1), logger)
+	worker.SetAuthenticator(auth)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go worker.Run(ctx, cancel)
+	<-fakeClient.IsSubscribed
+
+	// An already-authenticated stream must not churn.
+	select {
+	case <-fakeClient.IsSubscribed:
+		t.Error("SSEWorker re-subscribed despite holding a token from the start")
+	case <-time.After(2 * time.Second):
+	}
+}
+
+func TestSSEWorker_ResubscribesOnTokenChange(t *testing.T) {
+	fakeClient := newFakeSSEClient()
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := NewAuthManager(nil, logger)
+	auth.setToken(&oauth2.Token{AccessToken: "old-token"})
+
+	worker := NewSSEWorker(fakeClient, make(chan string, 1), make(chan string, 1), logger)
+	worker.SetAuthenticator(auth)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go worker.Run(ctx, cancel)
+	<-fakeClient.IsSubscribed
+
+	// A refresh or re-authentication swaps the token; the stream bound to
+	// the old identity must be replaced.
+	auth.setToken(&oauth2.Token{AccessToken: "new-token"})
+
+	select {
+	case <-fakeClient.IsSubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SSEWorker did not re-subscribe after the token changed")
+	}
+	if worker.subscribedToken != "new-token" {
+		t.Errorf("Expected the new subscription to carry the new token, got %q", worker.subscribedToken)
+	}
+}
+
+func TestSSEWorker_ReconnectsOnStall(t *testing.T) {
+	fakeClient := newFakeSSEClient()
+	logger := NewZapLogger(zap.NewNop().Sugar())
+
+	worker := NewSSEWorker(fakeClient, make(chan string, 1), make(chan string, 1), logger)
+	worker.SetStallTimeout(300 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	go worker.Run(ctx, cancel)
+	<-fakeClient.IsSubscribed
+
+	// Nothing arrives on the stream; the worker must tear it down and
+	// subscribe again after the reconnect backoff.
+	select {
+	case <-fakeClient.IsSubscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SSEWorker did not reconnect after the stream stalled")
+	}
+}
+
+func TestSSEWorker_EventsResetStallTimer(t *testing.T) {
+	fakeClient := newFakeSSEClient()
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	outputChan := make(chan string, 10)
+
+	worker := NewSSEWorker(fakeClient, make(chan string, 1), outputChan, logger)
+	worker.SetStallTimeout(500 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	go worker.Run(ctx, cancel)
+	<-fakeClient.IsSubscribed
+
+	// A steady trickle of events keeps the stream alive well past the
+	// stall timeout. Data-less heartbeats count as activity too.
+	for i := 0; i < 5; i++ {
+		fakeClient.Events <- &sse.Event{ID: []byte("hb")}
+		time.Sleep(300 * time.Millisecond)
+	}
+
+	select {
+	case <-fakeClient.IsSubscribed:
+		t.Error("SSEWorker reconnected despite steady traffic")
+	default:
+	}
+	select {
+	case msg := <-outputChan:
+		t.Errorf("Heartbeat event leaked to the output channel: %q", msg)
+	default:
+	}
+}
+
+func TestSSEStallTimeout_ConfigValidation(t *testing.T) {
+	cfg := Config{
+		UseSSE:          true,
+		Endpoint:        "http://localhost:8080",
+		SSEPath:         "/sse",
+		Logger:          NewZapLogger(zap.NewNop().Sugar()),
+		SSEStallTimeout: -time.Second,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a negative SSEStallTimeout rejected")
+	}
+
+	cfg = Config{
+		Endpoint:        "http://localhost:8080",
+		MCPPath:         "/mcp",
+		Logger:          NewZapLogger(zap.NewNop().Sugar()),
+		SSEStallTimeout: time.Minute,
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected SSEStallTimeout on the http transport rejected")
+	}
+}
@@ -0,0 +1,61 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// StaticTokenAuthenticator implements Authenticator with a pre-provisioned
+// bearer token, for servers that hand out long-lived credentials instead of
+// running interactive OAuth. There is no flow to start and nothing to
+// refresh: a 401 with a static token means the token itself is bad, so
+// HandleChallenge reports that instead of attempting OIDC discovery.
+type StaticTokenAuthenticator struct {
+	token  string
+	logger Logger
+}
+
+// NewStaticTokenAuthenticator constructs a StaticTokenAuthenticator around
+// the given token.
+func NewStaticTokenAuthenticator(token string, logger Logger) *StaticTokenAuthenticator {
+	return &StaticTokenAuthenticator{
+		token:  token,
+		logger: logger,
+	}
+}
+
+// HandleChallenge implements Authenticator. A static token cannot be
+// renegotiated, so a challenge is surfaced as an error for the user to act
+// on rather than starting an auth flow.
+func (s *StaticTokenAuthenticator) HandleChallenge(ctx context.Context, resp *http.Response) (string, func(), error) {
+	s.logger.Warnw("Server rejected the static bearer token", "status", resp.StatusCode)
+	return "", nil, fmt.Errorf("server rejected the static bearer token (status %d); verify the token is valid and has not expired", resp.StatusCode)
+}
+
+// Token implements Authenticator by returning the configured token.
+func (s *StaticTokenAuthenticator) Token() string {
+	return s.token
+}
+
+// Refresh implements Authenticator. Static tokens have no refresh flow.
+func (s *StaticTokenAuthenticator) Refresh(ctx context.Context) error {
+	return fmt.Errorf("static bearer tokens cannot be refreshed; supply a new token")
+}
+
+// ReadTokenFile loads a bearer token from a file, trimming surrounding
+// whitespace so trailing newlines from `echo` or editors don't corrupt the
+// Authorization header.
+func ReadTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token file: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", path)
+	}
+	return token, nil
+}
@@ -0,0 +1,102 @@
+package mcpengine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Static Token Authenticator Tests =====
+
+func TestStaticTokenAuthenticator_Token(t *testing.T) {
+	auth := NewStaticTokenAuthenticator("static-token", NewZapLogger(zap.NewNop().Sugar()))
+	if got := auth.Token(); got != "static-token" {
+		t.Errorf("Expected token %q, got %q", "static-token", got)
+	}
+}
+
+func TestStaticTokenAuthenticator_ChallengeFails(t *testing.T) {
+	auth := NewStaticTokenAuthenticator("static-token", NewZapLogger(zap.NewNop().Sugar()))
+
+	resp := &http.Response{StatusCode: http.StatusUnauthorized}
+	_, _, err := auth.HandleChallenge(context.Background(), resp)
+	if err == nil {
+		t.Fatal("Expected HandleChallenge to fail for a static token")
+	}
+	if !strings.Contains(err.Error(), "static bearer token") {
+		t.Errorf("Error should explain the static token was rejected, got %q", err)
+	}
+
+	if err := auth.Refresh(context.Background()); err == nil {
+		t.Error("Expected Refresh to fail for a static token")
+	}
+}
+
+func TestStaticTokenAuthenticator_AttachedByPostSender(t *testing.T) {
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+
+	authChan := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authChan <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	endpointChan <- "/api"
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := NewStaticTokenAuthenticator("static-token", logger)
+	sender := NewHTTPPostSender(&http.Client{}, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	select {
+	case got := <-authChan:
+		if got != "Bearer static-token" {
+			t.Errorf("Expected Authorization %q, got %q", "Bearer static-token", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No request was received")
+	}
+}
+
+func TestReadTokenFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("file-token\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+
+	token, err := ReadTokenFile(path)
+	if err != nil {
+		t.Fatalf("ReadTokenFile failed: %v", err)
+	}
+	if token != "file-token" {
+		t.Errorf("Expected trimmed token %q, got %q", "file-token", token)
+	}
+}
+
+func TestReadTokenFile_Errors(t *testing.T) {
+	if _, err := ReadTokenFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty")
+	if err := os.WriteFile(empty, []byte("  \n"), 0o600); err != nil {
+		t.Fatalf("Failed to write token file: %v", err)
+	}
+	if _, err := ReadTokenFile(empty); err == nil {
+		t.Error("Expected an error for an empty token file")
+	}
+}
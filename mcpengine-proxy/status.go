@@ -0,0 +1,101 @@
+package mcpengine
+
+// EngineState names the engine's position in its lifecycle.
+type EngineState string
+
+const (
+	// StateIdle means Start has not been called yet.
+	StateIdle EngineState = "idle"
+	// StateStarting means workers are running but no POST endpoint has
+	// been received.
+	StateStarting EngineState = "starting"
+	// StateConnected means the engine can deliver messages.
+	StateConnected EngineState = "connected"
+	// StateStopped means the engine has exited.
+	StateStopped EngineState = "stopped"
+)
+
+// Status is a point-in-time snapshot of the engine, for embedders' UIs and
+// the CLI. Token fields reflect the Authenticator's view at the moment of
+// the call.
+type Status struct {
+	// State is the engine's lifecycle position.
+	State EngineState
+	// Transport is the configured wire protocol.
+	Transport TransportMode
+	// Endpoint is the MCP server's base URL.
+	Endpoint string
+	// POSTPath is the path messages are delivered to, once known.
+	POSTPath string
+	// SessionID is the server-assigned session on the streamable-http
+	// transport, when one has been issued.
+	SessionID string
+	// TokenPresent reports whether the Authenticator currently holds a
+	// token.
+	TokenPresent bool
+	// TokenExpired reports whether that token is past its expiry, when the
+	// Authenticator tracks expiry.
+	TokenExpired bool
+	// PendingRequests is the number of requests awaiting a response, when
+	// a request timeout is configured.
+	PendingRequests int
+	// RequestStats holds per-method latency and outcome aggregates, when
+	// metrics are enabled.
+	RequestStats []MethodStats
+	// LastError is the most recent terminal error, if any.
+	LastError error
+}
+
+// Status reports a snapshot of the engine's current state. It is safe to
+// call from any goroutine, before, during and after Start.
+func (mcp *MCPEngine) Status() Status {
+	mcp.statusMu.Lock()
+	defer mcp.statusMu.Unlock()
+	status := Status{
+		State:     mcp.state,
+		Transport: mcp.transport,
+		Endpoint:  mcp.endpoint,
+		POSTPath:  mcp.postPath,
+		LastError: mcp.lastErr,
+	}
+	if status.State == "" {
+		status.State = StateIdle
+	}
+	if mcp.tracker != nil {
+		status.PendingRequests = mcp.tracker.Pending()
+	}
+	if mcp.metrics != nil {
+		status.RequestStats = mcp.metrics.Snapshot()
+	}
+	if mcp.streamSender != nil {
+		status.SessionID = mcp.streamSender.SessionID()
+	}
+	if mcp.auth != nil {
+		status.TokenPresent = mcp.auth.Token() != ""
+		if ea, ok := mcp.auth.(ExpiryAwareAuthenticator); ok {
+			status.TokenExpired = ea.TokenExpired()
+		}
+	}
+	return status
+}
+
+// setState moves the engine to the given lifecycle state.
+func (mcp *MCPEngine) setState(state EngineState) {
+	mcp.statusMu.Lock()
+	defer mcp.statusMu.Unlock()
+	mcp.state = state
+}
+
+// setPOSTPath records the delivery path announced by the server.
+func (mcp *MCPEngine) setPOSTPath(path string) {
+	mcp.statusMu.Lock()
+	defer mcp.statusMu.Unlock()
+	mcp.postPath = path
+}
+
+// setLastError records the engine's terminal error.
+func (mcp *MCPEngine) setLastError(err error) {
+	mcp.statusMu.Lock()
+	defer mcp.statusMu.Unlock()
+	mcp.lastErr = err
+}
@@ -0,0 +1,109 @@
+package mcpengine
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"go.uber.org/zap"
+)
+
+// ===== Status Tests =====
+
+func TestStatus_Lifecycle(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	input := mcpenginetest.NewBlockReader(strings.NewReader(""))
+	engine, err := New(Config{
+		Endpoint:       ts.URL,
+		MCPPath:        "/mcp",
+		Logger:         NewZapLogger(zap.NewNop().Sugar()),
+		Input:          input,
+		RequestTimeout: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	if got := engine.Status(); got.State != StateIdle {
+		t.Errorf("Expected idle before Start, got %q", got.State)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go engine.Start(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if engine.Status().State == StateConnected {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	status := engine.Status()
+	if status.State != StateConnected {
+		t.Fatalf("Expected connected, got %q", status.State)
+	}
+	if status.POSTPath != "/mcp" {
+		t.Errorf("Expected the announced POST path, got %q", status.POSTPath)
+	}
+	if status.Endpoint != ts.URL {
+		t.Errorf("Expected the configured endpoint, got %q", status.Endpoint)
+	}
+	if status.PendingRequests != 0 {
+		t.Errorf("Expected no pending requests, got %d", status.PendingRequests)
+	}
+
+	cancel()
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer stopCancel()
+	engine.Stop(stopCtx)
+	if got := engine.Status(); got.State != StateStopped {
+		t.Errorf("Expected stopped after exit, got %q", got.State)
+	}
+}
+
+func TestStatus_LastError(t *testing.T) {
+	input := mcpenginetest.NewBlockReader(strings.NewReader(""))
+	engine, err := New(Config{
+		Endpoint:       "http://localhost:1",
+		Transport:      TransportSSE,
+		SSEPath:        "/sse",
+		Logger:         NewZapLogger(zap.NewNop().Sugar()),
+		Input:          input,
+		StartupTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if err := engine.Start(context.Background()); err == nil {
+		t.Fatal("Expected Start to fail on the startup deadline")
+	}
+	status := engine.Status()
+	if status.LastError == nil || !strings.Contains(status.LastError.Error(), "startup deadline") {
+		t.Errorf("Expected the startup error to be recorded, got %v", status.LastError)
+	}
+	if status.State != StateStopped {
+		t.Errorf("Expected stopped after failure, got %q", status.State)
+	}
+}
+
+func TestStatus_TokenFields(t *testing.T) {
+	engine := &MCPEngine{
+		auth: &expiringAuthenticator{token: "tok", expired: true},
+	}
+	status := engine.Status()
+	if !status.TokenPresent {
+		t.Error("Expected TokenPresent with a held token")
+	}
+	if !status.TokenExpired {
+		t.Error("Expected TokenExpired from the expiry-aware authenticator")
+	}
+}
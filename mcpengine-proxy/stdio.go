@@ -0,0 +1,153 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// StdioBridge launches a local MCP server command and bridges JSON-RPC
+// messages to it over the child's stdin/stdout, so local servers (npx,
+// uvx, plain binaries) get the same middleware, filtering, recording and
+// logging as remote ones. The child's stderr is forwarded to the log. When
+// the child exits or the input channel closes, the bridge shuts the engine
+// down.
+type StdioBridge struct {
+	command        []string
+	env            []string
+	inputChan      chan string // Messages for the child's stdin.
+	outputChan     chan string // Messages read from the child's stdout.
+	maxMessageSize int
+	logger         Logger
+}
+
+// NewStdioBridge constructs a StdioBridge for the given command line. The
+// first element is the executable; the rest are its arguments.
+func NewStdioBridge(command []string, inputChan, outputChan chan string, logger Logger) *StdioBridge {
+	return &StdioBridge{
+		command:    command,
+		inputChan:  inputChan,
+		outputChan: outputChan,
+		logger:     logger,
+	}
+}
+
+// SetEnv appends KEY=VALUE entries to the child's environment, which
+// otherwise inherits the proxy's. Must be called before Run.
+func (sb *StdioBridge) SetEnv(env []string) {
+	sb.env = env
+}
+
+// SetMaxMessageSize caps how large a single line from the child may be.
+// Zero keeps the default (defaultMaxMessageSize). Must be called before
+// Run.
+func (sb *StdioBridge) SetMaxMessageSize(n int) {
+	sb.maxMessageSize = n
+}
+
+// Run starts the child and pumps messages in both directions until the
+// context is cancelled, the input channel closes, or the child exits.
+func (sb *StdioBridge) Run(ctx context.Context, cancel context.CancelFunc) error {
+	cmd := exec.CommandContext(ctx, sb.command[0], sb.command[1:]...)
+	cmd.Env = append(os.Environ(), sb.env...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return fmt.Errorf("stdio: stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return fmt.Errorf("stdio: stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		cancel()
+		return fmt.Errorf("stdio: stderr pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return fmt.Errorf("stdio: failed to start %q: %w", sb.command[0], err)
+	}
+	sb.logger.Infow("Launched local MCP server", "command", sb.command[0], "pid", cmd.Process.Pid)
+
+	// The child's stderr is its log; surface it line by line.
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			sb.logger.Warnw("Server stderr", "line", scanner.Text())
+		}
+	}()
+
+	maxSize := sb.maxMessageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxMessageSize
+	}
+	initialSize := bufio.MaxScanTokenSize
+	if maxSize < initialSize {
+		initialSize = maxSize
+	}
+
+	// Child stdout -> output channel. EOF here means the child is gone,
+	// so the engine shuts down rather than wedging silently.
+	readErrChan := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, initialSize), maxSize)
+		for scanner.Scan() {
+			line := scanner.Text()
+			sb.logger.Debugw("Read from server", "line", line)
+			select {
+			case sb.outputChan <- line:
+			case <-ctx.Done():
+				readErrChan <- ctx.Err()
+				return
+			}
+		}
+		readErrChan <- scanner.Err()
+	}()
+
+	// Input channel -> child stdin, until the client side closes or the
+	// child dies.
+	var runErr error
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			runErr = ctx.Err()
+			break loop
+		case err := <-readErrChan:
+			readErrChan = nil
+			if err != nil && ctx.Err() == nil {
+				sb.logger.Errorw("Lost the server's stdout", "err", err)
+				runErr = err
+			} else if ctx.Err() == nil {
+				sb.logger.Info("Server closed its stdout")
+			}
+			break loop
+		case msg, ok := <-sb.inputChan:
+			if !ok {
+				sb.logger.Info("Client input closed; closing server stdin")
+				break loop
+			}
+			if _, err := io.WriteString(stdin, msg+"\n"); err != nil {
+				sb.logger.Errorw("Failed to write to server", "err", err)
+				runErr = err
+				break loop
+			}
+		}
+	}
+
+	stdin.Close()
+	cancel()
+	if err := cmd.Wait(); err != nil && runErr == nil {
+		sb.logger.Warnw("Server exited", "err", err)
+	} else {
+		sb.logger.Info("Server exited")
+	}
+	return runErr
+}
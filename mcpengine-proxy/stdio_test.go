@@ -0,0 +1,133 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"go.uber.org/zap"
+)
+
+// ===== Stdio Transport Tests =====
+
+func TestStdioBridge_Echo(t *testing.T) {
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	// cat echoes each line straight back, standing in for a local server.
+	input := mcpenginetest.NewBlockReader(strings.NewReader(request + "\n"))
+	outputReader, outputWriter := io.Pipe()
+
+	engine, err := New(Config{
+		Command: []string{"cat"},
+		Logger:  NewZapLogger(zap.NewNop().Sugar()),
+		Input:   input,
+		Output:  outputWriter,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	lineChan := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		if scanner.Scan() {
+			lineChan <- scanner.Text()
+		}
+	}()
+
+	select {
+	case got := <-lineChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back through the bridge")
+	}
+
+	if engine.Status().State != StateConnected {
+		t.Errorf("Expected the stdio engine to report connected")
+	}
+}
+
+func TestStdioBridge_ChildExit(t *testing.T) {
+	// A child that exits immediately must shut the engine down instead of
+	// leaving it wedged.
+	input := mcpenginetest.NewBlockReader(strings.NewReader(""))
+	engine, err := New(Config{
+		Command: []string{"true"},
+		Logger:  NewZapLogger(zap.NewNop().Sugar()),
+		Input:   input,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		engine.Start(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Engine did not exit after the child died")
+	}
+}
+
+func TestStdioBridge_Env(t *testing.T) {
+	// The child sees entries from CommandEnv; sh echoes one back as a
+	// message line.
+	input := mcpenginetest.NewBlockReader(strings.NewReader(""))
+	outputReader, outputWriter := io.Pipe()
+	engine, err := New(Config{
+		Command:    []string{"sh", "-c", `echo "$MCP_TEST_VALUE"; cat`},
+		CommandEnv: []string{"MCP_TEST_VALUE=from-env"},
+		Logger:     NewZapLogger(zap.NewNop().Sugar()),
+		Input:      input,
+		Output:     outputWriter,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	lineChan := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		if scanner.Scan() {
+			lineChan <- scanner.Text()
+		}
+	}()
+	select {
+	case got := <-lineChan:
+		if got != "from-env" {
+			t.Errorf("Expected the environment value, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No output from the child")
+	}
+}
+
+func TestConfigValidate_Stdio(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	if err := (Config{Transport: TransportStdio, Logger: logger}).Validate(); err == nil {
+		t.Error("Expected an error for a missing Command")
+	}
+	if err := (Config{Command: []string{"cat"}, Logger: logger}).Validate(); err != nil {
+		t.Errorf("Expected a bare Command config to validate, got %v", err)
+	}
+	if err := (Config{Command: []string{"cat"}, SSEPath: "/sse", Logger: logger}).Validate(); err == nil {
+		t.Error("Expected an error combining Command with an SSE path")
+	}
+}
@@ -0,0 +1,465 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// TransportMode selects how the engine talks to the upstream MCP server.
+type TransportMode string
+
+const (
+	// TransportSSE is the original HTTP+SSE transport: a GET /sse stream
+	// carrying the session endpoint and responses, plus POSTs for requests.
+	TransportSSE TransportMode = "sse"
+	// TransportHTTP posts to a fixed path and reads responses from the
+	// POST bodies.
+	TransportHTTP TransportMode = "http"
+	// TransportStreamableHTTP implements the Streamable HTTP transport from
+	// the 2025-03-26 MCP revision: a single endpoint, POSTs that may answer
+	// with JSON or an SSE stream, and an Mcp-Session-Id header.
+	TransportStreamableHTTP TransportMode = "streamable-http"
+	// TransportStdio launches a local server command (Config.Command) and
+	// bridges to it over the child's stdin/stdout; no network connection
+	// is made.
+	TransportStdio TransportMode = "stdio"
+	// TransportDocker runs the server inside a container (Config.Docker)
+	// and bridges stdio through it.
+	TransportDocker TransportMode = "docker"
+	// TransportPipe bridges to a server behind a Windows named pipe, named
+	// by an npipe:// endpoint.
+	TransportPipe TransportMode = "pipe"
+	// TransportGRPC is an experimental transport that carries JSON-RPC
+	// messages over a gRPC bidirectional stream (Config.GRPC).
+	TransportGRPC TransportMode = "grpc"
+	// TransportTCP speaks newline-delimited JSON-RPC over a raw TCP (or
+	// TLS) connection, named by a tcp:// or tcps:// endpoint.
+	TransportTCP TransportMode = "tcp"
+)
+
+// sessionIDHeader carries the server-assigned session on the Streamable
+// HTTP transport.
+const sessionIDHeader = "Mcp-Session-Id"
+
+// StreamableHTTPSender is the worker for the Streamable HTTP transport. It
+// posts each message to the MCP endpoint, tracks the Mcp-Session-Id the
+// server assigns, and handles both JSON and SSE-formatted response bodies.
+type StreamableHTTPSender struct {
+	client         *http.Client
+	host           string
+	endpointChan   chan string // Supplies the MCP path, mirroring HTTPPostSender.
+	inputChan      chan string // Messages to send.
+	outputChan     chan string // Responses and streamed events.
+	auth           Authenticator
+	notifier       *Notifier
+	deadLetters    *DeadLetterQueue
+	sessions       *SessionStore
+	maxMessageSize int
+	headers        map[string]string
+	hooks          LifecycleHooks
+	compress       bool
+	logger         Logger
+
+	sessionMu sync.RWMutex
+	sessionID string
+
+	// Park-on-auth state, touched only from the Run goroutine; the auth
+	// flow goroutine signals completion over authDone.
+	parkAuth    bool
+	authPending bool
+	parked      []string
+	authDone    chan struct{}
+}
+
+// NewStreamableHTTPSender constructs a new StreamableHTTPSender.
+func NewStreamableHTTPSender(
+	client *http.Client, host string,
+	endpointChan, inputChan, outputChan chan string,
+	auth Authenticator,
+	logger Logger,
+) *StreamableHTTPSender {
+	return &StreamableHTTPSender{
+		client:       client,
+		host:         host,
+		endpointChan: endpointChan,
+		inputChan:    inputChan,
+		outputChan:   outputChan,
+		auth:         auth,
+		logger:       logger,
+		authDone:     make(chan struct{}, 1),
+	}
+}
+
+// SetNotifier attaches a Notifier for delivery and auth events.
+// Must be called before Run.
+func (ss *StreamableHTTPSender) SetNotifier(n *Notifier) {
+	ss.notifier = n
+}
+
+// SetDeadLetterQueue attaches a queue that persists undeliverable messages
+// for later inspection and re-dispatch. Must be called before Run.
+func (ss *StreamableHTTPSender) SetDeadLetterQueue(dlq *DeadLetterQueue) {
+	ss.deadLetters = dlq
+}
+
+// SetParkOnAuth makes an auth challenge park the blocked request (and any
+// calls that follow) until the flow completes, then re-send them, instead
+// of answering with a synthetic go-authenticate result. Must be called
+// before Run.
+func (ss *StreamableHTTPSender) SetParkOnAuth(park bool) {
+	ss.parkAuth = park
+}
+
+// SetSessionStore attaches a store that persists the server-assigned
+// session id, so a restart can resume the session where the server still
+// honors it. Must be called before Run.
+func (ss *StreamableHTTPSender) SetSessionStore(s *SessionStore) {
+	ss.sessions = s
+}
+
+// SetMaxMessageSize caps how large a single streamed event line may be.
+// Zero keeps the default (defaultMaxMessageSize). Must be called before Run.
+func (ss *StreamableHTTPSender) SetMaxMessageSize(n int) {
+	ss.maxMessageSize = n
+}
+
+// SetHeaders attaches static headers to every POST. Must be called before
+// Run.
+func (ss *StreamableHTTPSender) SetHeaders(headers map[string]string) {
+	ss.headers = headers
+}
+
+// SetLifecycleHooks attaches callbacks for auth events. Must be called
+// before Run.
+func (ss *StreamableHTTPSender) SetLifecycleHooks(hooks LifecycleHooks) {
+	ss.hooks = hooks
+}
+
+// SetCompress gzip-compresses POST bodies (with Content-Encoding), for
+// chatty agents talking to remote servers over slow links. Compressed
+// responses are decompressed either way. Must be called before Run.
+func (ss *StreamableHTTPSender) SetCompress(compress bool) {
+	ss.compress = compress
+}
+
+// SessionID returns the session assigned by the server, if any.
+func (ss *StreamableHTTPSender) SessionID() string {
+	ss.sessionMu.RLock()
+	defer ss.sessionMu.RUnlock()
+	return ss.sessionID
+}
+
+func (ss *StreamableHTTPSender) setSessionID(id string) {
+	ss.sessionMu.Lock()
+	defer ss.sessionMu.Unlock()
+	ss.sessionID = id
+}
+
+// Run waits for the MCP path and then posts each input message, forwarding
+// response payloads to the output channel. It stops when the input channel
+// closes or the context is cancelled.
+func (ss *StreamableHTTPSender) Run(ctx context.Context, cancel context.CancelFunc) error {
+	ss.logger.Debug("Starting StreamableHTTPSender")
+	// A fresh persisted session id is echoed from the first POST on, so a
+	// quick restart resumes the session instead of re-initializing.
+	if rec := ss.sessions.Load(); rec != nil && rec.SessionID != "" {
+		ss.logger.Infow("Resuming persisted session", "session-id", rec.SessionID)
+		ss.setSessionID(rec.SessionID)
+	}
+	var endpointPath string
+	select {
+	case <-ctx.Done():
+		ss.logger.Info("StreamableHTTPSender canceled before receiving endpoint")
+		return ctx.Err()
+	case endpointPath = <-ss.endpointChan:
+	}
+	parsedURL, err := url.Parse(fmt.Sprintf("%s%s", ss.host, endpointPath))
+	if err != nil {
+		ss.logger.Errorf("Invalid endpoint URL: %v", err)
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			ss.logger.Info("StreamableHTTPSender canceled")
+			return ctx.Err()
+		case <-ss.authDone:
+			ss.replayParked(ctx, parsedURL.String())
+		case msg, ok := <-ss.inputChan:
+			if !ok {
+				ss.logger.Info("Input channel closed, terminating StreamableHTTPSender")
+				return nil
+			}
+			// Requests arriving mid-auth-flow park behind the one that
+			// triggered it, keeping their order for the replay.
+			if ss.authPending {
+				ss.parked = append(ss.parked, msg)
+				continue
+			}
+			ss.post(ctx, parsedURL.String(), msg)
+		}
+	}
+}
+
+// post sends one message and routes the response.
+func (ss *StreamableHTTPSender) post(ctx context.Context, endpoint, msg string) {
+	// Renew an expired credential up front rather than burning a round
+	// trip on a guaranteed 401.
+	if ea, ok := ss.auth.(ExpiryAwareAuthenticator); ok && ea.TokenExpired() {
+		if err := ss.auth.Refresh(ctx); err != nil {
+			ss.logger.Debugf("Proactive token refresh failed: %v", err)
+		}
+	}
+	var body io.Reader = strings.NewReader(msg)
+	compressed := false
+	if ss.compress {
+		if gzipped, err := gzipBody(msg); err != nil {
+			ss.logger.Errorf("Failed to compress request, sending uncompressed: %v", err)
+		} else {
+			body = gzipped
+			compressed = true
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		ss.logger.Errorf("Failed to create request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if compressed {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	// The server may answer with plain JSON or an SSE stream.
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for name, value := range ss.headers {
+		req.Header.Set(name, value)
+	}
+	if session := ss.SessionID(); session != "" {
+		req.Header.Set(sessionIDHeader, session)
+	}
+	if ra, ok := ss.auth.(RequestAuthenticator); ok {
+		ra.Authorize(req)
+	} else if token := ss.auth.Token(); token != "" {
+		ss.logger.Debug("Setting auth token")
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := ss.client.Do(req)
+	if err != nil {
+		ss.logger.Errorf("Failed to post message: %v", err)
+		ss.notifier.Notify("error", "Failed to deliver message to server", map[string]interface{}{
+			"error": err.Error(),
+		})
+		ss.deadLetters.Add(msg, fmt.Sprintf("delivery failed: %v", err))
+		if id := getMessageID(msg, ss.logger); id != nil {
+			ss.outputChan <- jsonRPCErrorString(id, deliveryErrorCode,
+				"failed to deliver message to server",
+				map[string]interface{}{"error": err.Error()}, ss.logger)
+		}
+		return
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	// The session id is assigned on the initialize response and must be
+	// echoed on every subsequent request.
+	if session := resp.Header.Get(sessionIDHeader); session != "" && session != ss.SessionID() {
+		ss.logger.Debugw("Server assigned session", "session-id", session)
+		ss.setSessionID(session)
+		ss.sessions.SaveSessionID(session)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		reader, err := responseBody(resp)
+		if err != nil {
+			ss.logger.Errorf("Failed to decompress response: %v", err)
+			return
+		}
+		defer reader.Close()
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			ss.forwardSSEBody(reader)
+			return
+		}
+		respBody, err := io.ReadAll(reader)
+		if err != nil {
+			ss.logger.Errorf("Error reading body: %v", err)
+			return
+		}
+		ss.outputChan <- string(respBody)
+	case http.StatusAccepted:
+		ss.logger.Debugf("Message accepted: %s", msg)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		ss.logger.Debug("Unauthorized message")
+		ss.notifier.Notify("warning", "Authentication required by server", map[string]interface{}{
+			"status": resp.StatusCode,
+		})
+		id := getMessageID(msg, ss.logger)
+		authURL, wait, err := ss.auth.HandleChallenge(ctx, resp)
+		if err != nil {
+			ss.logger.Errorw("Failed to create auth challenge", "err", err)
+			return
+		}
+		if authURL != "" {
+			ss.hooks.authRequired(authURL)
+		}
+		go func() {
+			ss.logger.Info("Waiting for auth callback server")
+			wait()
+			ss.logger.Info("Auth callback server closed")
+			ss.hooks.authCompleted()
+			select {
+			case ss.authDone <- struct{}{}:
+			default:
+			}
+		}()
+		if ss.parkAuth {
+			ss.authPending = true
+			ss.parked = append(ss.parked, msg)
+			ss.logger.Infow("Parked request pending authentication", "id", string(id))
+			ss.notifier.Notify("info", "Request parked until authentication completes", map[string]interface{}{
+				"url": authURL,
+			})
+			return
+		}
+		authErr := createAuthError(id, authURL)
+		authErrData, err := json.Marshal(authErr)
+		if err != nil {
+			ss.logger.Errorf("Failed to marshal auth error: %v", err)
+			return
+		}
+		ss.outputChan <- string(authErrData)
+	case http.StatusTooManyRequests:
+		// Pause for the server's requested backoff, then send the same
+		// message again; the retry is invisible to the client.
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if waitRetryAfter(ctx, resp, ss.notifier, ss.logger) {
+			ss.post(ctx, endpoint, msg)
+		}
+	default:
+		// A 404 on an established session means the server no longer
+		// recognizes it (e.g. a resumed session the server has reaped);
+		// drop it so the next initialize starts fresh.
+		if resp.StatusCode == http.StatusNotFound && ss.SessionID() != "" {
+			ss.logger.Warnw("Server no longer recognizes the session; discarding it", "session-id", ss.SessionID())
+			ss.setSessionID("")
+			ss.sessions.Clear()
+		}
+		ss.logger.Warnf("Unexpected response status: %d", resp.StatusCode)
+		ss.notifier.Notify("warning", "Server returned an error status; message dropped", map[string]interface{}{
+			"status": resp.StatusCode,
+		})
+		ss.deadLetters.Add(msg, fmt.Sprintf("unexpected status %d", resp.StatusCode))
+		if id := getMessageID(msg, ss.logger); id != nil {
+			ss.outputChan <- upstreamErrorString(id, resp, ss.logger)
+		}
+	}
+}
+
+// replayParked re-sends the requests that were parked during the auth flow,
+// in order. A request that hits a fresh challenge re-parks itself and the
+// rest wait for the next completion.
+func (ss *StreamableHTTPSender) replayParked(ctx context.Context, endpoint string) {
+	ss.authPending = false
+	parked := ss.parked
+	ss.parked = nil
+	if len(parked) == 0 {
+		return
+	}
+	ss.logger.Infow("Authentication completed; replaying parked requests", "count", len(parked))
+	for _, msg := range parked {
+		if ss.authPending {
+			ss.parked = append(ss.parked, msg)
+			continue
+		}
+		ss.post(ctx, endpoint, msg)
+	}
+}
+
+// forwardSSEBody reads an SSE-formatted response body and forwards each
+// event's data payload to the output channel.
+func (ss *StreamableHTTPSender) forwardSSEBody(body io.Reader) {
+	maxSize := ss.maxMessageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxMessageSize
+	}
+	// The scanner honors the larger of the initial capacity and the max,
+	// so the initial buffer must not exceed the cap.
+	initialSize := bufio.MaxScanTokenSize
+	if maxSize < initialSize {
+		initialSize = maxSize
+	}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, initialSize), maxSize)
+	var data []string
+	flush := func() {
+		if len(data) == 0 {
+			return
+		}
+		ss.outputChan <- strings.Join(data, "\n")
+		data = data[:0]
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		default:
+			// event:, id:, retry:, and comment lines don't carry payload.
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		ss.logger.Errorf("Error reading SSE response body: %v", err)
+		if err == bufio.ErrTooLong {
+			ss.outputChan <- jsonRPCErrorString(nil, messageTooLargeErrorCode,
+				fmt.Sprintf("streamed message exceeds the maximum size of %d bytes", maxSize),
+				map[string]interface{}{"max_bytes": maxSize}, ss.logger)
+		}
+	}
+}
+
+// DetectTransport probes endpoint+mcpPath to decide whether the server
+// speaks Streamable HTTP. Servers on the old transport respond to a bare
+// POST on the MCP endpoint with 404/405; Streamable HTTP servers answer
+// with a session or a JSON/SSE body.
+func DetectTransport(ctx context.Context, client *http.Client, endpoint, mcpPath string, logger Logger) TransportMode {
+	probe := `{"jsonrpc": "2.0", "id": 0, "method": "ping"}`
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+mcpPath, strings.NewReader(probe))
+	if err != nil {
+		logger.Debugf("Transport probe failed to build: %v", err)
+		return TransportSSE
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		logger.Debugf("Transport probe failed: %v", err)
+		return TransportSSE
+	}
+	defer func() {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed {
+		logger.Debugw("Transport probe rejected, assuming SSE transport", "status", resp.StatusCode)
+		return TransportSSE
+	}
+	logger.Debugw("Transport probe accepted, using Streamable HTTP", "status", resp.StatusCode)
+	return TransportStreamableHTTP
+}
@@ -0,0 +1,155 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Streamable HTTP Sender Tests =====
+
+func runStreamableSender(t *testing.T, ts *httptest.Server, messages []string) (*StreamableHTTPSender, chan string) {
+	t.Helper()
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, len(messages))
+	outputChan := make(chan string, 10)
+	endpointChan <- "/mcp"
+	for _, msg := range messages {
+		inputChan <- msg
+	}
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	sender := NewStreamableHTTPSender(&http.Client{}, ts.URL, endpointChan, inputChan, outputChan, NewAuthManager(nil, logger), logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	if err := sender.Run(ctx, cancel); err != nil {
+		t.Fatalf("StreamableHTTPSender Run returned error: %v", err)
+	}
+	return sender, outputChan
+}
+
+func TestStreamableHTTPSender_JSONResponse(t *testing.T) {
+	response := `{"jsonrpc": "2.0", "id": 1, "result": {}}`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, response)
+	}))
+	defer ts.Close()
+
+	_, outputChan := runStreamableSender(t, ts, []string{`{"id": 1, "method": "test"}`})
+
+	select {
+	case msg := <-outputChan:
+		if msg != response {
+			t.Errorf("Expected %q, got %q", response, msg)
+		}
+	default:
+		t.Fatal("Expected a response on the output channel")
+	}
+}
+
+func TestStreamableHTTPSender_SSEResponse(t *testing.T) {
+	// A single POST may answer with an SSE stream carrying several events.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message\ndata: {\"id\": 1, \"result\": \"first\"}\n\n")
+		fmt.Fprint(w, "event: message\ndata: {\"id\": 2, \"result\": \"second\"}\n\n")
+	}))
+	defer ts.Close()
+
+	_, outputChan := runStreamableSender(t, ts, []string{`{"id": 1, "method": "test"}`})
+
+	expected := []string{
+		`{"id": 1, "result": "first"}`,
+		`{"id": 2, "result": "second"}`,
+	}
+	for i, want := range expected {
+		select {
+		case msg := <-outputChan:
+			if msg != want {
+				t.Errorf("Event %d: expected %q, got %q", i, want, msg)
+			}
+		default:
+			t.Fatalf("Expected event %d on the output channel", i)
+		}
+	}
+}
+
+func TestStreamableHTTPSender_TracksSessionID(t *testing.T) {
+	var mu sync.Mutex
+	var receivedSessions []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		receivedSessions = append(receivedSessions, r.Header.Get(sessionIDHeader))
+		mu.Unlock()
+		w.Header().Set(sessionIDHeader, "session-123")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	sender, _ := runStreamableSender(t, ts, []string{
+		`{"id": 1, "method": "initialize"}`,
+		`{"id": 2, "method": "tools/list"}`,
+	})
+
+	if got := sender.SessionID(); got != "session-123" {
+		t.Errorf("Expected session id %q, got %q", "session-123", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(receivedSessions) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(receivedSessions))
+	}
+	if receivedSessions[0] != "" {
+		t.Errorf("First request should have no session header, got %q", receivedSessions[0])
+	}
+	if receivedSessions[1] != "session-123" {
+		t.Errorf("Second request should echo the session, got %q", receivedSessions[1])
+	}
+}
+
+func TestDetectTransport(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		expected TransportMode
+	}{
+		{name: "streamable server accepts", status: http.StatusAccepted, expected: TransportStreamableHTTP},
+		{name: "legacy server 404", status: http.StatusNotFound, expected: TransportSSE},
+		{name: "legacy server 405", status: http.StatusMethodNotAllowed, expected: TransportSSE},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				w.WriteHeader(tc.status)
+			}))
+			defer ts.Close()
+
+			logger := NewZapLogger(zap.NewNop().Sugar())
+			got := DetectTransport(context.Background(), &http.Client{}, ts.URL, "/mcp", logger)
+			if got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDetectTransport_Unreachable(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	got := DetectTransport(context.Background(), &http.Client{Timeout: 500 * time.Millisecond}, "http://localhost:1", "/mcp", logger)
+	if got != TransportSSE {
+		t.Errorf("Expected fallback to SSE for unreachable server, got %s", got)
+	}
+}
@@ -0,0 +1,195 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Endpoint schemes for the raw TCP transport. tcp:// dials in the clear;
+// tcps:// wraps the connection in TLS, honoring Config.TLS for the client
+// configuration.
+const (
+	tcpScheme  = "tcp://"
+	tcpsScheme = "tcps://"
+)
+
+// Reconnection backoff bounds for the TCP bridge, matching the SSE
+// worker's.
+const (
+	tcpInitialReconnectDelay = 1 * time.Second
+	tcpMaxReconnectDelay     = 30 * time.Second
+)
+
+// parseTCPEndpoint splits a tcp:// or tcps:// endpoint into the host:port
+// to dial and whether TLS applies.
+func parseTCPEndpoint(endpoint string) (addr string, useTLS bool, err error) {
+	switch {
+	case strings.HasPrefix(endpoint, tcpsScheme):
+		addr = strings.TrimPrefix(endpoint, tcpsScheme)
+		useTLS = true
+	case strings.HasPrefix(endpoint, tcpScheme):
+		addr = strings.TrimPrefix(endpoint, tcpScheme)
+	default:
+		return "", false, fmt.Errorf("tcp: endpoint %q must start with %s or %s", endpoint, tcpScheme, tcpsScheme)
+	}
+	host, _, splitErr := net.SplitHostPort(addr)
+	if splitErr != nil || host == "" {
+		return "", false, fmt.Errorf("tcp: endpoint %q must name a host:port like %slocalhost:9000", endpoint, tcpScheme)
+	}
+	return addr, useTLS, nil
+}
+
+// TCPBridge speaks newline-delimited JSON-RPC over a plain TCP (or TLS)
+// connection, for LAN setups and embedded devices that don't carry HTTP.
+// A lost connection is re-dialed with exponential backoff; outgoing
+// messages simply buffer on their channel while the link is down.
+type TCPBridge struct {
+	addr           string
+	useTLS         bool
+	tlsCfg         *TLSClientConfig
+	inputChan      chan string // Messages written to the connection.
+	outputChan     chan string // Messages read from the connection.
+	maxMessageSize int
+	logger         Logger
+}
+
+// NewTCPBridge constructs a TCPBridge for the given host:port.
+func NewTCPBridge(addr string, useTLS bool, tlsCfg *TLSClientConfig, inputChan, outputChan chan string, logger Logger) *TCPBridge {
+	return &TCPBridge{
+		addr:       addr,
+		useTLS:     useTLS,
+		tlsCfg:     tlsCfg,
+		inputChan:  inputChan,
+		outputChan: outputChan,
+		logger:     logger,
+	}
+}
+
+// SetMaxMessageSize caps how large a single line from the connection may
+// be. Zero keeps the default (defaultMaxMessageSize). Must be called
+// before Run.
+func (tb *TCPBridge) SetMaxMessageSize(n int) {
+	tb.maxMessageSize = n
+}
+
+// dial establishes one connection, with TLS when configured.
+func (tb *TCPBridge) dial(ctx context.Context) (net.Conn, error) {
+	var dialer net.Dialer
+	if !tb.useTLS {
+		return dialer.DialContext(ctx, "tcp", tb.addr)
+	}
+	var tlsConfig *tls.Config
+	if tb.tlsCfg != nil {
+		built, err := tb.tlsCfg.build()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig = built
+	}
+	tlsDialer := &tls.Dialer{NetDialer: &dialer, Config: tlsConfig}
+	return tlsDialer.DialContext(ctx, "tcp", tb.addr)
+}
+
+// Run dials the server and pumps messages in both directions until the
+// context is cancelled or the input channel closes, re-dialing with
+// backoff when the connection drops. The first dial failing is terminal,
+// so a bad address surfaces immediately instead of retrying forever.
+func (tb *TCPBridge) Run(ctx context.Context, cancel context.CancelFunc) error {
+	backoff := tcpInitialReconnectDelay
+	attempt := 0
+	for {
+		if attempt > 0 {
+			tb.logger.Warnw("TCP connection lost; reconnecting", "attempt", attempt, "delay", backoff)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, tcpMaxReconnectDelay)
+		}
+
+		conn, err := tb.dial(ctx)
+		if err != nil {
+			if attempt == 0 {
+				cancel()
+				return fmt.Errorf("tcp: failed to dial %s: %w", tb.addr, err)
+			}
+			tb.logger.Errorw("Failed to re-dial", "addr", tb.addr, "err", err)
+			attempt++
+			continue
+		}
+		attempt++
+		backoff = tcpInitialReconnectDelay
+		tb.logger.Infow("Connected over TCP", "addr", tb.addr, "tls", tb.useTLS)
+
+		done, err := tb.pump(ctx, cancel, conn)
+		conn.Close()
+		if done {
+			return err
+		}
+	}
+}
+
+// pump moves messages over one connection until it breaks or the bridge
+// must stop. It reports whether Run should return (with the given error)
+// rather than reconnect.
+func (tb *TCPBridge) pump(ctx context.Context, cancel context.CancelFunc, conn net.Conn) (bool, error) {
+	maxSize := tb.maxMessageSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxMessageSize
+	}
+	initialSize := bufio.MaxScanTokenSize
+	if maxSize < initialSize {
+		initialSize = maxSize
+	}
+
+	readErrChan := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(conn)
+		scanner.Buffer(make([]byte, 0, initialSize), maxSize)
+		for scanner.Scan() {
+			line := scanner.Text()
+			tb.logger.Debugw("Read from connection", "line", line)
+			select {
+			case tb.outputChan <- line:
+			case <-ctx.Done():
+				readErrChan <- ctx.Err()
+				return
+			}
+		}
+		readErrChan <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err := <-readErrChan:
+			if ctx.Err() != nil {
+				return true, ctx.Err()
+			}
+			tb.logger.Warnw("Connection closed by peer", "err", err)
+			return false, nil
+		case msg, ok := <-tb.inputChan:
+			if !ok {
+				tb.logger.Info("Client input closed; closing connection")
+				cancel()
+				return true, nil
+			}
+			if _, err := conn.Write([]byte(msg + "\n")); err != nil {
+				if ctx.Err() != nil {
+					return true, ctx.Err()
+				}
+				tb.logger.Warnw("Failed to write to connection", "err", err)
+				// The message is lost with the connection; reconnect and
+				// carry on with the next one, like the SSE transport does.
+				return false, nil
+			}
+		}
+	}
+}
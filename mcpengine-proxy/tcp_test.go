@@ -0,0 +1,240 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"go.uber.org/zap"
+)
+
+// ===== TCP Transport Tests =====
+
+func TestParseTCPEndpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		wantAddr string
+		wantTLS  bool
+		wantErr  bool
+	}{
+		{name: "Plain", endpoint: "tcp://localhost:9000", wantAddr: "localhost:9000"},
+		{name: "TLS", endpoint: "tcps://mcp.internal:9000", wantAddr: "mcp.internal:9000", wantTLS: true},
+		{name: "WrongScheme", endpoint: "http://localhost:9000", wantErr: true},
+		{name: "MissingPort", endpoint: "tcp://localhost", wantErr: true},
+		{name: "MissingHost", endpoint: "tcp://:9000", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			addr, useTLS, err := parseTCPEndpoint(test.endpoint)
+			if test.wantErr {
+				if err == nil {
+					t.Error("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if addr != test.wantAddr || useTLS != test.wantTLS {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", test.wantAddr, test.wantTLS, addr, useTLS)
+			}
+		})
+	}
+}
+
+// serveTCPEcho listens on a free port and echoes lines back on every
+// connection, standing in for an NDJSON server on the LAN.
+func serveTCPEcho(t *testing.T, tlsConfig *tls.Config) (string, net.Listener) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					fmt.Fprintf(conn, "%s\n", scanner.Text())
+				}
+			}(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return listener.Addr().String(), listener
+}
+
+func TestTCPBridge_Echo(t *testing.T) {
+	addr, _ := serveTCPEcho(t, nil)
+
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	bridge := NewTCPBridge(addr, false, nil, inputChan, outputChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bridge.Run(ctx, cancel)
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	inputChan <- request
+	select {
+	case got := <-outputChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back over TCP")
+	}
+}
+
+func TestTCPBridge_TLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeTestCertificate(t, certFile, keyFile, "mcpengine-test")
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to load certificate: %v", err)
+	}
+	addr, _ := serveTCPEcho(t, &tls.Config{Certificates: []tls.Certificate{cert}})
+
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	// The test certificate is self-signed, so verification is skipped.
+	bridge := NewTCPBridge(addr, true, &TLSClientConfig{InsecureSkipVerify: true},
+		inputChan, outputChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bridge.Run(ctx, cancel)
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	inputChan <- request
+	select {
+	case got := <-outputChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back over TLS")
+	}
+}
+
+func TestTCPBridge_Reconnects(t *testing.T) {
+	// A server that closes each connection after one echo, forcing the
+	// bridge to reconnect between messages.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			scanner := bufio.NewScanner(conn)
+			if scanner.Scan() {
+				fmt.Fprintf(conn, "%s\n", scanner.Text())
+			}
+			conn.Close()
+		}
+	}()
+
+	inputChan := make(chan string, 2)
+	outputChan := make(chan string, 2)
+	bridge := NewTCPBridge(listener.Addr().String(), false, nil, inputChan, outputChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bridge.Run(ctx, cancel)
+
+	for i := 1; i <= 2; i++ {
+		request := fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "method": "ping"}`, i)
+		inputChan <- request
+		select {
+		case got := <-outputChan:
+			if got != request {
+				t.Errorf("Expected message %d echoed, got %q", i, got)
+			}
+		case <-time.After(2*tcpInitialReconnectDelay + 2*time.Second):
+			t.Fatalf("Message %d never came back", i)
+		}
+	}
+}
+
+func TestTCPBridge_FirstDialFails(t *testing.T) {
+	inputChan := make(chan string)
+	outputChan := make(chan string)
+	// A port nothing listens on: the first dial failing must be terminal.
+	bridge := NewTCPBridge("127.0.0.1:1", false, nil, inputChan, outputChan, NewZapLogger(zap.NewNop().Sugar()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() { errChan <- bridge.Run(ctx, cancel) }()
+	select {
+	case err := <-errChan:
+		if err == nil {
+			t.Error("Expected a dial error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return on a failed first dial")
+	}
+}
+
+func TestTCPTransport_Engine(t *testing.T) {
+	addr, _ := serveTCPEcho(t, nil)
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	input := mcpenginetest.NewBlockReader(strings.NewReader(request + "\n"))
+	outputReader, outputWriter := io.Pipe()
+	engine, err := New(Config{
+		Endpoint: "tcp://" + addr,
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+		Input:    input,
+		Output:   outputWriter,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	lineChan := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		if scanner.Scan() {
+			lineChan <- scanner.Text()
+		}
+	}()
+	select {
+	case got := <-lineChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back through the engine")
+	}
+}
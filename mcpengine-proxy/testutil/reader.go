@@ -1,61 +1,32 @@
+// Package testutil previously hosted the engine's test doubles. They have
+// been promoted to the public mcpenginetest package; the aliases here remain
+// so existing imports keep working.
 package testutil
 
 import (
 	"io"
-	"os"
 	"testing"
+
+	"mcpengine/mcpenginetest"
 )
 
 // BlockReader reads from the underlying Reader until EOF,
-// then blocks forever on subsequent reads
-type BlockReader struct {
-	r          io.Reader
-	reachedEOF bool
-	blockChan  chan struct{}
-}
+// then blocks forever on subsequent reads.
+//
+// Deprecated: use mcpenginetest.BlockReader.
+type BlockReader = mcpenginetest.BlockReader
 
-// NewBlockReader creates a new reader that will block after EOF
+// NewBlockReader creates a new reader that will block after EOF.
+//
+// Deprecated: use mcpenginetest.NewBlockReader.
 func NewBlockReader(r io.Reader) *BlockReader {
-	return &BlockReader{
-		r:          r,
-		reachedEOF: false,
-		blockChan:  make(chan struct{}), // Unbuffered channel that's never closed
-	}
-}
-
-func (r *BlockReader) Read(p []byte) (n int, err error) {
-	if r.reachedEOF {
-		// Block forever by trying to receive from a channel that will never send
-		<-r.blockChan
-		return 0, nil // This line is never reached
-	}
-
-	n, err = r.r.Read(p)
-	if err == io.EOF {
-		r.reachedEOF = true
-		return n, nil // Return the data but suppress the EOF
-	}
-	return n, err
+	return mcpenginetest.NewBlockReader(r)
 }
 
+// CreateTempBlockReader writes content to a temp file and returns a
+// BlockReader over it.
+//
+// Deprecated: use mcpenginetest.CreateTempBlockReader.
 func CreateTempBlockReader(t *testing.T, content string) io.Reader {
-	t.Helper()
-	tmpFile, err := os.CreateTemp("", "mcpengine_input_*")
-	if err != nil {
-		t.Fatalf("Failed to create temporary file: %v", err)
-	}
-	t.Cleanup(func() {
-		err := os.Remove(tmpFile.Name())
-		if err != nil {
-			t.Errorf("Failed to remove temporary file: %v", err)
-		}
-	})
-
-	if _, err := tmpFile.WriteString(content); err != nil {
-		t.Fatalf("Failed to write to temporary file: %v", err)
-	}
-	if _, err := tmpFile.Seek(0, 0); err != nil {
-		t.Fatalf("Failed to reset temporary file: %v", err)
-	}
-	return NewBlockReader(tmpFile)
+	return mcpenginetest.CreateTempBlockReader(t, content)
 }
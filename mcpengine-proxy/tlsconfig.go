@@ -0,0 +1,154 @@
+package mcpengine
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+)
+
+// TLSClientConfig customizes TLS for all outbound connections: the SSE
+// stream, POSTs, and the OIDC/token exchange. The zero value (or a nil
+// pointer) keeps Go's defaults, which suit public servers; the options here
+// exist for private CAs, client certificates, and hardened deployments.
+type TLSClientConfig struct {
+	// CAFile is a PEM bundle of root CAs to trust in addition to the
+	// system roots, for servers behind a private or corporate CA.
+	CAFile string
+	// CertFile and KeyFile present a client certificate to servers that
+	// require mTLS. Both must be set together. The pair is reloaded on
+	// SIGHUP so rotated certificates are picked up without a restart.
+	CertFile string
+	KeyFile  string
+	// MinVersion is the minimum accepted TLS version, e.g.
+	// tls.VersionTLS13. Zero keeps Go's default.
+	MinVersion uint16
+	// InsecureSkipVerify disables server certificate verification. Only
+	// for development against self-signed servers; never in production.
+	InsecureSkipVerify bool
+}
+
+// validate checks for option combinations that can never work.
+func (c *TLSClientConfig) validate() error {
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return fmt.Errorf("tls config: CertFile and KeyFile must be set together")
+	}
+	return nil
+}
+
+// build materializes the options into a *tls.Config, loading any referenced
+// files.
+func (c *TLSClientConfig) build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         c.MinVersion,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.CertFile != "" {
+		reloader, err := newClientCertReloader(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = reloader.getClientCertificate
+	}
+	return tlsConfig, nil
+}
+
+// clientCertReloader serves the client certificate for mTLS handshakes and
+// picks up rotated certificate files without a restart: SIGHUP marks the
+// loaded pair stale, and the next handshake reloads it from disk. Gateways
+// that issue short-lived client certificates rotate them on the order of
+// hours, far shorter than a long-running proxy's lifetime.
+type clientCertReloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+
+	reloadPending atomic.Bool
+}
+
+// newClientCertReloader loads the certificate pair and registers the SIGHUP
+// handler that flags it for reload.
+func newClientCertReloader(certFile, keyFile string) (*clientCertReloader, error) {
+	r := &clientCertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	sighupChan := make(chan os.Signal, 1)
+	signal.Notify(sighupChan, syscall.SIGHUP)
+	go func() {
+		for range sighupChan {
+			r.reloadPending.Store(true)
+		}
+	}()
+	return r, nil
+}
+
+// reload re-reads the certificate pair from disk.
+func (r *clientCertReloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// getClientCertificate implements tls.Config.GetClientCertificate. When a
+// reload is pending it re-reads the pair first, keeping the previous
+// certificate if the new files don't load, so a botched rotation degrades
+// to the old credential instead of breaking every handshake.
+func (r *clientCertReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if r.reloadPending.Swap(false) {
+		if err := r.reload(); err != nil {
+			r.reloadPending.Store(true)
+		}
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// ParseTLSVersion maps a version string like "1.2" to the corresponding
+// tls.VersionTLS constant. An empty string means "use the default" and maps
+// to zero.
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "":
+		return 0, nil
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version %q (expected one of: 1.0, 1.1, 1.2, 1.3)", version)
+	}
+}
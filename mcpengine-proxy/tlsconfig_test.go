@@ -0,0 +1,298 @@
+package mcpengine
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// ===== TLS Config Tests =====
+
+func TestTLSClientConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cfg     TLSClientConfig
+		wantErr bool
+	}{
+		{
+			name: "zero value",
+			cfg:  TLSClientConfig{},
+		},
+		{
+			name: "cert and key together",
+			cfg:  TLSClientConfig{CertFile: "cert.pem", KeyFile: "key.pem"},
+		},
+		{
+			name:    "cert without key",
+			cfg:     TLSClientConfig{CertFile: "cert.pem"},
+			wantErr: true,
+		},
+		{
+			name:    "key without cert",
+			cfg:     TLSClientConfig{KeyFile: "key.pem"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.cfg.validate()
+			if tc.wantErr && err == nil {
+				t.Error("Expected an error, got none")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTLSClientConfig_Build(t *testing.T) {
+	cfg := TLSClientConfig{
+		MinVersion:         tls.VersionTLS13,
+		InsecureSkipVerify: true,
+	}
+	tlsConfig, err := cfg.build()
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	if tlsConfig.MinVersion != tls.VersionTLS13 {
+		t.Errorf("Expected MinVersion %d, got %d", tls.VersionTLS13, tlsConfig.MinVersion)
+	}
+	if !tlsConfig.InsecureSkipVerify {
+		t.Error("Expected InsecureSkipVerify to be set")
+	}
+}
+
+func TestTLSClientConfig_BuildErrors(t *testing.T) {
+	missing := TLSClientConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}
+	if _, err := missing.build(); err == nil {
+		t.Error("Expected an error for a missing CA bundle")
+	}
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+	invalid := TLSClientConfig{CAFile: notPEM}
+	if _, err := invalid.build(); err == nil {
+		t.Error("Expected an error for a CA bundle with no certificates")
+	}
+}
+
+func TestNewHTTPClient_InsecureSkipVerify(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	// Without trust for the test server's self-signed certificate, the
+	// request must fail.
+	strict, err := NewHTTPClient("", nil)
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+	if _, err := strict.Get(ts.URL); err == nil {
+		t.Error("Expected certificate verification to fail against a self-signed server")
+	}
+
+	insecure, err := NewHTTPClient("", &TLSClientConfig{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+	resp, err := insecure.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Expected the request to succeed with InsecureSkipVerify, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected uint16
+		wantErr  bool
+	}{
+		{input: "", expected: 0},
+		{input: "1.0", expected: tls.VersionTLS10},
+		{input: "1.1", expected: tls.VersionTLS11},
+		{input: "1.2", expected: tls.VersionTLS12},
+		{input: "1.3", expected: tls.VersionTLS13},
+		{input: "2.0", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		got, err := ParseTLSVersion(tc.input)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseTLSVersion(%q): expected an error, got none", tc.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTLSVersion(%q): unexpected error: %v", tc.input, err)
+		}
+		if got != tc.expected {
+			t.Errorf("ParseTLSVersion(%q): expected %d, got %d", tc.input, tc.expected, got)
+		}
+	}
+}
+
+// ===== Client Certificate Tests =====
+
+// writeTestCertificate generates a self-signed certificate for the given
+// organization and writes the PEM pair to certFile and keyFile.
+func writeTestCertificate(t *testing.T, certFile, keyFile, organization string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{Organization: []string{organization}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write cert file: %v", err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+}
+
+// certOrganization extracts the organization of the leaf certificate.
+func certOrganization(t *testing.T, cert *tls.Certificate) string {
+	t.Helper()
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	return leaf.Subject.Organization[0]
+}
+
+func TestNewHTTPClient_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	writeTestCertificate(t, certFile, keyFile, "mcpengine-test")
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	// Without a client certificate the handshake must be rejected.
+	bare, err := NewHTTPClient("", &TLSClientConfig{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+	if _, err := bare.Get(ts.URL); err == nil {
+		t.Error("Expected the handshake to fail without a client certificate")
+	}
+
+	client, err := NewHTTPClient("", &TLSClientConfig{
+		CertFile:           certFile,
+		KeyFile:            keyFile,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		t.Fatalf("NewHTTPClient failed: %v", err)
+	}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("Expected the mTLS request to succeed, got %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClientCertReloader_RotatesOnSIGHUP(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	writeTestCertificate(t, certFile, keyFile, "before-rotation")
+
+	reloader, err := newClientCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+	cert, err := reloader.getClientCertificate(nil)
+	if err != nil {
+		t.Fatalf("getClientCertificate failed: %v", err)
+	}
+	if got := certOrganization(t, cert); got != "before-rotation" {
+		t.Errorf("Expected organization %q, got %q", "before-rotation", got)
+	}
+
+	// Rotate the files and signal ourselves; the next handshake should
+	// serve the new pair.
+	writeTestCertificate(t, certFile, keyFile, "after-rotation")
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatalf("Failed to send SIGHUP: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		cert, err = reloader.getClientCertificate(nil)
+		if err != nil {
+			t.Fatalf("getClientCertificate failed: %v", err)
+		}
+		if certOrganization(t, cert) == "after-rotation" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Certificate was not rotated after SIGHUP")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestClientCertReloader_KeepsOldCertOnBadRotation(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	writeTestCertificate(t, certFile, keyFile, "good-cert")
+
+	reloader, err := newClientCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("Failed to create reloader: %v", err)
+	}
+
+	// Corrupt the files and force a reload; the previous pair must keep
+	// being served.
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("Failed to corrupt cert file: %v", err)
+	}
+	reloader.reloadPending.Store(true)
+	cert, err := reloader.getClientCertificate(nil)
+	if err != nil {
+		t.Fatalf("getClientCertificate failed: %v", err)
+	}
+	if got := certOrganization(t, cert); got != "good-cert" {
+		t.Errorf("Expected the previous certificate to be kept, got organization %q", got)
+	}
+}
@@ -0,0 +1,79 @@
+package mcpengine
+
+import (
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenKey identifies the credential a cached token belongs to. Tokens from
+// different issuers (or minted for different audiences by the same issuer)
+// are not interchangeable, so both parts participate in the key.
+type TokenKey struct {
+	Issuer   string
+	Audience string
+}
+
+// TokenCache stores OAuth2 tokens keyed by issuer and audience, so an
+// engine talking to several upstreams — or a server federating several
+// issuers — selects the right credential per request instead of sharing one
+// global token. A single cache may be shared by multiple AuthManagers via
+// AuthConfig.TokenCache.
+type TokenCache struct {
+	mu     sync.RWMutex
+	tokens map[TokenKey]*oauth2.Token
+}
+
+// NewTokenCache constructs an empty TokenCache.
+func NewTokenCache() *TokenCache {
+	return &TokenCache{tokens: make(map[TokenKey]*oauth2.Token)}
+}
+
+// Get returns the cached token for key, or nil if none is cached.
+func (c *TokenCache) Get(key TokenKey) *oauth2.Token {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tokens[key]
+}
+
+// Put caches token under key. A nil token removes the entry.
+func (c *TokenCache) Put(key TokenKey, token *oauth2.Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if token == nil {
+		delete(c.tokens, key)
+		return
+	}
+	c.tokens[key] = token
+}
+
+// Take removes and returns the cached token for key, or nil if none is
+// cached. The removal is atomic with the lookup, for revocation.
+func (c *TokenCache) Take(key TokenKey) *oauth2.Token {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	token := c.tokens[key]
+	delete(c.tokens, key)
+	return token
+}
+
+// Rekey moves the token cached under from to to, used when a token cached
+// before its issuer was known (e.g. loaded from a store at startup) is
+// attributed once discovery names the issuer. It never overwrites a token
+// already cached under to.
+func (c *TokenCache) Rekey(from, to TokenKey) {
+	if from == to {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	token, ok := c.tokens[from]
+	if !ok {
+		return
+	}
+	if _, taken := c.tokens[to]; taken {
+		return
+	}
+	delete(c.tokens, from)
+	c.tokens[to] = token
+}
@@ -0,0 +1,94 @@
+package mcpengine
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// ===== Token Cache Tests =====
+
+func TestTokenCache_PutGetTake(t *testing.T) {
+	cache := NewTokenCache()
+	key := TokenKey{Issuer: "https://auth.example.com"}
+
+	if token := cache.Get(key); token != nil {
+		t.Errorf("Expected nil for an empty cache, got %+v", token)
+	}
+
+	cache.Put(key, &oauth2.Token{AccessToken: "token-1"})
+	if token := cache.Get(key); token == nil || token.AccessToken != "token-1" {
+		t.Errorf("Expected token-1, got %+v", token)
+	}
+
+	// A different audience under the same issuer is a different credential.
+	other := TokenKey{Issuer: "https://auth.example.com", Audience: "https://api.example.com"}
+	if token := cache.Get(other); token != nil {
+		t.Errorf("Expected nil for a different audience, got %+v", token)
+	}
+
+	// A nil Put removes the entry.
+	cache.Put(key, nil)
+	if token := cache.Get(key); token != nil {
+		t.Errorf("Expected nil after removal, got %+v", token)
+	}
+
+	cache.Put(key, &oauth2.Token{AccessToken: "token-2"})
+	if token := cache.Take(key); token == nil || token.AccessToken != "token-2" {
+		t.Errorf("Expected token-2 from Take, got %+v", token)
+	}
+	if token := cache.Get(key); token != nil {
+		t.Errorf("Expected Take to remove the entry, got %+v", token)
+	}
+}
+
+func TestTokenCache_Rekey(t *testing.T) {
+	cache := NewTokenCache()
+	from := TokenKey{}
+	to := TokenKey{Issuer: "https://auth.example.com"}
+
+	cache.Put(from, &oauth2.Token{AccessToken: "startup-token"})
+	cache.Rekey(from, to)
+	if token := cache.Get(to); token == nil || token.AccessToken != "startup-token" {
+		t.Errorf("Expected the token under the new key, got %+v", token)
+	}
+	if token := cache.Get(from); token != nil {
+		t.Errorf("Expected the old key to be empty, got %+v", token)
+	}
+
+	// Rekey never overwrites a token already cached under the target key.
+	cache.Put(from, &oauth2.Token{AccessToken: "stale-token"})
+	cache.Rekey(from, to)
+	if token := cache.Get(to); token == nil || token.AccessToken != "startup-token" {
+		t.Errorf("Expected the existing token to survive, got %+v", token)
+	}
+
+	// Rekeying onto itself is a no-op.
+	cache.Rekey(to, to)
+	if token := cache.Get(to); token == nil || token.AccessToken != "startup-token" {
+		t.Errorf("Expected the token to remain, got %+v", token)
+	}
+}
+
+// TestTokenCache_SharedAcrossManagers verifies that managers for different
+// issuers sharing one cache each select their own credential.
+func TestTokenCache_SharedAcrossManagers(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	cache := NewTokenCache()
+
+	authA := NewAuthManager(&AuthConfig{TokenCache: cache}, logger)
+	authA.oidcConfig.Issuer = "https://issuer-a.example.com"
+	authB := NewAuthManager(&AuthConfig{TokenCache: cache}, logger)
+	authB.oidcConfig.Issuer = "https://issuer-b.example.com"
+
+	authA.setToken(&oauth2.Token{AccessToken: "token-a"})
+	authB.setToken(&oauth2.Token{AccessToken: "token-b"})
+
+	if token := authA.GetAccessToken(); token != "token-a" {
+		t.Errorf("Expected token-a, got %q", token)
+	}
+	if token := authB.GetAccessToken(); token != "token-b" {
+		t.Errorf("Expected token-b, got %q", token)
+	}
+}
@@ -0,0 +1,72 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TokenProvider is the minimal surface an embedder implements to plug its
+// own token source (SAML bridges, an internal STS, a vault sidecar) into
+// the engine. Unlike Authenticator, it needs no knowledge of HTTP
+// challenges or header injection: GetToken produces the current token and
+// Invalidate marks it stale after the server rejects it. Wrap one with
+// NewTokenProviderAuthenticator and hand it to Config.Authenticator.
+type TokenProvider interface {
+	// GetToken returns a token to authenticate the next request, fetching
+	// or renewing one as needed.
+	GetToken(ctx context.Context) (string, error)
+	// Invalidate discards any cached token so the next GetToken call
+	// acquires a fresh one.
+	Invalidate()
+}
+
+// TokenProviderAuthenticator adapts a TokenProvider to the engine's
+// Authenticator interface, mapping the senders' 401 handling onto
+// Invalidate-then-GetToken.
+type TokenProviderAuthenticator struct {
+	provider TokenProvider
+	logger   Logger
+}
+
+// NewTokenProviderAuthenticator wraps provider for use as
+// Config.Authenticator.
+func NewTokenProviderAuthenticator(provider TokenProvider, logger Logger) *TokenProviderAuthenticator {
+	return &TokenProviderAuthenticator{
+		provider: provider,
+		logger:   logger,
+	}
+}
+
+// HandleChallenge implements Authenticator. The rejected token is
+// invalidated and a fresh one fetched; there is never a URL for the user to
+// visit.
+func (t *TokenProviderAuthenticator) HandleChallenge(ctx context.Context, resp *http.Response) (string, func(), error) {
+	t.logger.Debugw("Server rejected the provided token; invalidating", "status", resp.StatusCode)
+	if err := t.Refresh(ctx); err != nil {
+		return "", nil, err
+	}
+	return "", func() {}, nil
+}
+
+// Token implements Authenticator. Provider failures are logged and yield an
+// empty token so the request proceeds unauthenticated and surfaces the
+// server's error.
+func (t *TokenProviderAuthenticator) Token() string {
+	token, err := t.provider.GetToken(context.Background())
+	if err != nil {
+		t.logger.Warnf("Token provider failed: %v", err)
+		return ""
+	}
+	return token
+}
+
+// Refresh implements Authenticator by invalidating the cached token and
+// fetching a replacement.
+func (t *TokenProviderAuthenticator) Refresh(ctx context.Context) error {
+	t.provider.Invalidate()
+	if _, err := t.provider.GetToken(ctx); err != nil {
+		return fmt.Errorf("token provider could not renew the token: %w", err)
+	}
+	return nil
+}
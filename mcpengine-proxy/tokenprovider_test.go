@@ -0,0 +1,126 @@
+package mcpengine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ===== Token Provider Tests =====
+
+// countingProvider is a TokenProvider that mints a new token whenever its
+// cache is empty.
+type countingProvider struct {
+	mu     sync.Mutex
+	token  string
+	minted int
+	err    error
+}
+
+func (p *countingProvider) GetToken(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return "", p.err
+	}
+	if p.token == "" {
+		p.minted++
+		p.token = fmt.Sprintf("provider-token-%d", p.minted)
+	}
+	return p.token, nil
+}
+
+func (p *countingProvider) Invalidate() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token = ""
+}
+
+func TestTokenProviderAuthenticator_Token(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	provider := &countingProvider{}
+	auth := NewTokenProviderAuthenticator(provider, logger)
+
+	if token := auth.Token(); token != "provider-token-1" {
+		t.Errorf("Expected provider-token-1, got %q", token)
+	}
+	// The provider caches, so repeated calls don't mint new tokens.
+	if token := auth.Token(); token != "provider-token-1" {
+		t.Errorf("Expected the cached token, got %q", token)
+	}
+
+	// A 401 invalidates the cache and fetches a replacement.
+	authURL, wait, err := auth.HandleChallenge(context.Background(), &http.Response{
+		StatusCode: http.StatusUnauthorized,
+	})
+	if err != nil {
+		t.Fatalf("HandleChallenge failed: %v", err)
+	}
+	if authURL != "" {
+		t.Errorf("Expected no auth URL, got %q", authURL)
+	}
+	wait()
+	if token := auth.Token(); token != "provider-token-2" {
+		t.Errorf("Expected a fresh token, got %q", token)
+	}
+}
+
+func TestTokenProviderAuthenticator_Errors(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	provider := &countingProvider{err: fmt.Errorf("sts unavailable")}
+	auth := NewTokenProviderAuthenticator(provider, logger)
+
+	// Failures degrade to an empty token so the server's error surfaces.
+	if token := auth.Token(); token != "" {
+		t.Errorf("Expected an empty token, got %q", token)
+	}
+	if err := auth.Refresh(context.Background()); err == nil {
+		t.Error("Expected Refresh to report the provider failure")
+	}
+	if _, _, err := auth.HandleChallenge(context.Background(), &http.Response{
+		StatusCode: http.StatusUnauthorized,
+	}); err == nil {
+		t.Error("Expected HandleChallenge to report the provider failure")
+	}
+}
+
+// TestTokenProviderAuthenticator_AttachedBySender verifies the adapter
+// plugs into the POST path like any other Authenticator.
+func TestTokenProviderAuthenticator_AttachedBySender(t *testing.T) {
+	authHeaders := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	endpointChan := make(chan string, 1)
+	inputChan := make(chan string, 1)
+	outputChan := make(chan string, 1)
+	endpointChan <- "/mcp"
+	inputChan <- `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	close(inputChan)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	auth := NewTokenProviderAuthenticator(&countingProvider{}, logger)
+	sender := NewHTTPPostSender(&http.Client{Timeout: 2 * time.Second}, ts.URL, endpointChan, inputChan, outputChan, auth, logger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go sender.Run(ctx, cancel)
+
+	select {
+	case header := <-authHeaders:
+		if header != "Bearer provider-token-1" {
+			t.Errorf("Expected the provider's token on the wire, got %q", header)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Server did not receive the request")
+	}
+}
@@ -0,0 +1,82 @@
+package mcpengine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/oauth2"
+)
+
+// TokenStore persists OAuth2 tokens across proxy restarts so users don't
+// have to re-authenticate every time the client relaunches the proxy.
+// Load returns (nil, nil) when no token has been stored yet.
+type TokenStore interface {
+	Load() (*oauth2.Token, error)
+	Save(token *oauth2.Token) error
+	Delete() error
+}
+
+// keychainService namespaces our entries in the OS credential store.
+const keychainService = "mcpengine"
+
+// KeychainTokenStore stores tokens in the operating system's credential
+// store: Keychain on macOS, Credential Manager on Windows, and the Secret
+// Service (libsecret) on Linux. Tokens are serialized as JSON under a
+// per-account entry.
+type KeychainTokenStore struct {
+	service string
+	account string
+}
+
+// NewKeychainTokenStore creates a store keyed by account, typically the
+// upstream server's host, so different MCP servers don't clobber each
+// other's credentials.
+func NewKeychainTokenStore(account string) *KeychainTokenStore {
+	return &KeychainTokenStore{
+		service: keychainService,
+		account: account,
+	}
+}
+
+// Load retrieves the stored token, or (nil, nil) if none exists.
+func (s *KeychainTokenStore) Load() (*oauth2.Token, error) {
+	data, err := keyring.Get(s.service, s.account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token from keychain: %w", err)
+	}
+	var token oauth2.Token
+	if err := json.Unmarshal([]byte(data), &token); err != nil {
+		return nil, fmt.Errorf("failed to parse stored token: %w", err)
+	}
+	return &token, nil
+}
+
+// Save writes the token to the credential store.
+func (s *KeychainTokenStore) Save(token *oauth2.Token) error {
+	data, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("failed to serialize token: %w", err)
+	}
+	if err := keyring.Set(s.service, s.account, string(data)); err != nil {
+		return fmt.Errorf("failed to write token to keychain: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the stored token. Deleting a token that does not exist is
+// not an error.
+func (s *KeychainTokenStore) Delete() error {
+	err := keyring.Delete(s.service, s.account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete token from keychain: %w", err)
+	}
+	return nil
+}
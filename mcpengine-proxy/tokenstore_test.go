@@ -0,0 +1,142 @@
+package mcpengine
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+// ===== Token Store Tests =====
+
+// memoryTokenStore is an in-memory TokenStore for exercising AuthManager's
+// persistence hooks without touching the OS credential store.
+type memoryTokenStore struct {
+	token   *oauth2.Token
+	saveErr error
+	saves   int
+}
+
+func (m *memoryTokenStore) Load() (*oauth2.Token, error) { return m.token, nil }
+
+func (m *memoryTokenStore) Save(token *oauth2.Token) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.token = token
+	m.saves++
+	return nil
+}
+
+func (m *memoryTokenStore) Delete() error {
+	m.token = nil
+	return nil
+}
+
+func TestKeychainTokenStore_RoundTrip(t *testing.T) {
+	keyring.MockInit()
+	store := NewKeychainTokenStore("server.example.com")
+
+	// Loading before anything was saved reports no token, not an error.
+	token, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if token != nil {
+		t.Fatalf("Expected no stored token, got %+v", token)
+	}
+
+	saved := &oauth2.Token{
+		AccessToken:  "access-token",
+		RefreshToken: "refresh-token",
+		Expiry:       time.Now().Add(time.Hour).Truncate(time.Second),
+	}
+	if err := store.Save(saved); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load after Save failed: %v", err)
+	}
+	if loaded == nil {
+		t.Fatal("Expected a stored token after Save")
+	}
+	if loaded.AccessToken != saved.AccessToken || loaded.RefreshToken != saved.RefreshToken {
+		t.Errorf("Loaded token %+v does not match saved token %+v", loaded, saved)
+	}
+
+	if err := store.Delete(); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	token, err = store.Load()
+	if err != nil {
+		t.Fatalf("Load after Delete failed: %v", err)
+	}
+	if token != nil {
+		t.Errorf("Expected no token after Delete, got %+v", token)
+	}
+
+	// Deleting again is a no-op, not an error.
+	if err := store.Delete(); err != nil {
+		t.Errorf("Second Delete failed: %v", err)
+	}
+}
+
+func TestKeychainTokenStore_SeparateAccounts(t *testing.T) {
+	keyring.MockInit()
+	storeA := NewKeychainTokenStore("a.example.com")
+	storeB := NewKeychainTokenStore("b.example.com")
+
+	if err := storeA.Save(&oauth2.Token{AccessToken: "token-a"}); err != nil {
+		t.Fatalf("Save to store A failed: %v", err)
+	}
+	token, err := storeB.Load()
+	if err != nil {
+		t.Fatalf("Load from store B failed: %v", err)
+	}
+	if token != nil {
+		t.Errorf("Store B should not see store A's token, got %+v", token)
+	}
+}
+
+func TestAuthManager_LoadsStoredToken(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	store := &memoryTokenStore{token: &oauth2.Token{AccessToken: "persisted-token"}}
+
+	auth := NewAuthManager(&AuthConfig{TokenStore: store}, logger)
+	if token := auth.GetAccessToken(); token != "persisted-token" {
+		t.Errorf("Expected token loaded from store, got %q", token)
+	}
+}
+
+func TestAuthManager_PersistsNewTokens(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	store := &memoryTokenStore{}
+
+	auth := NewAuthManager(&AuthConfig{TokenStore: store}, logger)
+	auth.setToken(&oauth2.Token{AccessToken: "fresh-token"})
+
+	if store.saves != 1 {
+		t.Errorf("Expected 1 save to the store, got %d", store.saves)
+	}
+	if store.token == nil || store.token.AccessToken != "fresh-token" {
+		t.Errorf("Store holds %+v, expected the fresh token", store.token)
+	}
+}
+
+func TestAuthManager_StoreFailureKeepsToken(t *testing.T) {
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	store := &memoryTokenStore{saveErr: fmt.Errorf("keychain locked")}
+
+	auth := NewAuthManager(&AuthConfig{TokenStore: store}, logger)
+	auth.setToken(&oauth2.Token{AccessToken: "unstored-token"})
+
+	// A failed save must not lose the in-memory token.
+	if token := auth.GetAccessToken(); token != "unstored-token" {
+		t.Errorf("Expected in-memory token despite store failure, got %q", token)
+	}
+}
@@ -0,0 +1,113 @@
+package mcpengine
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WireTracer dumps the raw traffic of a session — stdio lines, HTTP
+// requests and responses, and SSE stream bytes — to a writer, each entry
+// redacted and stamped with a sequence number and the offset since startup.
+// The resulting file makes protocol-mismatch bugs diagnosable from a user
+// report without reconstructing the session by hand.
+type WireTracer struct {
+	redactor *Redactor
+
+	mu    sync.Mutex
+	out   io.Writer
+	seq   int
+	start time.Time
+}
+
+// NewWireTracer traces to out, masking secrets through redactor.
+func NewWireTracer(out io.Writer, redactor *Redactor) *WireTracer {
+	return &WireTracer{
+		redactor: redactor,
+		out:      out,
+		start:    time.Now(),
+	}
+}
+
+// record writes one sequenced entry. Multi-line payloads (HTTP dumps) keep
+// their line breaks; the header line carries the metadata.
+func (wt *WireTracer) record(kind, payload string) {
+	payload = wt.redactor.Redact(payload)
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	wt.seq++
+	offset := time.Since(wt.start).Round(time.Microsecond)
+	fmt.Fprintf(wt.out, "%06d +%s [%s] %s\n", wt.seq, offset, kind, strings.TrimRight(payload, "\n"))
+}
+
+// traceClientLine is outbound middleware recording each stdio line from the
+// client as it heads to the transport.
+func (wt *WireTracer) traceClientLine(msg string) (string, error) {
+	wt.record("stdin", msg)
+	return msg, nil
+}
+
+// traceServerLine is inbound middleware recording each server message as it
+// arrives, before the tracker or any rewriting.
+func (wt *WireTracer) traceServerLine(msg string) (string, error) {
+	wt.record("server", msg)
+	return msg, nil
+}
+
+// roundTripper wraps base so every HTTP exchange lands in the trace:
+// requests with their bodies, responses with headers and bodies — except
+// SSE streams, whose bytes are traced chunk by chunk as they are read so
+// the dump never consumes or stalls the stream.
+func (wt *WireTracer) roundTripper(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &tracingTransport{tracer: wt, base: base}
+}
+
+type tracingTransport struct {
+	tracer *WireTracer
+	base   http.RoundTripper
+}
+
+func (tt *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if dump, err := httputil.DumpRequestOut(req, true); err == nil {
+		tt.tracer.record("http-request", string(dump))
+	}
+	resp, err := tt.base.RoundTrip(req)
+	if err != nil {
+		tt.tracer.record("http-error", err.Error())
+		return resp, err
+	}
+	streaming := strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream")
+	if dump, dumpErr := httputil.DumpResponse(resp, !streaming); dumpErr == nil {
+		tt.tracer.record("http-response", string(dump))
+	}
+	if streaming {
+		resp.Body = &tracingBody{tracer: tt.tracer, body: resp.Body}
+	}
+	return resp, nil
+}
+
+// tracingBody tees an SSE response body into the trace one read at a time,
+// preserving the exact bytes and framing that came off the wire.
+type tracingBody struct {
+	tracer *WireTracer
+	body   io.ReadCloser
+}
+
+func (tb *tracingBody) Read(p []byte) (int, error) {
+	n, err := tb.body.Read(p)
+	if n > 0 {
+		tb.tracer.record("sse", string(p[:n]))
+	}
+	return n, err
+}
+
+func (tb *tracingBody) Close() error {
+	return tb.body.Close()
+}
@@ -0,0 +1,123 @@
+package mcpengine
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ===== Wire Tracer Tests =====
+
+func TestWireTracer_SequencesAndRedacts(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewWireTracer(&buf, NewRedactor())
+
+	msg := `{"jsonrpc":"2.0","id":1,"params":{"access_token":"s3cret"}}`
+	got, err := tracer.traceClientLine(msg)
+	if err != nil || got != msg {
+		t.Fatalf("Middleware altered the message: %q, %v", got, err)
+	}
+	if got, err := tracer.traceServerLine(`{"jsonrpc":"2.0","id":1,"result":{}}`); err != nil || got == "" {
+		t.Fatalf("Middleware altered the message: %q, %v", got, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 trace entries, got %d: %q", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "000001 ") || !strings.Contains(lines[0], "[stdin]") {
+		t.Errorf("Unexpected first entry: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "000002 ") || !strings.Contains(lines[1], "[server]") {
+		t.Errorf("Unexpected second entry: %q", lines[1])
+	}
+	if strings.Contains(buf.String(), "s3cret") {
+		t.Error("Trace leaked the access token")
+	}
+	if !strings.Contains(lines[0], redactedPlaceholder) {
+		t.Errorf("Expected the token masked, got %q", lines[0])
+	}
+}
+
+func TestWireTracer_DumpsHTTPExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{}}`)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	tracer := NewWireTracer(&buf, NewRedactor())
+	client := &http.Client{Transport: tracer.roundTripper(nil)}
+
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"method":"ping"}`))
+	req.Header.Set("Authorization", "Bearer topsecret")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `"result"`) {
+		t.Errorf("Response body was consumed by the trace: %q", body)
+	}
+
+	trace := buf.String()
+	if !strings.Contains(trace, "[http-request]") || !strings.Contains(trace, `{"method":"ping"}`) {
+		t.Errorf("Request dump missing from trace: %q", trace)
+	}
+	if !strings.Contains(trace, "[http-response]") || !strings.Contains(trace, `"result"`) {
+		t.Errorf("Response dump missing from trace: %q", trace)
+	}
+	if strings.Contains(trace, "topsecret") {
+		t.Error("Trace leaked the bearer token")
+	}
+}
+
+func TestWireTracer_StreamsSSEWithoutConsuming(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: message\ndata: hello\n\n")
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	tracer := NewWireTracer(&buf, NewRedactor())
+	client := &http.Client{Transport: tracer.roundTripper(nil)}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "data: hello") {
+		t.Errorf("SSE body was consumed by the trace: %q", body)
+	}
+
+	trace := buf.String()
+	if !strings.Contains(trace, "[sse]") || !strings.Contains(trace, "data: hello") {
+		t.Errorf("SSE chunk missing from trace: %q", trace)
+	}
+	// The response dump must not have pre-read the stream.
+	if strings.Count(trace, "data: hello") != 1 {
+		t.Errorf("Stream bytes appear more than once in the trace: %q", trace)
+	}
+}
+
+func TestWireTracer_RecordsTransportErrors(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewWireTracer(&buf, NewRedactor())
+	client := &http.Client{Transport: tracer.roundTripper(nil)}
+
+	if _, err := client.Get("http://127.0.0.1:1/unreachable"); err == nil {
+		t.Fatal("Expected the request to fail")
+	}
+	if !strings.Contains(buf.String(), "[http-error]") {
+		t.Errorf("Expected a http-error entry, got %q", buf.String())
+	}
+}
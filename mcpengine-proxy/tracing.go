@@ -0,0 +1,215 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingServiceName is the default OpenTelemetry service.name.
+const tracingServiceName = "mcpengine"
+
+// TracingConfig enables OpenTelemetry tracing of the JSON-RPC traffic. Each
+// request becomes a client span named after its method, carrying the request
+// id, the outcome, and events for auth challenges, so operators running
+// fleets of proxies can tie LLM tool calls to backend latency in their
+// tracing backend.
+type TracingConfig struct {
+	// OTLPEndpoint is the OTLP/HTTP collector to export spans to, as
+	// host:port (e.g. "localhost:4318").
+	OTLPEndpoint string
+	// Insecure exports over plain HTTP instead of TLS, for collectors on
+	// localhost or inside the cluster.
+	Insecure bool
+	// ServiceName overrides the reported service.name. Defaults to
+	// "mcpengine".
+	ServiceName string
+	// TracerProvider, if set, is used instead of building an OTLP exporter,
+	// for embedders that already manage an OpenTelemetry pipeline (and for
+	// tests). OTLPEndpoint is ignored when set.
+	TracerProvider trace.TracerProvider
+}
+
+// requestTracer opens a span per outbound JSON-RPC request and closes it
+// when the matching response arrives, spliced into the pipeline as ordinary
+// middleware. Notifications carry no id and become zero-length spans.
+type requestTracer struct {
+	tracer   trace.Tracer
+	provider *sdktrace.TracerProvider // Owned; nil when one was injected.
+	logger   Logger
+
+	// spans maps the raw JSON id (its exact bytes) to the open span, the
+	// same keying the request tracker uses.
+	mu    sync.Mutex
+	spans map[string]trace.Span
+}
+
+// newRequestTracer builds the tracer, wiring an OTLP/HTTP exporter unless a
+// TracerProvider was injected.
+func newRequestTracer(cfg TracingConfig, logger Logger) (*requestTracer, error) {
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = tracingServiceName
+	}
+	provider := cfg.TracerProvider
+	var owned *sdktrace.TracerProvider
+	if provider == nil {
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("tracing: failed to build the OTLP exporter: %w", err)
+		}
+		res, err := resource.Merge(resource.Default(),
+			resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+		if err != nil {
+			return nil, fmt.Errorf("tracing: %w", err)
+		}
+		owned = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithResource(res),
+		)
+		provider = owned
+	}
+	return &requestTracer{
+		tracer:   provider.Tracer(tracingServiceName),
+		provider: owned,
+		logger:   logger,
+		spans:    make(map[string]trace.Span),
+	}, nil
+}
+
+// traceOutbound is the outbound middleware: it opens a span for the message
+// and remembers it by id until the response closes it. Messages always flow
+// through unchanged.
+func (t *requestTracer) traceOutbound(msg string) (string, error) {
+	var envelope struct {
+		Method string          `json:"method"`
+		ID     json.RawMessage `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(msg), &envelope); err != nil {
+		t.logger.Debugf("Failed to parse outbound message for tracing: %v", err)
+		return msg, nil
+	}
+	name := envelope.Method
+	if name == "" {
+		name = "request"
+	}
+	attrs := []attribute.KeyValue{
+		attribute.String("rpc.system", "jsonrpc"),
+		attribute.String("rpc.method", envelope.Method),
+	}
+	if len(envelope.ID) > 0 && string(envelope.ID) != "null" {
+		attrs = append(attrs, attribute.String("rpc.jsonrpc.request_id", string(envelope.ID)))
+	}
+	_, span := t.tracer.Start(context.Background(), name,
+		trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	if len(envelope.ID) == 0 || string(envelope.ID) == "null" {
+		// Notifications get no response; the span records that they were
+		// sent and nothing more.
+		span.End()
+		return msg, nil
+	}
+	t.mu.Lock()
+	t.spans[string(envelope.ID)] = span
+	t.mu.Unlock()
+	return msg, nil
+}
+
+// traceInbound is the inbound middleware: it closes the span the response's
+// id matches, recording the JSON-RPC error (and the HTTP status the proxy
+// embeds in synthesized delivery errors) when the request failed.
+func (t *requestTracer) traceInbound(msg string) (string, error) {
+	var envelope struct {
+		ID    json.RawMessage `json:"id"`
+		Error *JSONRPCError   `json:"error"`
+	}
+	if err := json.Unmarshal([]byte(msg), &envelope); err != nil {
+		t.logger.Debugf("Failed to parse inbound message for tracing: %v", err)
+		return msg, nil
+	}
+	if len(envelope.ID) == 0 || string(envelope.ID) == "null" {
+		return msg, nil
+	}
+	t.mu.Lock()
+	span, ok := t.spans[string(envelope.ID)]
+	delete(t.spans, string(envelope.ID))
+	t.mu.Unlock()
+	if !ok {
+		return msg, nil
+	}
+	if envelope.Error != nil {
+		span.SetAttributes(attribute.Int("rpc.jsonrpc.error_code", envelope.Error.Code))
+		var data struct {
+			Status int `json:"status"`
+		}
+		if len(envelope.Error.Data) > 0 && json.Unmarshal(envelope.Error.Data, &data) == nil && data.Status != 0 {
+			span.SetAttributes(attribute.Int("http.response.status_code", data.Status))
+		}
+		span.SetStatus(codes.Error, envelope.Error.Message)
+	} else {
+		span.SetStatus(codes.Ok, "")
+	}
+	span.End()
+	return msg, nil
+}
+
+// addEvent annotates every open span, for events that concern the session
+// rather than one request (auth challenges, reconnections).
+func (t *requestTracer) addEvent(name string, attrs ...attribute.KeyValue) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, span := range t.spans {
+		span.AddEvent(name, trace.WithAttributes(attrs...))
+	}
+}
+
+// instrumentHooks layers span events over the engine's lifecycle hooks so
+// auth challenges and endpoint changes show up on the requests they stalled.
+func (t *requestTracer) instrumentHooks(hooks LifecycleHooks) LifecycleHooks {
+	instrumented := hooks
+	instrumented.OnAuthRequired = func(url string) {
+		t.addEvent("auth.required", attribute.String("auth.url", url))
+		hooks.authRequired(url)
+	}
+	instrumented.OnAuthCompleted = func() {
+		t.addEvent("auth.completed")
+		hooks.authCompleted()
+	}
+	instrumented.OnEndpointReceived = func(path string) {
+		t.addEvent("endpoint.received")
+		hooks.endpointReceived(path)
+	}
+	return instrumented
+}
+
+// shutdown closes any spans still open (requests the server never answered)
+// and flushes the exporter, bounded so a slow collector can't hang exit.
+func (t *requestTracer) shutdown() {
+	t.mu.Lock()
+	for id, span := range t.spans {
+		delete(t.spans, id)
+		span.SetStatus(codes.Error, "no response before shutdown")
+		span.End()
+	}
+	t.mu.Unlock()
+	if t.provider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := t.provider.Shutdown(ctx); err != nil {
+			t.logger.Warnw("Failed to flush spans on shutdown", "err", err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package mcpengine
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mcpengine/mcpenginetest"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+)
+
+// ===== Tracing Tests =====
+
+// newTestTracer builds a requestTracer backed by an in-memory exporter.
+func newTestTracer(t *testing.T) (*requestTracer, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer, err := newRequestTracer(TracingConfig{TracerProvider: provider},
+		NewZapLogger(zap.NewNop().Sugar()))
+	if err != nil {
+		t.Fatalf("Failed to create tracer: %v", err)
+	}
+	return tracer, exporter
+}
+
+// spanAttr returns the named attribute's value as a string, or "".
+func spanAttr(span tracetest.SpanStub, key string) string {
+	for _, attr := range span.Attributes {
+		if string(attr.Key) == key {
+			return attr.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestRequestTracer_RequestResponseSpan(t *testing.T) {
+	tracer, exporter := newTestTracer(t)
+
+	if _, err := tracer.traceOutbound(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`); err != nil {
+		t.Fatalf("traceOutbound failed: %v", err)
+	}
+	if len(exporter.GetSpans()) != 0 {
+		t.Fatal("Expected the span to stay open until the response")
+	}
+	if _, err := tracer.traceInbound(`{"jsonrpc":"2.0","id":1,"result":{}}`); err != nil {
+		t.Fatalf("traceInbound failed: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "tools/call" {
+		t.Errorf("Expected the span to be named after the method, got %q", span.Name)
+	}
+	if span.Status.Code != codes.Ok {
+		t.Errorf("Expected Ok status, got %v", span.Status.Code)
+	}
+	if got := spanAttr(span, "rpc.jsonrpc.request_id"); got != "1" {
+		t.Errorf("Expected request id 1, got %q", got)
+	}
+}
+
+func TestRequestTracer_ErrorResponse(t *testing.T) {
+	tracer, exporter := newTestTracer(t)
+
+	tracer.traceOutbound(`{"jsonrpc":"2.0","id":"a","method":"tools/call"}`)
+	tracer.traceInbound(`{"jsonrpc":"2.0","id":"a","error":{"code":-32004,"message":"server returned an unexpected status","data":{"status":502}}}`)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Status.Code != codes.Error {
+		t.Errorf("Expected Error status, got %v", span.Status.Code)
+	}
+	if got := spanAttr(span, "rpc.jsonrpc.error_code"); got != "-32004" {
+		t.Errorf("Expected the JSON-RPC error code, got %q", got)
+	}
+	if got := spanAttr(span, "http.response.status_code"); got != "502" {
+		t.Errorf("Expected the embedded HTTP status, got %q", got)
+	}
+}
+
+func TestRequestTracer_Notification(t *testing.T) {
+	tracer, exporter := newTestTracer(t)
+
+	tracer.traceOutbound(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	// Notifications get no response, so their span ends immediately and
+	// nothing is left pending.
+	if len(exporter.GetSpans()) != 1 {
+		t.Fatalf("Expected the notification span to be ended, got %d spans", len(exporter.GetSpans()))
+	}
+	if len(tracer.spans) != 0 {
+		t.Errorf("Expected no open spans, got %d", len(tracer.spans))
+	}
+}
+
+func TestRequestTracer_AuthEvent(t *testing.T) {
+	tracer, exporter := newTestTracer(t)
+
+	tracer.traceOutbound(`{"jsonrpc":"2.0","id":7,"method":"tools/call"}`)
+	hooks := tracer.instrumentHooks(LifecycleHooks{})
+	hooks.authRequired("https://idp.example.com/authorize")
+	tracer.traceInbound(`{"jsonrpc":"2.0","id":7,"result":{}}`)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	found := false
+	for _, event := range spans[0].Events {
+		if event.Name == "auth.required" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected an auth.required event on the open span")
+	}
+}
+
+func TestRequestTracer_ShutdownEndsOpenSpans(t *testing.T) {
+	tracer, exporter := newTestTracer(t)
+
+	tracer.traceOutbound(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`)
+	tracer.shutdown()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected the unanswered span to be ended, got %d", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error {
+		t.Errorf("Expected Error status for an unanswered request, got %v", spans[0].Status.Code)
+	}
+}
+
+func TestTracingConfig_Validation(t *testing.T) {
+	cfg := Config{
+		Endpoint: "http://localhost:8000",
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+		Tracing:  &TracingConfig{},
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for tracing without an endpoint or provider")
+	}
+}
+
+func TestTracing_Engine(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer ts.Close()
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`
+	input := mcpenginetest.NewBlockReader(strings.NewReader(request + "\n"))
+	outputReader, outputWriter := io.Pipe()
+	engine, err := New(Config{
+		Endpoint: ts.URL,
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+		Input:    input,
+		Output:   outputWriter,
+		Tracing:  &TracingConfig{TracerProvider: provider},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.Start(ctx)
+
+	lineChan := make(chan string, 1)
+	go func() {
+		scanner := bufio.NewScanner(outputReader)
+		if scanner.Scan() {
+			lineChan <- scanner.Text()
+		}
+	}()
+	select {
+	case got := <-lineChan:
+		if got != request {
+			t.Errorf("Expected the echoed message, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No message came back through the engine")
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		if spans := exporter.GetSpans(); len(spans) == 1 {
+			if spans[0].Name != "ping" {
+				t.Errorf("Expected a ping span, got %q", spans[0].Name)
+			}
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatal("No span was exported for the request")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
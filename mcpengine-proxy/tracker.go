@@ -0,0 +1,246 @@
+package mcpengine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// requestTimeoutErrorCode is returned when the server never answers a
+// request within the configured timeout.
+const requestTimeoutErrorCode = -32002
+
+// defaultOrphanWarnAfter is how long a request may go unanswered before
+// the warn-only tracker logs it, when no explicit threshold is set.
+const defaultOrphanWarnAfter = 60 * time.Second
+
+// RequestTracker sits between the client and the transport, remembering the
+// id of every outbound request. If the server doesn't answer within the
+// configured timeout, the tracker emits a JSON-RPC error with the original
+// id so the client isn't left waiting forever. With a zero timeout the
+// tracker runs in warn-only mode: unanswered requests are logged after the
+// warn threshold but no errors are synthesized. Either way, responses whose
+// id matches no outstanding request are flagged instead of passing through
+// silently, and server-initiated requests (id plus method from the
+// transport) are correlated with the client's answers the same way.
+// Messages flow through unchanged in both directions; notifications carry
+// no id and are never tracked.
+type RequestTracker struct {
+	timeout   time.Duration
+	warnAfter time.Duration
+	clientIn  chan string // Requests from the client.
+	serverIn  chan string // Responses from the transport.
+	serverOut chan string // Requests forwarded to the transport.
+	clientOut chan string // Responses and timeout errors to the client.
+	logger    Logger
+
+	// pending maps the raw JSON id (its exact bytes) to when the request
+	// was sent, so string and numeric ids are tracked alike. serverPending
+	// does the same for requests the server sent to the client. Guarded by
+	// mu so Pending can be read from outside the Run goroutine.
+	mu            sync.Mutex
+	pending       map[string]time.Time
+	serverPending map[string]time.Time
+}
+
+// NewRequestTracker constructs a RequestTracker with the given per-request
+// timeout. A zero timeout selects warn-only mode.
+func NewRequestTracker(
+	timeout time.Duration,
+	clientIn, serverIn, serverOut, clientOut chan string,
+	logger Logger,
+) *RequestTracker {
+	warnAfter := time.Duration(0)
+	if timeout == 0 {
+		warnAfter = defaultOrphanWarnAfter
+	}
+	return &RequestTracker{
+		timeout:       timeout,
+		warnAfter:     warnAfter,
+		clientIn:      clientIn,
+		serverIn:      serverIn,
+		serverOut:     serverOut,
+		clientOut:     clientOut,
+		logger:        logger,
+		pending:       make(map[string]time.Time),
+		serverPending: make(map[string]time.Time),
+	}
+}
+
+// SetWarnAfter overrides how long a request may go unanswered before the
+// warn-only tracker logs it. It has no effect when a timeout is set, since
+// expiry already covers unanswered requests. Call before Run.
+func (rt *RequestTracker) SetWarnAfter(d time.Duration) {
+	if d > 0 {
+		rt.warnAfter = d
+	}
+}
+
+// Pending reports how many requests are currently awaiting a response.
+func (rt *RequestTracker) Pending() int {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return len(rt.pending)
+}
+
+// sweepInterval is how often the tracker checks for expired requests. In
+// warn-only mode it derives from the warn threshold instead of the timeout.
+func (rt *RequestTracker) sweepInterval() time.Duration {
+	base := rt.timeout
+	if base == 0 {
+		base = rt.warnAfter
+	}
+	interval := base / 4
+	if interval > time.Second {
+		interval = time.Second
+	}
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	return interval
+}
+
+// Run forwards traffic in both directions, emitting timeout errors for
+// requests the server never answers. When the client side closes, the
+// transport side is closed too; the tracker keeps delivering late responses
+// until the context is cancelled.
+func (rt *RequestTracker) Run(ctx context.Context, cancel context.CancelFunc) error {
+	rt.logger.Debug("Starting RequestTracker")
+	ticker := time.NewTicker(rt.sweepInterval())
+	defer ticker.Stop()
+
+	clientIn := rt.clientIn
+	for {
+		select {
+		case <-ctx.Done():
+			rt.logger.Info("RequestTracker canceled")
+			return ctx.Err()
+		case msg, ok := <-clientIn:
+			if !ok {
+				rt.logger.Info("Client input closed; closing transport input")
+				close(rt.serverOut)
+				clientIn = nil
+				continue
+			}
+			if id := getMessageID(msg, rt.logger); id != nil {
+				rt.mu.Lock()
+				if getMessageMethod(msg) != "" {
+					// A client request; expect a response from the server.
+					rt.pending[string(id)] = time.Now()
+				} else if _, ok := rt.serverPending[string(id)]; ok {
+					// The client answering a server-initiated request.
+					delete(rt.serverPending, string(id))
+				} else {
+					rt.logger.Warnw("Response to unknown server request", "id", string(id))
+				}
+				rt.mu.Unlock()
+			}
+			select {
+			case rt.serverOut <- msg:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case msg := <-rt.serverIn:
+			if id := getMessageID(msg, rt.logger); id != nil {
+				rt.mu.Lock()
+				if getMessageMethod(msg) != "" {
+					// A server-initiated request; expect an answer from the
+					// client.
+					rt.serverPending[string(id)] = time.Now()
+				} else if _, ok := rt.pending[string(id)]; ok {
+					delete(rt.pending, string(id))
+				} else {
+					rt.logger.Warnw("Unsolicited response with unknown id", "id", string(id))
+				}
+				rt.mu.Unlock()
+			}
+			select {
+			case rt.clientOut <- msg:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case <-ticker.C:
+			now := time.Now()
+			// Collect expired ids under the lock; the error responses are
+			// emitted afterwards since channel sends may block. In warn-only
+			// mode unanswered requests are logged and forgotten instead, so
+			// a late answer surfaces as an unsolicited response.
+			var expired []string
+			rt.mu.Lock()
+			if rt.timeout > 0 {
+				for id, sent := range rt.pending {
+					if now.Sub(sent) < rt.timeout {
+						continue
+					}
+					delete(rt.pending, id)
+					expired = append(expired, id)
+				}
+			} else {
+				for id, sent := range rt.pending {
+					if now.Sub(sent) < rt.warnAfter {
+						continue
+					}
+					delete(rt.pending, id)
+					rt.logger.Warnw("Request never answered", "id", id, "age", now.Sub(sent))
+				}
+			}
+			threshold := rt.warnAfter
+			if threshold == 0 {
+				threshold = rt.timeout
+			}
+			for id, sent := range rt.serverPending {
+				if now.Sub(sent) < threshold {
+					continue
+				}
+				delete(rt.serverPending, id)
+				rt.logger.Warnw("Server request never answered by client", "id", id, "age", now.Sub(sent))
+			}
+			rt.mu.Unlock()
+			for _, id := range expired {
+				rt.logger.Warnw("Request timed out", "id", id, "timeout", rt.timeout)
+				errResp := jsonRPCErrorString(json.RawMessage(id), requestTimeoutErrorCode,
+					fmt.Sprintf("no response from server within %v", rt.timeout), nil, rt.logger)
+				select {
+				case rt.clientOut <- errResp:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+// jsonRPCErrorString builds a serialized JSON-RPC error response for the
+// given request id. The optional data payload ends up in the error's data
+// field.
+func jsonRPCErrorString(id json.RawMessage, code int, message string, data interface{}, logger Logger) string {
+	rpcErr := &JSONRPCError{
+		Code:    code,
+		Message: message,
+	}
+	if data != nil {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			logger.Errorf("Failed to marshal error data: %v", err)
+		} else {
+			rpcErr.Data = raw
+		}
+	}
+	errResp := struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Error   *JSONRPCError   `json:"error"`
+	}{
+		JSONRPC: "2.0",
+		ID:      id,
+		Error:   rpcErr,
+	}
+	serialized, err := json.Marshal(errResp)
+	if err != nil {
+		logger.Errorf("Failed to marshal error response: %v", err)
+		return ""
+	}
+	return string(serialized)
+}
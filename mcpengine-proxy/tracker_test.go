@@ -0,0 +1,244 @@
+package mcpengine
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// ===== Request Tracker Tests =====
+
+func newTestTracker(t *testing.T, timeout time.Duration) (clientIn, serverIn, serverOut, clientOut chan string) {
+	t.Helper()
+	clientIn = make(chan string, 10)
+	serverIn = make(chan string, 10)
+	serverOut = make(chan string, 10)
+	clientOut = make(chan string, 10)
+
+	logger := NewZapLogger(zap.NewNop().Sugar())
+	tracker := NewRequestTracker(timeout, clientIn, serverIn, serverOut, clientOut, logger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go tracker.Run(ctx, cancel)
+	return clientIn, serverIn, serverOut, clientOut
+}
+
+func TestRequestTracker_ForwardsBothDirections(t *testing.T) {
+	clientIn, serverIn, serverOut, clientOut := newTestTracker(t, time.Minute)
+
+	request := `{"jsonrpc": "2.0", "id": 1, "method": "tools/list"}`
+	clientIn <- request
+	select {
+	case got := <-serverOut:
+		if got != request {
+			t.Errorf("Expected request %q forwarded, got %q", request, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Request was not forwarded to the transport")
+	}
+
+	response := `{"jsonrpc": "2.0", "id": 1, "result": {}}`
+	serverIn <- response
+	select {
+	case got := <-clientOut:
+		if got != response {
+			t.Errorf("Expected response %q forwarded, got %q", response, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Response was not forwarded to the client")
+	}
+}
+
+func TestRequestTracker_EmitsTimeoutError(t *testing.T) {
+	clientIn, _, serverOut, clientOut := newTestTracker(t, 100*time.Millisecond)
+
+	clientIn <- `{"jsonrpc": "2.0", "id": 7, "method": "tools/call"}`
+	<-serverOut // The request still goes out.
+
+	select {
+	case got := <-clientOut:
+		if !strings.Contains(got, `"id":7`) {
+			t.Errorf("Timeout error should carry the request id, got %q", got)
+		}
+		if !strings.Contains(got, "no response from server") {
+			t.Errorf("Expected a timeout error, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No timeout error was emitted")
+	}
+}
+
+func TestRequestTracker_TimelyResponseSuppressesError(t *testing.T) {
+	clientIn, serverIn, serverOut, clientOut := newTestTracker(t, 200*time.Millisecond)
+
+	clientIn <- `{"jsonrpc": "2.0", "id": 3, "method": "ping"}`
+	<-serverOut
+	serverIn <- `{"jsonrpc": "2.0", "id": 3, "result": {}}`
+	<-clientOut
+
+	// Wait past the timeout; no error should follow the real response.
+	time.Sleep(400 * time.Millisecond)
+	select {
+	case got := <-clientOut:
+		t.Errorf("Unexpected message after timely response: %q", got)
+	default:
+	}
+}
+
+func TestRequestTracker_IgnoresNotifications(t *testing.T) {
+	clientIn, _, serverOut, clientOut := newTestTracker(t, 100*time.Millisecond)
+
+	clientIn <- `{"jsonrpc": "2.0", "method": "notifications/initialized"}`
+	<-serverOut
+
+	// Notifications have no id and never time out.
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case got := <-clientOut:
+		t.Errorf("Unexpected message for a notification: %q", got)
+	default:
+	}
+}
+
+func TestRequestTracker_ClosesTransportOnClientEOF(t *testing.T) {
+	clientIn, _, serverOut, _ := newTestTracker(t, time.Minute)
+
+	close(clientIn)
+	select {
+	case _, ok := <-serverOut:
+		if ok {
+			t.Error("Expected transport input to be closed, got a message")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Transport input was not closed after client EOF")
+	}
+}
+
+// newObservedTracker is like newTestTracker but captures the tracker's log
+// output, for the orphan-detection tests that assert on warnings.
+func newObservedTracker(t *testing.T, timeout, warnAfter time.Duration) (clientIn, serverIn, serverOut, clientOut chan string, logs *observer.ObservedLogs) {
+	t.Helper()
+	clientIn = make(chan string, 10)
+	serverIn = make(chan string, 10)
+	serverOut = make(chan string, 10)
+	clientOut = make(chan string, 10)
+
+	core, observed := observer.New(zap.DebugLevel)
+	logger := NewZapLogger(zap.New(core).Sugar())
+	tracker := NewRequestTracker(timeout, clientIn, serverIn, serverOut, clientOut, logger)
+	tracker.SetWarnAfter(warnAfter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go tracker.Run(ctx, cancel)
+	return clientIn, serverIn, serverOut, clientOut, observed
+}
+
+func TestRequestTracker_FlagsUnsolicitedResponses(t *testing.T) {
+	_, serverIn, _, clientOut, logs := newObservedTracker(t, time.Minute, 0)
+
+	// A response whose id matches no outstanding request still passes
+	// through, but gets flagged.
+	serverIn <- `{"jsonrpc": "2.0", "id": 9, "result": {}}`
+	select {
+	case <-clientOut:
+	case <-time.After(time.Second):
+		t.Fatal("Unsolicited response was not forwarded")
+	}
+	if logs.FilterMessageSnippet("Unsolicited response").Len() != 1 {
+		t.Error("Expected a warning about the unknown response id")
+	}
+}
+
+func TestRequestTracker_CorrelatesServerRequests(t *testing.T) {
+	clientIn, serverIn, serverOut, clientOut, logs := newObservedTracker(t, 100*time.Millisecond, 0)
+
+	// A server-initiated request (id plus method) is not a response and
+	// must not be flagged; the client's answer closes it out without being
+	// tracked as a new client request.
+	serverIn <- `{"jsonrpc": "2.0", "id": 5, "method": "sampling/createMessage"}`
+	<-clientOut
+	clientIn <- `{"jsonrpc": "2.0", "id": 5, "result": {}}`
+	<-serverOut
+
+	// The answer must not be mistaken for a client request and timed out.
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case got := <-clientOut:
+		t.Errorf("Unexpected message after answering the server: %q", got)
+	default:
+	}
+	if logs.FilterMessageSnippet("unknown").Len() != 0 {
+		t.Error("Expected no correlation warnings for a matched exchange")
+	}
+}
+
+func TestRequestTracker_WarnOnlyMode(t *testing.T) {
+	// With a zero timeout the tracker warns about orphans instead of
+	// synthesizing errors.
+	clientIn, _, serverOut, clientOut, logs := newObservedTracker(t, 0, 50*time.Millisecond)
+
+	clientIn <- `{"jsonrpc": "2.0", "id": 4, "method": "tools/call"}`
+	<-serverOut
+
+	time.Sleep(300 * time.Millisecond)
+	select {
+	case got := <-clientOut:
+		t.Errorf("Warn-only mode must not synthesize errors, got %q", got)
+	default:
+	}
+	if logs.FilterMessageSnippet("never answered").Len() != 1 {
+		t.Error("Expected a warning about the unanswered request")
+	}
+}
+
+func TestOrphanTracking_ConfigValidation(t *testing.T) {
+	base := Config{
+		Endpoint: "http://localhost:8080",
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+	}
+
+	cfg := base
+	cfg.OrphanWarnAfter = time.Minute
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected OrphanWarnAfter without TrackOrphans rejected")
+	}
+
+	cfg = base
+	cfg.TrackOrphans = true
+	cfg.OrphanWarnAfter = time.Minute
+	cfg.RequestTimeout = time.Minute
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected OrphanWarnAfter alongside RequestTimeout rejected")
+	}
+
+	cfg = base
+	cfg.TrackOrphans = true
+	cfg.OrphanWarnAfter = 30 * time.Second
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid warn-only config, got %v", err)
+	}
+}
+
+func TestRequestTracker_StringIDs(t *testing.T) {
+	// String ids must be tracked and echoed verbatim, not coerced to ints.
+	clientIn, _, serverOut, clientOut := newTestTracker(t, 100*time.Millisecond)
+
+	clientIn <- `{"jsonrpc": "2.0", "id": "req-abc", "method": "tools/call"}`
+	<-serverOut
+
+	select {
+	case got := <-clientOut:
+		if !strings.Contains(got, `"id":"req-abc"`) {
+			t.Errorf("Timeout error should echo the string id, got %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("No timeout error was emitted for the string id")
+	}
+}
@@ -0,0 +1,224 @@
+package mcpengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Transform scopes for TransformRule.On.
+const (
+	// TransformRequest applies a rule to client requests of the matching
+	// method. This is the default.
+	TransformRequest = "request"
+	// TransformResponse applies a rule to the server's responses to
+	// requests of the matching method.
+	TransformResponse = "response"
+)
+
+// TransformRule is one declarative rewrite applied to messages of a
+// matching JSON-RPC method, so slightly-incompatible servers and clients
+// can be adapted from configuration instead of Go middleware. Paths are
+// dotted and relative to the message root, e.g. "params.arguments.model"
+// or "result.serverInfo.name".
+type TransformRule struct {
+	// Method is the JSON-RPC method the rule applies to; "*" matches every
+	// method.
+	Method string
+	// On selects request or response messages; empty means request.
+	// Response rules are matched by remembering each request's method
+	// until its response arrives, since responses carry no method.
+	On string
+	// Set writes a value at each path, creating intermediate objects.
+	Set map[string]interface{}
+	// Remove deletes the field at each path.
+	Remove []string
+	// Rename gives the field at each path a new name in the same object.
+	Rename map[string]string
+}
+
+// validate rejects rules that could never apply.
+func (tr *TransformRule) validate() error {
+	if tr.Method == "" {
+		return fmt.Errorf("transform rule requires a method (or \"*\")")
+	}
+	switch tr.On {
+	case "", TransformRequest, TransformResponse:
+	default:
+		return fmt.Errorf("transform rule on %q must apply to %q or %q, got %q",
+			tr.Method, TransformRequest, TransformResponse, tr.On)
+	}
+	if len(tr.Set) == 0 && len(tr.Remove) == 0 && len(tr.Rename) == 0 {
+		return fmt.Errorf("transform rule on %q has no set, remove, or rename operations", tr.Method)
+	}
+	for old, name := range tr.Rename {
+		if name == "" || strings.Contains(name, ".") {
+			return fmt.Errorf("transform rule on %q renames %q to invalid name %q", tr.Method, old, name)
+		}
+	}
+	return nil
+}
+
+// transformer applies the configured rules as middleware on both
+// directions. Request rules match on the outbound method; response rules
+// are applied when the response to a remembered request id arrives.
+type transformer struct {
+	requests  map[string][]TransformRule
+	responses map[string][]TransformRule
+	logger    Logger
+
+	// inflight maps outstanding request ids (raw bytes) to their method,
+	// only for methods with response rules. Guarded by mu since outbound
+	// and inbound run on different workers.
+	mu       sync.Mutex
+	inflight map[string]string
+}
+
+// newTransformer indexes the rules by method.
+func newTransformer(rules []TransformRule, logger Logger) *transformer {
+	t := &transformer{
+		requests:  make(map[string][]TransformRule),
+		responses: make(map[string][]TransformRule),
+		logger:    logger,
+		inflight:  make(map[string]string),
+	}
+	for _, rule := range rules {
+		if rule.On == TransformResponse {
+			t.responses[rule.Method] = append(t.responses[rule.Method], rule)
+		} else {
+			t.requests[rule.Method] = append(t.requests[rule.Method], rule)
+		}
+	}
+	return t
+}
+
+// rulesFor collects the rules matching a method, wildcard rules first.
+func rulesFor(indexed map[string][]TransformRule, method string) []TransformRule {
+	if len(indexed) == 0 {
+		return nil
+	}
+	rules := indexed["*"]
+	return append(rules, indexed[method]...)
+}
+
+// outbound rewrites matching client requests, and remembers ids of
+// requests whose responses have rules.
+func (t *transformer) outbound(msg string) (string, error) {
+	method := getMessageMethod(msg)
+	if method == "" {
+		return msg, nil
+	}
+	if len(rulesFor(t.responses, method)) > 0 {
+		if id := getMessageID(msg, t.logger); id != nil {
+			t.mu.Lock()
+			t.inflight[string(id)] = method
+			t.mu.Unlock()
+		}
+	}
+	return t.apply(msg, rulesFor(t.requests, method))
+}
+
+// inbound rewrites responses to remembered requests; everything else
+// passes through untouched.
+func (t *transformer) inbound(msg string) (string, error) {
+	if getMessageMethod(msg) != "" {
+		return msg, nil
+	}
+	id := getMessageID(msg, t.logger)
+	if id == nil {
+		return msg, nil
+	}
+	t.mu.Lock()
+	method, ok := t.inflight[string(id)]
+	if ok {
+		delete(t.inflight, string(id))
+	}
+	t.mu.Unlock()
+	if !ok {
+		return msg, nil
+	}
+	return t.apply(msg, rulesFor(t.responses, method))
+}
+
+// apply runs the rules over one message. With no matching rules the
+// message is returned byte-for-byte; a message the rules can't parse is
+// forwarded unchanged rather than dropped.
+func (t *transformer) apply(msg string, rules []TransformRule) (string, error) {
+	if len(rules) == 0 {
+		return msg, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(msg), &data); err != nil {
+		t.logger.Warnw("Skipping transforms on unparseable message", "err", err)
+		return msg, nil
+	}
+	for _, rule := range rules {
+		for path, value := range rule.Set {
+			setPath(data, path, value)
+		}
+		for _, path := range rule.Remove {
+			removePath(data, path)
+		}
+		for path, name := range rule.Rename {
+			renamePath(data, path, name)
+		}
+	}
+	rewritten, err := json.Marshal(data)
+	if err != nil {
+		t.logger.Errorw("Failed to serialize transformed message", "err", err)
+		return msg, nil
+	}
+	return string(rewritten), nil
+}
+
+// walkPath descends to the object holding the path's final segment,
+// creating intermediate objects when asked. It returns nil when an
+// intermediate segment is missing or not an object.
+func walkPath(data map[string]interface{}, path string, create bool) (map[string]interface{}, string) {
+	segments := strings.Split(path, ".")
+	current := data
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := current[segment]
+		if !ok {
+			if !create {
+				return nil, ""
+			}
+			child := make(map[string]interface{})
+			current[segment] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return nil, ""
+		}
+		current = child
+	}
+	return current, segments[len(segments)-1]
+}
+
+func setPath(data map[string]interface{}, path string, value interface{}) {
+	if parent, key := walkPath(data, path, true); parent != nil {
+		parent[key] = value
+	}
+}
+
+func removePath(data map[string]interface{}, path string) {
+	if parent, key := walkPath(data, path, false); parent != nil {
+		delete(parent, key)
+	}
+}
+
+func renamePath(data map[string]interface{}, path, name string) {
+	parent, key := walkPath(data, path, false)
+	if parent == nil {
+		return
+	}
+	value, ok := parent[key]
+	if !ok {
+		return
+	}
+	delete(parent, key)
+	parent[name] = value
+}
@@ -0,0 +1,151 @@
+package mcpengine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// ===== Transform Tests =====
+
+func parseTransformed(t *testing.T, msg string) map[string]interface{} {
+	t.Helper()
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(msg), &data); err != nil {
+		t.Fatalf("Failed to parse transformed message %q: %v", msg, err)
+	}
+	return data
+}
+
+func TestTransformer_SetRemoveRenameOnRequests(t *testing.T) {
+	transforms := newTransformer([]TransformRule{{
+		Method: "tools/call",
+		Set:    map[string]interface{}{"params.arguments.model": "gpt-4"},
+		Remove: []string{"params.arguments.internal"},
+		Rename: map[string]string{"params.name": "tool"},
+	}}, NewZapLogger(zap.NewNop().Sugar()))
+
+	msg, err := transforms.outbound(`{"jsonrpc": "2.0", "id": 1, "method": "tools/call",` +
+		` "params": {"name": "search", "arguments": {"internal": true}}}`)
+	if err != nil {
+		t.Fatalf("outbound returned error: %v", err)
+	}
+	data := parseTransformed(t, msg)
+	params := data["params"].(map[string]interface{})
+	args := params["arguments"].(map[string]interface{})
+	if args["model"] != "gpt-4" {
+		t.Errorf("Expected model set, got %v", args)
+	}
+	if _, ok := args["internal"]; ok {
+		t.Errorf("Expected internal removed, got %v", args)
+	}
+	if params["tool"] != "search" {
+		t.Errorf("Expected name renamed to tool, got %v", params)
+	}
+	if _, ok := params["name"]; ok {
+		t.Errorf("Expected original name gone, got %v", params)
+	}
+}
+
+func TestTransformer_LeavesOtherMethodsUntouched(t *testing.T) {
+	transforms := newTransformer([]TransformRule{{
+		Method: "tools/call",
+		Remove: []string{"params"},
+	}}, NewZapLogger(zap.NewNop().Sugar()))
+
+	original := `{"jsonrpc": "2.0", "id": 2, "method": "ping", "params": {"x": 1}}`
+	msg, err := transforms.outbound(original)
+	if err != nil {
+		t.Fatalf("outbound returned error: %v", err)
+	}
+	if msg != original {
+		t.Errorf("Expected non-matching message byte-for-byte, got %q", msg)
+	}
+}
+
+func TestTransformer_AppliesResponseRulesByRequestMethod(t *testing.T) {
+	transforms := newTransformer([]TransformRule{{
+		Method: "initialize",
+		On:     TransformResponse,
+		Set:    map[string]interface{}{"result.serverInfo.name": "renamed"},
+	}}, NewZapLogger(zap.NewNop().Sugar()))
+
+	if _, err := transforms.outbound(`{"jsonrpc": "2.0", "id": 1, "method": "initialize"}`); err != nil {
+		t.Fatalf("outbound returned error: %v", err)
+	}
+	msg, err := transforms.inbound(`{"jsonrpc": "2.0", "id": 1, "result": {"serverInfo": {"name": "orig"}}}`)
+	if err != nil {
+		t.Fatalf("inbound returned error: %v", err)
+	}
+	data := parseTransformed(t, msg)
+	info := data["result"].(map[string]interface{})["serverInfo"].(map[string]interface{})
+	if info["name"] != "renamed" {
+		t.Errorf("Expected serverInfo.name rewritten, got %v", info)
+	}
+
+	// A response to an untracked id passes through untouched.
+	other := `{"jsonrpc": "2.0", "id": 9, "result": {"serverInfo": {"name": "orig"}}}`
+	if msg, err := transforms.inbound(other); err != nil || msg != other {
+		t.Errorf("Expected unknown response untouched, got %q (%v)", msg, err)
+	}
+}
+
+func TestTransformer_WildcardMatchesEveryMethod(t *testing.T) {
+	transforms := newTransformer([]TransformRule{{
+		Method: "*",
+		Set:    map[string]interface{}{"params._meta.proxied": true},
+	}}, NewZapLogger(zap.NewNop().Sugar()))
+
+	msg, err := transforms.outbound(`{"jsonrpc": "2.0", "id": 3, "method": "resources/list"}`)
+	if err != nil {
+		t.Fatalf("outbound returned error: %v", err)
+	}
+	data := parseTransformed(t, msg)
+	meta := data["params"].(map[string]interface{})["_meta"].(map[string]interface{})
+	if meta["proxied"] != true {
+		t.Errorf("Expected _meta.proxied set via created objects, got %v", data)
+	}
+}
+
+func TestTransforms_ConfigValidation(t *testing.T) {
+	base := Config{
+		Endpoint: "http://localhost:8080",
+		MCPPath:  "/mcp",
+		Logger:   NewZapLogger(zap.NewNop().Sugar()),
+	}
+
+	cfg := base
+	cfg.Transforms = []TransformRule{{Method: "tools/call"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a rule without operations rejected")
+	}
+
+	cfg = base
+	cfg.Transforms = []TransformRule{{Set: map[string]interface{}{"params.x": 1}}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a rule without a method rejected")
+	}
+
+	cfg = base
+	cfg.Transforms = []TransformRule{{Method: "a", On: "both", Remove: []string{"params"}}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an unknown scope rejected")
+	}
+
+	cfg = base
+	cfg.Transforms = []TransformRule{{Method: "a", Rename: map[string]string{"params.x": "y.z"}}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected a dotted rename target rejected")
+	}
+
+	cfg = base
+	cfg.Transforms = []TransformRule{{
+		Method: "tools/call",
+		On:     TransformResponse,
+		Remove: []string{"result.debug"},
+	}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a valid rule accepted, got %v", err)
+	}
+}